@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
 	"citynext/internal/api/routes"
 	"citynext/internal/config"
 	"citynext/internal/database"
 	"citynext/internal/logger"
 	"citynext/internal/services"
+	"citynext/pkg/client"
 )
 
+// how long in-flight requests get to finish once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 
 	cfg := config.Load()
@@ -22,7 +33,116 @@ func main() {
 		"port", cfg.ServerPort,
 		"db_path", cfg.DBPath,
 		"log_level", cfg.LogLevel.String(),
-		"nager_api_url", cfg.NagerAPIBaseURL)
+		"holiday_providers", cfg.HolidayProviders,
+		"opening_time", cfg.OpeningTime,
+		"closing_time", cfg.ClosingTime,
+		"slot_minutes", cfg.SlotMinutes,
+		"weekly_schedule", cfg.WeeklySchedule,
+		"min_notice", cfg.MinNotice.String(),
+		"min_lead_working_days", cfg.MinLeadWorkingDays,
+		"max_days_ahead", cfg.MaxDaysAhead,
+		"same_day_cutoff", cfg.SameDayCutoff,
+		"daily_capacity", cfg.DailyCapacity,
+		"max_appointments_per_person", cfg.PersonLimit,
+		"half_day_closing_time", cfg.HalfDayClosingTime,
+		"admin_token_set", cfg.AdminToken != "",
+		"office_region", cfg.OfficeRegion,
+		"office_time_zone", cfg.OfficeTimeZone,
+		"holiday_fail_policy", cfg.HolidayFailPolicy,
+		"holiday_cache_ttl", cfg.HolidayCacheTTL.String(),
+		"holiday_revalidate_interval", cfg.HolidayRevalidateInterval.String(),
+		"holiday_refresh_interval", cfg.HolidayRefreshInterval.String(),
+		"holiday_retry_attempts", cfg.HolidayRetryAttempts,
+		"holiday_breaker_threshold", cfg.HolidayBreakerThreshold,
+		"holiday_breaker_cooldown", cfg.HolidayBreakerCooldown.String())
+
+	openingHours, err := parseOpeningHours(cfg)
+	if err != nil {
+		log.Error("Invalid opening hours configuration", "error", err)
+		os.Exit(1)
+	}
+
+	weeklySchedule := services.WeekdaySchedule(openingHours)
+	if cfg.WeeklySchedule != "" {
+		weeklySchedule, err = services.ParseWeeklySchedule(cfg.WeeklySchedule, openingHours.SlotLength)
+		if err != nil {
+			log.Error("Invalid weekly schedule configuration", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	bookingWindow, err := parseBookingWindow(cfg)
+	if err != nil {
+		log.Error("Invalid booking window configuration", "error", err)
+		os.Exit(1)
+	}
+
+	capacityOverrides, err := services.ParseCapacityOverrides(cfg.CapacityOverrides)
+	if err != nil {
+		log.Error("Invalid capacity override configuration", "error", err)
+		os.Exit(1)
+	}
+	dailyCapacity := services.DailyCapacity{Default: cfg.DailyCapacity, Overrides: capacityOverrides}
+	if err := dailyCapacity.Validate(); err != nil {
+		log.Error("Invalid daily capacity configuration", "error", err)
+		os.Exit(1)
+	}
+
+	halfDayClosingTime, err := parseHalfDayClosingTime(cfg)
+	if err != nil {
+		log.Error("Invalid half-day closing time configuration", "error", err)
+		os.Exit(1)
+	}
+
+	if cfg.PersonLimit < 0 {
+		log.Error("Invalid per-person limit configuration", "error", "MAX_APPOINTMENTS_PER_PERSON must not be negative")
+		os.Exit(1)
+	}
+
+	officeRegion, err := services.ParseOfficeRegion(cfg.OfficeRegion)
+	if err != nil {
+		log.Error("Invalid office region configuration", "error", err)
+		os.Exit(1)
+	}
+
+	officeLocation, err := services.ParseOfficeTimeZone(cfg.OfficeTimeZone)
+	if err != nil {
+		log.Error("Invalid office time zone configuration", "error", err)
+		os.Exit(1)
+	}
+
+	holidayFailPolicy, err := services.ParseHolidayFailPolicy(cfg.HolidayFailPolicy)
+	if err != nil {
+		log.Error("Invalid holiday fail policy configuration", "error", err)
+		os.Exit(1)
+	}
+
+	retryPolicy := client.RetryPolicy{
+		MaxAttempts: cfg.HolidayRetryAttempts,
+		BaseDelay:   cfg.HolidayRetryBaseDelay,
+		MaxDelay:    cfg.HolidayRetryMaxDelay,
+	}
+	if err := retryPolicy.Validate(); err != nil {
+		log.Error("Invalid holiday retry configuration", "error", err)
+		os.Exit(1)
+	}
+
+	breakerConfig := client.BreakerConfig{
+		FailureThreshold: cfg.HolidayBreakerThreshold,
+		Cooldown:         cfg.HolidayBreakerCooldown,
+	}
+	if err := breakerConfig.Validate(); err != nil {
+		log.Error("Invalid holiday circuit breaker configuration", "error", err)
+		os.Exit(1)
+	}
+
+	holidayProvider, err := client.NewProviderFromSpec(cfg.HolidayProviders, log.Logger,
+		client.WithRetryPolicy(retryPolicy),
+		client.WithBreakerConfig(breakerConfig))
+	if err != nil {
+		log.Error("Invalid holiday provider configuration", "error", err)
+		os.Exit(1)
+	}
 
 	db, err := database.NewSQLiteConnection(cfg.DBPath)
 	if err != nil {
@@ -35,15 +155,142 @@ func main() {
 		}
 	}()
 
+	// appointments booked before time slots existed get the first free slot of their day
+	err = database.MigrateLegacyStartTimes(db, func(date apiModels.Date) []apiModels.TimeOfDay {
+		if hours, open := weeklySchedule.HoursOn(date.Weekday()); open {
+			return hours.Slots()
+		}
+		return openingHours.Slots()
+	})
+	if err != nil {
+		log.Error("Failed to migrate appointments booked before time slots", "error", err)
+		os.Exit(1)
+	}
+
 	appointmentRepo := database.NewSQLiteAppointmentRepository(db, log.Logger)
+	holidayRepo := database.NewSQLiteHolidayRepository(db, log.Logger)
+	closureRepo := database.NewSQLiteClosureRepository(db, log.Logger)
+	extraOpeningRepo := database.NewSQLiteExtraOpeningRepository(db, log.Logger)
 
-	holidayService := services.NewHolidayService(cfg.NagerAPIBaseURL, log.Logger)
-	appointmentService := services.NewAppointmentService(appointmentRepo, holidayService, log.Logger)
+	holidayService := services.NewHolidayService(holidayProvider, log.Logger,
+		services.WithOpeningHours(openingHours),
+		services.WithHalfDayClosingTime(halfDayClosingTime),
+		services.WithWeeklySchedule(weeklySchedule),
+		services.WithBookingWindow(bookingWindow),
+		services.WithHolidayStore(holidayRepo),
+		services.WithOfficeRegion(officeRegion),
+		services.WithOfficeLocation(officeLocation),
+		services.WithHolidayCacheTTL(cfg.HolidayCacheTTL),
+		services.WithHolidayFailPolicy(holidayFailPolicy),
+		services.WithClosures(closureRepo),
+		services.WithExtraOpenings(extraOpeningRepo))
+	appointmentService := services.NewAppointmentService(appointmentRepo, holidayService, log.Logger,
+		services.WithDailyCapacity(dailyCapacity),
+		services.WithPersonLimit(cfg.PersonLimit),
+		services.WithOfficeClock(time.Now, officeLocation))
+
+	// cancelled on SIGINT or SIGTERM, which stops the background jobs and the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// re-checks bookings accepted while holiday data was unavailable
+	revalidator := services.NewHolidayRevalidator(appointmentRepo, holidayService, cfg.HolidayRevalidateInterval, log.Logger)
+	go revalidator.Run(ctx)
+
+	// prefetches next year's holidays and picks up holidays published after they were cached
+	if yearRefresher, ok := holidayService.(services.YearRefresher); ok {
+		refresher := services.NewHolidayRefresher(appointmentRepo, yearRefresher, cfg.HolidayRefreshInterval, log.Logger)
+		go refresher.Run(ctx)
+	}
+
+	closureService := services.NewClosureService(closureRepo, appointmentRepo, openingHours, halfDayClosingTime, log.Logger)
+	extraOpeningService := services.NewExtraOpeningService(extraOpeningRepo, openingHours, log.Logger)
 
 	appointmentHandler := handlers.NewAppointmentHandler(appointmentService, log.Logger)
+	closureHandler := handlers.NewClosureHandler(closureService, log.Logger)
+	extraOpeningHandler := handlers.NewExtraOpeningHandler(extraOpeningService, log.Logger)
+
+	if cfg.AdminToken == "" {
+		log.Warn("ADMIN_TOKEN is not set, staff requests for appointments, closures and extra openings are rejected")
+	}
+	adminAuth := handlers.NewAdminAuth(cfg.AdminToken, log.Logger)
 
 	router := http.NewServeMux()
-	routes.RegisterRoutes(router, appointmentHandler)
+	api := routes.RegisterRoutes(router, appointmentHandler, adminAuth)
+	routes.RegisterClosureRoutes(api, closureHandler, adminAuth)
+	routes.RegisterExtraOpeningRoutes(api, extraOpeningHandler, adminAuth)
+	if calendarSource, ok := holidayService.(services.CalendarSource); ok {
+		routes.RegisterCalendarRoutes(api, handlers.NewCalendarHandler(calendarSource, log.Logger))
+	}
+	if providerStater, ok := holidayService.(services.ProviderStater); ok {
+		routes.RegisterHealthRoutes(api, handlers.NewHealthHandler(providerStater, log.Logger))
+	}
 
-	http.ListenAndServe(":"+cfg.ServerPort, router)
+	server := &http.Server{Addr: ":" + cfg.ServerPort, Handler: router}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		log.Info("Shutting down")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("Failed to shut down gracefully", "error", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Server failed", "error", err)
+		return
+	}
+	<-stopped
+}
+
+// builds the office opening hours from configuration
+func parseOpeningHours(cfg *config.Config) (services.OpeningHours, error) {
+	opens, err := apiModels.ParseTimeOfDay(cfg.OpeningTime)
+	if err != nil {
+		return services.OpeningHours{}, fmt.Errorf("OPENING_TIME: %w", err)
+	}
+	closes, err := apiModels.ParseTimeOfDay(cfg.ClosingTime)
+	if err != nil {
+		return services.OpeningHours{}, fmt.Errorf("CLOSING_TIME: %w", err)
+	}
+
+	hours := services.OpeningHours{
+		Opens:      opens,
+		Closes:     closes,
+		SlotLength: time.Duration(cfg.SlotMinutes) * time.Minute,
+	}
+	return hours, hours.Validate()
+}
+
+// parses the time from which the office is closed on a half-day closure
+func parseHalfDayClosingTime(cfg *config.Config) (apiModels.TimeOfDay, error) {
+	closes, err := apiModels.ParseTimeOfDay(cfg.HalfDayClosingTime)
+	if err != nil {
+		return apiModels.TimeOfDay{}, fmt.Errorf("HALF_DAY_CLOSING_TIME: %w", err)
+	}
+	if closes.Minutes() == 0 {
+		return apiModels.TimeOfDay{}, fmt.Errorf("HALF_DAY_CLOSING_TIME: must be after 00:00")
+	}
+	return closes, nil
+}
+
+// builds the limits on how soon and how far ahead appointments can be booked from configuration
+func parseBookingWindow(cfg *config.Config) (services.BookingWindow, error) {
+	window := services.BookingWindow{
+		MinNotice:      cfg.MinNotice,
+		MinWorkingDays: cfg.MinLeadWorkingDays,
+		MaxDaysAhead:   cfg.MaxDaysAhead,
+	}
+	if cfg.SameDayCutoff != "" {
+		cutoff, err := apiModels.ParseTimeOfDay(cfg.SameDayCutoff)
+		if err != nil {
+			return services.BookingWindow{}, fmt.Errorf("SAME_DAY_CUTOFF: %w", err)
+		}
+		window.SameDayCutoff = &cutoff
+	}
+	return window, window.Validate()
 }
@@ -2,14 +2,14 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log/slog"
+	"net/http"
 
 	"citynext/internal/api/models"
 	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
 	"citynext/internal/services"
-
-	"github.com/danielgtaylor/huma/v2"
 )
 
 type AppointmentHandler struct {
@@ -28,12 +28,14 @@ func (h *AppointmentHandler) CreateAppointment(ctx context.Context, input *model
 	h.logger.Info("Received appointment creation request",
 		"first_name", input.Body.FirstName,
 		"last_name", input.Body.LastName,
-		"visit_date", input.Body.VisitDate.String())
+		"visit_date", input.Body.VisitDate.String(),
+		"start_time", input.Body.StartTime.String())
 
 	req := &services.CreateAppointmentRequest{
 		FirstName: input.Body.FirstName,
 		LastName:  input.Body.LastName,
 		VisitDate: input.Body.VisitDate,
+		StartTime: input.Body.StartTime,
 	}
 
 	appointment, err := h.appointmentService.CreateAppointment(ctx, req)
@@ -42,37 +44,260 @@ func (h *AppointmentHandler) CreateAppointment(ctx context.Context, input *model
 			"error", err,
 			"first_name", input.Body.FirstName,
 			"last_name", input.Body.LastName,
-			"visit_date", input.Body.VisitDate.String())
+			"visit_date", input.Body.VisitDate.String(),
+			"start_time", input.Body.StartTime.String())
 
 		// map domain errors to HTTP errors
-		switch err {
-		case services.ErrDateInPast:
-			return nil, huma.Error422UnprocessableEntity("Visit date cannot be in the past")
-		case services.ErrDateIsHoliday:
-			return nil, huma.Error422UnprocessableEntity("Visit date is a public holiday")
-		case services.ErrDateIsWeekend:
-			return nil, huma.Error422UnprocessableEntity("Visit date is a weekend")
-		case database.ErrDuplicateAppointment:
-			return nil, huma.Error422UnprocessableEntity("An appointment already exists for this date")
-		case services.ErrInvalidInput:
-			return nil, huma.Error422UnprocessableEntity("Invalid input data")
-		default:
-			return nil, huma.Error500InternalServerError(fmt.Sprintf("Internal server error: %v", err))
-		}
+		return nil, h.bookingError(ctx, err, input.Body.VisitDate)
 	}
 
 	output := &models.CreateAppointmentOutput{}
-	output.Body.ID = appointment.ID
-	output.Body.FirstName = appointment.FirstName
-	output.Body.LastName = appointment.LastName
-	output.Body.VisitDate = appointment.VisitDate
-	output.Body.CreatedAt = appointment.CreatedAt.Format("2006-01-02T15:04:05Z")
+	output.Body.AppointmentBody = toAppointmentBody(appointment)
+	output.Body.ManagementToken = appointment.ManagementToken
 
 	h.logger.Info("Appointment created successfully via API",
 		"id", appointment.ID,
+		"reference", appointment.Reference,
 		"first_name", appointment.FirstName,
 		"last_name", appointment.LastName,
-		"visit_date", appointment.VisitDate.String())
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String())
+
+	return output, nil
+}
+
+func (h *AppointmentHandler) CheckAppointment(ctx context.Context, input *models.CheckAppointmentInput) (*models.CheckAppointmentOutput, error) {
+	h.logger.Info("Received appointment check request",
+		"visit_date", input.Body.VisitDate.String(),
+		"start_time", input.Body.StartTime.String())
+
+	req := &services.CreateAppointmentRequest{
+		FirstName: input.Body.FirstName,
+		LastName:  input.Body.LastName,
+		VisitDate: input.Body.VisitDate,
+		StartTime: input.Body.StartTime,
+	}
+
+	result, err := h.appointmentService.CheckAppointment(ctx, req)
+	if err != nil {
+		h.logger.Error("Failed to check appointment",
+			"error", err,
+			"visit_date", input.Body.VisitDate.String(),
+			"start_time", input.Body.StartTime.String())
+
+		switch {
+		case errors.Is(err, services.ErrHolidayDataUnavailable):
+			return nil, holidayDataUnavailable(err)
+		default:
+			return nil, internalError()
+		}
+	}
+
+	output := &models.CheckAppointmentOutput{}
+	output.Body.Bookable = len(result.Violations) == 0
+	output.Body.HolidayUnverified = result.HolidayUnverified
+	output.Body.Violations = make([]models.ViolationBody, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		output.Body.Violations = append(output.Body.Violations, models.ViolationBody{
+			Rule:     violation.Rule,
+			Code:     violation.Err.Code,
+			Message:  violation.Err.Error(),
+			Location: violation.Err.Location,
+		})
+	}
+
+	return output, nil
+}
+
+func (h *AppointmentHandler) GetAppointment(ctx context.Context, input *models.GetAppointmentInput) (*models.GetAppointmentOutput, error) {
+	h.logger.Info("Received appointment lookup request", "id", input.ID)
+
+	appointment, err := h.appointmentService.GetAppointment(ctx, input.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrAppointmentNotFound):
+			return nil, notFound("Appointment not found", err)
+		default:
+			h.logger.Error("Failed to get appointment", "error", err, "id", input.ID)
+			return nil, internalError()
+		}
+	}
+
+	return &models.GetAppointmentOutput{Body: toAppointmentBody(appointment)}, nil
+}
+
+func (h *AppointmentHandler) ListAppointments(ctx context.Context, input *models.ListAppointmentsInput) (*models.ListAppointmentsOutput, error) {
+	h.logger.Info("Received appointment list request",
+		"from", input.From.String(),
+		"to", input.To.String(),
+		"last_name", input.LastName,
+		"page", input.Page,
+		"page_size", input.PageSize)
+
+	req := &services.ListAppointmentsRequest{
+		From:             input.From,
+		To:               input.To,
+		LastName:         input.LastName,
+		IncludeCancelled: input.IncludeCancelled,
+		Page:             input.Page,
+		PageSize:         input.PageSize,
+	}
+
+	appointments, total, err := h.appointmentService.ListAppointments(ctx, req)
+	if err != nil {
+		h.logger.Error("Failed to list appointments", "error", err)
+
+		switch {
+		case errors.Is(err, services.ErrInvalidDateRange):
+			return nil, unprocessable("The 'from' date must not be after the 'to' date", err)
+		case errors.Is(err, services.ErrInvalidInput):
+			return nil, unprocessable("Invalid input data", err)
+		default:
+			return nil, internalError()
+		}
+	}
+
+	output := &models.ListAppointmentsOutput{}
+	output.Body.Items = make([]models.AppointmentBody, 0, len(appointments))
+	for i := range appointments {
+		output.Body.Items = append(output.Body.Items, toAppointmentBody(&appointments[i]))
+	}
+	output.Body.Total = total
+	output.Body.Page = input.Page
+	output.Body.PageSize = input.PageSize
 
 	return output, nil
 }
+
+func (h *AppointmentHandler) CancelAppointment(ctx context.Context, input *models.CancelAppointmentInput) (*models.CancelAppointmentOutput, error) {
+	h.logger.Info("Received appointment cancellation request",
+		"id", input.ID,
+		"cancelled_by", input.Body.CancelledBy)
+
+	req := &services.CancelAppointmentRequest{
+		ID:          input.ID,
+		CancelledBy: input.Body.CancelledBy,
+		Reason:      input.Body.Reason,
+	}
+
+	appointment, err := h.appointmentService.CancelAppointment(ctx, req)
+	if err != nil {
+		h.logger.Error("Failed to cancel appointment", "error", err, "id", input.ID)
+
+		switch {
+		case errors.Is(err, database.ErrAppointmentNotFound):
+			return nil, notFound("Appointment not found", err)
+		case errors.Is(err, database.ErrAlreadyCancelled):
+			return nil, conflict("Appointment has already been cancelled", err)
+		case errors.Is(err, services.ErrInvalidInput):
+			return nil, unprocessable("Invalid input data", err)
+		default:
+			return nil, internalError()
+		}
+	}
+
+	h.logger.Info("Appointment cancelled successfully via API",
+		"id", appointment.ID,
+		"visit_date", appointment.VisitDate.String())
+
+	return &models.CancelAppointmentOutput{Body: toAppointmentBody(appointment)}, nil
+}
+
+func (h *AppointmentHandler) RescheduleAppointment(ctx context.Context, input *models.RescheduleAppointmentInput) (*models.RescheduleAppointmentOutput, error) {
+	h.logger.Info("Received appointment reschedule request",
+		"id", input.ID,
+		"new_visit_date", input.Body.VisitDate.String(),
+		"new_start_time", input.Body.StartTime.String())
+
+	req := &services.RescheduleAppointmentRequest{
+		ID:        input.ID,
+		VisitDate: input.Body.VisitDate,
+		StartTime: input.Body.StartTime,
+	}
+
+	appointment, err := h.appointmentService.RescheduleAppointment(ctx, req)
+	if err != nil {
+		h.logger.Error("Failed to reschedule appointment",
+			"error", err,
+			"id", input.ID,
+			"new_visit_date", input.Body.VisitDate.String(),
+			"new_start_time", input.Body.StartTime.String())
+
+		// map domain errors to HTTP errors
+		return nil, h.bookingError(ctx, err, input.Body.VisitDate)
+	}
+
+	h.logger.Info("Appointment rescheduled successfully via API",
+		"id", appointment.ID,
+		"visit_date", appointment.VisitDate.String())
+
+	return &models.RescheduleAppointmentOutput{Body: toAppointmentBody(appointment)}, nil
+}
+
+// maps domain errors from booking or moving an appointment to HTTP errors, shared by the staff and self-service routes
+func (h *AppointmentHandler) bookingError(ctx context.Context, err error, date models.Date) error {
+	switch {
+	case errors.Is(err, database.ErrAppointmentNotFound):
+		return notFound("Appointment not found", err)
+	case errors.Is(err, database.ErrAlreadyCancelled):
+		return conflict("Appointment has been cancelled", err)
+	case errors.Is(err, services.ErrAppointmentInPast):
+		return conflict("Appointment date has already passed", err)
+	case errors.Is(err, services.ErrDateInPast):
+		return unprocessable("Visit date cannot be in the past", err)
+	case errors.Is(err, services.ErrDateTooSoon):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "Visit date is too soon to book", err, date)
+	case errors.Is(err, services.ErrSameDayCutoff):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "Bookings for today have closed", err, date)
+	case errors.Is(err, services.ErrNoticeTooShort):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "The appointment starts too soon to book", err, date)
+	case errors.Is(err, services.ErrDateTooFar):
+		return unprocessable("Visit date is too far ahead to book", err)
+	case errors.Is(err, services.ErrDateIsHoliday):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "Visit date is a public holiday", err, date)
+	case errors.Is(err, services.ErrClosedWeekday):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "The office is not open on this day of the week", err, date)
+	case errors.Is(err, services.ErrOfficeClosed):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "The office is closed at this time", err, date)
+	case errors.Is(err, services.ErrOutsideOpeningHours):
+		return unprocessable("Start time is outside opening hours or not on a slot boundary", err)
+	case errors.Is(err, database.ErrDuplicateAppointment):
+		return h.rejectWithSuggestions(ctx, http.StatusConflict, "An appointment already exists for this slot", err, date)
+	case errors.Is(err, database.ErrDateFullyBooked):
+		return h.rejectWithSuggestions(ctx, http.StatusUnprocessableEntity, "No appointments left on this date", err, date)
+	case errors.Is(err, database.ErrPersonLimitReached):
+		return unprocessable("This person already holds the maximum number of upcoming appointments", err)
+	case errors.Is(err, services.ErrInvalidInput):
+		return unprocessable("Invalid input data", err)
+	case errors.Is(err, services.ErrHolidayDataUnavailable):
+		return holidayDataUnavailable(err)
+	default:
+		return internalError()
+	}
+}
+
+// maps a database appointment to its API representation
+func toAppointmentBody(appointment *dbModels.Appointment) models.AppointmentBody {
+	body := models.AppointmentBody{
+		ID:        appointment.ID,
+		Reference: appointment.Reference,
+		FirstName: appointment.FirstName,
+		LastName:  appointment.LastName,
+		VisitDate: appointment.VisitDate,
+		StartTime: appointment.StartTime,
+		Status:    "active",
+		CreatedAt: appointment.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+		HolidayUnverified: appointment.HolidayUnverified,
+		HolidayConflict:   appointment.HolidayConflict,
+	}
+
+	if appointment.IsCancelled() {
+		body.Status = "cancelled"
+		body.CancelledAt = appointment.CancelledAt.UTC().Format("2006-01-02T15:04:05Z")
+		body.CancelledBy = appointment.CancelledBy
+		body.CancellationReason = appointment.CancellationReason
+	}
+
+	return body
+}
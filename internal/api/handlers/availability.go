@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"citynext/internal/api/models"
+	"citynext/internal/services"
+)
+
+func (h *AppointmentHandler) GetAvailability(ctx context.Context, input *models.GetAvailabilityInput) (*models.GetAvailabilityOutput, error) {
+	h.logger.Info("Received availability request",
+		"from", input.From.String(),
+		"to", input.To.String())
+
+	days, err := h.appointmentService.GetAvailability(ctx, input.From, input.To)
+	if err != nil {
+		h.logger.Error("Failed to get availability",
+			"error", err,
+			"from", input.From.String(),
+			"to", input.To.String())
+
+		switch {
+		case errors.Is(err, services.ErrInvalidDateRange):
+			return nil, unprocessable("The 'from' date must not be after the 'to' date", err)
+		case errors.Is(err, services.ErrDateRangeTooLong):
+			return nil, unprocessable(fmt.Sprintf("The date range must not exceed %d days", services.MaxAvailabilityDays), err)
+		case errors.Is(err, services.ErrHolidayDataUnavailable):
+			return nil, holidayDataUnavailable(err)
+		default:
+			return nil, internalError()
+		}
+	}
+
+	output := &models.GetAvailabilityOutput{}
+	output.Body.From = input.From
+	output.Body.To = input.To
+	output.Body.Days = make([]models.DayAvailabilityBody, 0, len(days))
+	for _, day := range days {
+		var reasons []string
+		for _, reason := range day.Reasons {
+			reasons = append(reasons, string(reason))
+		}
+		output.Body.Days = append(output.Body.Days, models.DayAvailabilityBody{
+			Date:         day.Date,
+			Bookable:     day.Bookable,
+			Reason:       string(day.Reason),
+			Reasons:      reasons,
+			HolidayName:  day.HolidayName,
+			Closure:      day.Closure,
+			HalfDay:      day.HalfDay,
+			ExtraOpening: day.ExtraOpening,
+			Remaining:    day.Remaining,
+
+			HolidayUnverified: day.HolidayUnverified,
+		})
+	}
+
+	return output, nil
+}
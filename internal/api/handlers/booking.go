@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"citynext/internal/api/models"
+	"citynext/internal/database"
+)
+
+func (h *AppointmentHandler) GetBooking(ctx context.Context, input *models.GetBookingInput) (*models.GetBookingOutput, error) {
+	h.logger.Info("Received booking lookup request", "reference", input.Reference)
+
+	appointment, err := h.appointmentService.GetAppointmentByReference(ctx, input.Reference, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrAppointmentNotFound):
+			return nil, notFound("Booking not found", err)
+		default:
+			h.logger.Error("Failed to get booking", "error", err, "reference", input.Reference)
+			return nil, internalError()
+		}
+	}
+
+	return &models.GetBookingOutput{Body: toAppointmentBody(appointment)}, nil
+}
+
+func (h *AppointmentHandler) CancelBooking(ctx context.Context, input *models.CancelBookingInput) (*models.CancelBookingOutput, error) {
+	h.logger.Info("Received booking cancellation request", "reference", input.Reference)
+
+	var reason string
+	if input.Body != nil {
+		reason = input.Body.Reason
+	}
+
+	appointment, err := h.appointmentService.CancelAppointmentByReference(ctx, input.Reference, input.Token, reason)
+	if err != nil {
+		h.logger.Error("Failed to cancel booking", "error", err, "reference", input.Reference)
+
+		switch {
+		case errors.Is(err, database.ErrAppointmentNotFound):
+			return nil, notFound("Booking not found", err)
+		case errors.Is(err, database.ErrAlreadyCancelled):
+			return nil, conflict("Appointment has already been cancelled", err)
+		default:
+			return nil, internalError()
+		}
+	}
+
+	h.logger.Info("Booking cancelled successfully via API",
+		"id", appointment.ID,
+		"reference", appointment.Reference)
+
+	return &models.CancelBookingOutput{Body: toAppointmentBody(appointment)}, nil
+}
+
+func (h *AppointmentHandler) RescheduleBooking(ctx context.Context, input *models.RescheduleBookingInput) (*models.RescheduleBookingOutput, error) {
+	h.logger.Info("Received booking reschedule request",
+		"reference", input.Reference,
+		"new_visit_date", input.Body.VisitDate.String(),
+		"new_start_time", input.Body.StartTime.String())
+
+	appointment, err := h.appointmentService.RescheduleAppointmentByReference(ctx, input.Reference, input.Token, input.Body.VisitDate, input.Body.StartTime)
+	if err != nil {
+		h.logger.Error("Failed to reschedule booking",
+			"error", err,
+			"reference", input.Reference,
+			"new_visit_date", input.Body.VisitDate.String(),
+			"new_start_time", input.Body.StartTime.String())
+
+		if errors.Is(err, database.ErrAppointmentNotFound) {
+			return nil, notFound("Booking not found", err)
+		}
+		return nil, h.bookingError(ctx, err, input.Body.VisitDate)
+	}
+
+	h.logger.Info("Booking rescheduled successfully via API",
+		"id", appointment.ID,
+		"reference", appointment.Reference,
+		"visit_date", appointment.VisitDate.String())
+
+	return &models.RescheduleBookingOutput{Body: toAppointmentBody(appointment)}, nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"citynext/internal/api/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+)
+
+// how long browsers and CDNs may reuse a response before revalidating it with its ETag;
+// staff can add closures at any time, so calendars are kept for less time than holidays
+const (
+	holidaysCacheControl = "public, max-age=3600"
+	calendarCacheControl = "public, max-age=300"
+)
+
+type CalendarHandler struct {
+	calendar services.CalendarSource
+	logger   *slog.Logger
+}
+
+func NewCalendarHandler(calendar services.CalendarSource, logger *slog.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		calendar: calendar,
+		logger:   logger,
+	}
+}
+
+func (h *CalendarHandler) GetHolidays(ctx context.Context, input *models.GetHolidaysInput) (*models.GetHolidaysOutput, error) {
+	h.logger.Info("Received holiday list request", "year", input.Year)
+
+	holidays, err := h.calendar.HolidaysInYear(ctx, input.Year)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+
+	output := &models.GetHolidaysOutput{Status: http.StatusOK, CacheControl: holidaysCacheControl}
+	output.Body.Year = input.Year
+	output.Body.Holidays = make([]models.HolidayBody, 0, len(holidays))
+	for _, holiday := range holidays {
+		output.Body.Holidays = append(output.Body.Holidays, toHolidayBody(holiday))
+	}
+
+	output.ETag, err = etagOf(output.Body)
+	if err != nil {
+		h.logger.Error("Failed to compute holiday list ETag", "error", err)
+		return nil, internalError()
+	}
+	if matchesETag(input.IfNoneMatch, output.ETag) {
+		output.Status = http.StatusNotModified
+	}
+
+	return output, nil
+}
+
+func (h *CalendarHandler) GetCalendar(ctx context.Context, input *models.GetCalendarInput) (*models.GetCalendarOutput, error) {
+	h.logger.Info("Received calendar request",
+		"from", input.From.String(),
+		"to", input.To.String())
+
+	entries, err := h.calendar.Calendar(ctx, input.From, input.To)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+
+	output := &models.GetCalendarOutput{Status: http.StatusOK, CacheControl: calendarCacheControl}
+	output.Body.From = input.From
+	output.Body.To = input.To
+	output.Body.Entries = make([]models.CalendarEntryBody, 0, len(entries))
+	for _, entry := range entries {
+		body := models.CalendarEntryBody{
+			Date:    entry.Date,
+			Reason:  string(entry.Reason),
+			Name:    entry.Name,
+			HalfDay: entry.HalfDay,
+		}
+		if entry.Holiday != nil {
+			holiday := toHolidayBody(*entry.Holiday)
+			body.Holiday = &holiday
+		}
+		output.Body.Entries = append(output.Body.Entries, body)
+	}
+
+	output.ETag, err = etagOf(output.Body)
+	if err != nil {
+		h.logger.Error("Failed to compute calendar ETag", "error", err)
+		return nil, internalError()
+	}
+	if matchesETag(input.IfNoneMatch, output.ETag) {
+		output.Status = http.StatusNotModified
+	}
+
+	return output, nil
+}
+
+func (h *CalendarHandler) GetWorkingDays(ctx context.Context, input *models.GetWorkingDaysInput) (*models.GetWorkingDaysOutput, error) {
+	h.logger.Info("Received working days request",
+		"from", input.From.String(),
+		"days", input.Days,
+		"to", input.To.String())
+
+	result, err := h.calendar.WorkingDays(ctx, input.From, input.Days, input.To)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+
+	output := &models.GetWorkingDaysOutput{}
+	output.Body.From = result.From
+	output.Body.IsWorkingDay = result.IsWorkingDay
+	output.Body.NextWorkingDay = result.NextWorkingDay
+	output.Body.Days = result.Days
+	output.Body.Date = result.Date
+	if !result.To.IsZero() {
+		output.Body.To = &result.To
+		output.Body.WorkingDaysBetween = &result.Between
+	}
+
+	return output, nil
+}
+
+// maps calendar service errors to API errors
+func calendarError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidDateRange):
+		return unprocessable("The 'from' date must not be after the 'to' date", err)
+	case errors.Is(err, services.ErrDateRangeTooLong):
+		return unprocessable(fmt.Sprintf("The date range must not exceed %d days", services.MaxCalendarDays), err)
+	case errors.Is(err, services.ErrDateOutOfRange):
+		return unprocessable(fmt.Sprintf("The 'from' date must be within %d days of today", services.MaxCalendarDays), err)
+	case errors.Is(err, services.ErrYearOutOfRange):
+		return unprocessable("Only last year through next year, or through the last bookable year, can be listed", err)
+	case errors.Is(err, services.ErrNoWorkingDay):
+		return unprocessable("There is no working day within a year of the date", err)
+	case errors.Is(err, services.ErrHolidayDataUnavailable):
+		return holidayDataUnavailable(err)
+	default:
+		return internalError()
+	}
+}
+
+func toHolidayBody(holiday client.Holiday) models.HolidayBody {
+	// holiday dates are YYYY-MM-DD, as the service matches them against Date.String()
+	var date models.Date
+	date.UnmarshalText([]byte(holiday.Date))
+
+	return models.HolidayBody{
+		Date:      date,
+		Name:      holiday.Name,
+		LocalName: holiday.LocalName,
+		Global:    holiday.Global,
+		Counties:  holiday.Counties,
+		Types:     holiday.Types,
+	}
+}
+
+// strong ETag derived from a response body, so an unchanged response keeps its ETag
+func etagOf(body any) (string, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// reports whether an If-None-Match header lists the given ETag, or is *
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+)
+
+type ClosureHandler struct {
+	closureService *services.ClosureService
+	logger         *slog.Logger
+}
+
+func NewClosureHandler(closureService *services.ClosureService, logger *slog.Logger) *ClosureHandler {
+	return &ClosureHandler{
+		closureService: closureService,
+		logger:         logger,
+	}
+}
+
+func (h *ClosureHandler) CreateClosure(ctx context.Context, input *models.CreateClosureInput) (*models.CreateClosureOutput, error) {
+	h.logger.Info("Received closure creation request",
+		"start_date", input.Body.StartDate.String(),
+		"end_date", input.Body.EndDate.String(),
+		"half_day", input.Body.HalfDay)
+
+	closure, affected, err := h.closureService.CreateClosure(ctx, toClosureRequest(input.Body))
+	if err != nil {
+		h.logger.Error("Failed to create closure", "error", err)
+		return nil, closureError(err)
+	}
+
+	return &models.CreateClosureOutput{Body: toClosureWithAffectedBody(closure, affected)}, nil
+}
+
+func (h *ClosureHandler) GetClosure(ctx context.Context, input *models.GetClosureInput) (*models.GetClosureOutput, error) {
+	h.logger.Info("Received closure lookup request", "id", input.ID)
+
+	closure, err := h.closureService.GetClosure(ctx, input.ID)
+	if err != nil {
+		return nil, closureError(err)
+	}
+
+	return &models.GetClosureOutput{Body: toClosureBody(closure)}, nil
+}
+
+func (h *ClosureHandler) ListClosures(ctx context.Context, input *models.ListClosuresInput) (*models.ListClosuresOutput, error) {
+	h.logger.Info("Received closure list request",
+		"from", input.From.String(),
+		"to", input.To.String())
+
+	closures, err := h.closureService.ListClosures(ctx, input.From, input.To)
+	if err != nil {
+		h.logger.Error("Failed to list closures", "error", err)
+		return nil, closureError(err)
+	}
+
+	output := &models.ListClosuresOutput{}
+	output.Body.Items = make([]models.ClosureBody, 0, len(closures))
+	for i := range closures {
+		output.Body.Items = append(output.Body.Items, toClosureBody(&closures[i]))
+	}
+
+	return output, nil
+}
+
+func (h *ClosureHandler) UpdateClosure(ctx context.Context, input *models.UpdateClosureInput) (*models.UpdateClosureOutput, error) {
+	h.logger.Info("Received closure update request",
+		"id", input.ID,
+		"start_date", input.Body.StartDate.String(),
+		"end_date", input.Body.EndDate.String(),
+		"half_day", input.Body.HalfDay)
+
+	closure, affected, err := h.closureService.UpdateClosure(ctx, input.ID, toClosureRequest(input.Body))
+	if err != nil {
+		h.logger.Error("Failed to update closure", "error", err, "id", input.ID)
+		return nil, closureError(err)
+	}
+
+	return &models.UpdateClosureOutput{Body: toClosureWithAffectedBody(closure, affected)}, nil
+}
+
+func (h *ClosureHandler) DeleteClosure(ctx context.Context, input *models.DeleteClosureInput) (*struct{}, error) {
+	h.logger.Info("Received closure deletion request", "id", input.ID)
+
+	if err := h.closureService.DeleteClosure(ctx, input.ID); err != nil {
+		return nil, closureError(err)
+	}
+
+	return nil, nil
+}
+
+// maps domain errors from closure operations to HTTP errors
+func closureError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrClosureNotFound):
+		return notFound("Closure not found", err)
+	case errors.Is(err, services.ErrInvalidDateRange):
+		return unprocessable("The start date must not be after the end date", err)
+	case errors.Is(err, services.ErrDateRangeTooLong):
+		return unprocessable("A closure must not exceed a year", err)
+	case errors.Is(err, services.ErrInvalidInput):
+		return unprocessable("Invalid input data", err)
+	default:
+		return internalError()
+	}
+}
+
+func toClosureRequest(body models.ClosureInputBody) *services.ClosureRequest {
+	return &services.ClosureRequest{
+		StartDate: body.StartDate,
+		EndDate:   body.EndDate,
+		Reason:    body.Reason,
+		HalfDay:   body.HalfDay,
+	}
+}
+
+// maps a database closure to its API representation
+func toClosureBody(closure *dbModels.Closure) models.ClosureBody {
+	return models.ClosureBody{
+		ID:        closure.ID,
+		StartDate: closure.StartDate,
+		EndDate:   closure.EndDate,
+		Reason:    closure.Reason,
+		HalfDay:   closure.HalfDay,
+		CreatedAt: closure.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: closure.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func toClosureWithAffectedBody(closure *dbModels.Closure, affected []dbModels.Appointment) models.ClosureWithAffectedBody {
+	body := models.ClosureWithAffectedBody{
+		ClosureBody:          toClosureBody(closure),
+		AffectedAppointments: make([]models.AppointmentBody, 0, len(affected)),
+	}
+	for i := range affected {
+		body.AffectedAppointments = append(body.AffectedAppointments, toAppointmentBody(&affected[i]))
+	}
+	return body
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"citynext/internal/api/models"
+	"citynext/internal/errcode"
+	"citynext/internal/services"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// codes for failures that do not come from a domain error
+const (
+	CodeInvalidRequest = "INVALID_REQUEST" // rejected by Huma's request validation
+	CodeInternalError  = "INTERNAL_ERROR"
+)
+
+// stands in for unexpected errors, whose text must not reach the client
+var errInternal = errcode.New(CodeInternalError, "", "internal server error")
+
+// single entry of a problem response's errors[] field
+type ProblemDetail struct {
+	Code     string `json:"code" example:"DATE_IN_PAST" doc:"Stable, machine-readable error code"`
+	Message  string `json:"message,omitempty" doc:"Human-readable description of the error"`
+	Location string `json:"location,omitempty" example:"body.visitDate" doc:"Where the error occurred, e.g. 'body.visitDate' or 'path.id'"`
+	Value    any    `json:"value,omitempty" doc:"The value at the given location"`
+}
+
+// RFC 9457 problem details; the same shape as huma.ErrorModel, except that
+// every entry in errors[] carries a code
+type Problem struct {
+	Type     string           `json:"type,omitempty" format:"uri" default:"about:blank" doc:"A URI reference to human-readable documentation for the error."`
+	Title    string           `json:"title,omitempty" example:"Unprocessable Entity" doc:"A short, human-readable summary of the problem type."`
+	Status   int              `json:"status,omitempty" example:"422" doc:"HTTP status code"`
+	Detail   string           `json:"detail,omitempty" example:"Visit date is a public holiday" doc:"A human-readable explanation specific to this occurrence of the problem."`
+	Instance string           `json:"instance,omitempty" format:"uri" doc:"A URI reference that identifies the specific occurrence of the problem."`
+	Errors   []*ProblemDetail `json:"errors,omitempty" doc:"Individual errors, each with a stable code"`
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+func (p *Problem) GetStatus() int {
+	return p.Status
+}
+
+func (p *Problem) ContentType(ct string) string {
+	if ct == "application/json" {
+		return "application/problem+json"
+	}
+	if ct == "application/cbor" {
+		return "application/problem+cbor"
+	}
+	return ct
+}
+
+// builds the Problem for a status, with an entry in errors[] for every error
+func NewProblem(status int, msg string, errs ...error) huma.StatusError {
+	return newProblem(status, msg, errs...)
+}
+
+// response transformer that turns the errors Huma writes itself, such as request validation
+// failures, into Problems, so that every error response carries machine-readable codes
+func TransformProblem(ctx huma.Context, status string, v any) (any, error) {
+	model, ok := v.(*huma.ErrorModel)
+	if !ok {
+		return v, nil
+	}
+	errs := make([]error, 0, len(model.Errors))
+	for _, detail := range model.Errors {
+		errs = append(errs, detail)
+	}
+	problem := newProblem(model.Status, model.Detail, errs...)
+	problem.Instance = model.Instance
+	return problem, nil
+}
+
+func newProblem(status int, msg string, errs ...error) *Problem {
+	problem := &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: msg,
+	}
+	for _, err := range errs {
+		if err != nil {
+			problem.Errors = append(problem.Errors, toProblemDetail(status, err))
+		}
+	}
+	return problem
+}
+
+func toProblemDetail(status int, err error) *ProblemDetail {
+	if coded := errcode.From(err); coded != nil {
+		return &ProblemDetail{
+			Code:     coded.Code,
+			Message:  coded.Error(),
+			Location: coded.Location,
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		return &ProblemDetail{Code: CodeInternalError}
+	}
+
+	var detailer huma.ErrorDetailer
+	if errors.As(err, &detailer) {
+		detail := detailer.ErrorDetail()
+		return &ProblemDetail{
+			Code:     CodeInvalidRequest,
+			Message:  detail.Message,
+			Location: detail.Location,
+			Value:    detail.Value,
+		}
+	}
+
+	return &ProblemDetail{Code: CodeInvalidRequest, Message: err.Error()}
+}
+
+// 404 response for a resource that does not exist
+func notFound(msg string, err error) error {
+	return newProblem(http.StatusNotFound, msg, err)
+}
+
+// 409 response for a request that conflicts with the current state
+func conflict(msg string, err error) error {
+	return newProblem(http.StatusConflict, msg, err)
+}
+
+// 422 response for a request that breaks a validation or booking rule
+func unprocessable(msg string, err error) error {
+	return newProblem(http.StatusUnprocessableEntity, msg, err)
+}
+
+// 503 response for a booking that cannot be checked against public holidays right now
+func holidayDataUnavailable(err error) error {
+	return newProblem(http.StatusServiceUnavailable, "Public holiday data is currently unavailable, please try again later", err)
+}
+
+// 500 response that does not reveal the underlying error
+func internalError() error {
+	return newProblem(http.StatusInternalServerError, "Internal server error", errInternal)
+}
+
+// problem response for a rejected booking, listing dates that can be booked instead
+type BookingRejectedError struct {
+	Problem
+	SuggestedDates []models.Date `json:"suggestedDates" doc:"Nearest bookable dates on or after the requested one"`
+}
+
+// builds a BookingRejectedError with alternatives to date; a failed lookup only
+// drops the suggestions, so the original rejection still reaches the client
+func (h *AppointmentHandler) rejectWithSuggestions(ctx context.Context, status int, msg string, cause error, date models.Date) error {
+	suggestions, err := h.appointmentService.SuggestDates(ctx, date, services.DefaultSuggestionCount)
+	if err != nil {
+		h.logger.Warn("Failed to suggest alternative dates",
+			"error", err,
+			"visit_date", date.String())
+		suggestions = []models.Date{}
+	}
+
+	return &BookingRejectedError{
+		Problem:        *newProblem(status, msg, cause),
+		SuggestedDates: suggestions,
+	}
+}
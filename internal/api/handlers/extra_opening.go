@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+)
+
+type ExtraOpeningHandler struct {
+	extraOpeningService *services.ExtraOpeningService
+	logger              *slog.Logger
+}
+
+func NewExtraOpeningHandler(extraOpeningService *services.ExtraOpeningService, logger *slog.Logger) *ExtraOpeningHandler {
+	return &ExtraOpeningHandler{
+		extraOpeningService: extraOpeningService,
+		logger:              logger,
+	}
+}
+
+func (h *ExtraOpeningHandler) CreateExtraOpening(ctx context.Context, input *models.CreateExtraOpeningInput) (*models.CreateExtraOpeningOutput, error) {
+	h.logger.Info("Received extra opening creation request",
+		"start_date", input.Body.StartDate.String(),
+		"end_date", input.Body.EndDate.String(),
+		"opens", input.Body.Opens.String(),
+		"closes", input.Body.Closes.String(),
+		"capacity", input.Body.Capacity)
+
+	opening, err := h.extraOpeningService.CreateExtraOpening(ctx, toExtraOpeningRequest(input.Body))
+	if err != nil {
+		h.logger.Error("Failed to create extra opening", "error", err)
+		return nil, extraOpeningError(err)
+	}
+
+	return &models.CreateExtraOpeningOutput{Body: toExtraOpeningBody(opening)}, nil
+}
+
+func (h *ExtraOpeningHandler) GetExtraOpening(ctx context.Context, input *models.GetExtraOpeningInput) (*models.GetExtraOpeningOutput, error) {
+	h.logger.Info("Received extra opening lookup request", "id", input.ID)
+
+	opening, err := h.extraOpeningService.GetExtraOpening(ctx, input.ID)
+	if err != nil {
+		return nil, extraOpeningError(err)
+	}
+
+	return &models.GetExtraOpeningOutput{Body: toExtraOpeningBody(opening)}, nil
+}
+
+func (h *ExtraOpeningHandler) ListExtraOpenings(ctx context.Context, input *models.ListExtraOpeningsInput) (*models.ListExtraOpeningsOutput, error) {
+	h.logger.Info("Received extra opening list request",
+		"from", input.From.String(),
+		"to", input.To.String())
+
+	openings, err := h.extraOpeningService.ListExtraOpenings(ctx, input.From, input.To)
+	if err != nil {
+		h.logger.Error("Failed to list extra openings", "error", err)
+		return nil, extraOpeningError(err)
+	}
+
+	output := &models.ListExtraOpeningsOutput{}
+	output.Body.Items = make([]models.ExtraOpeningBody, 0, len(openings))
+	for i := range openings {
+		output.Body.Items = append(output.Body.Items, toExtraOpeningBody(&openings[i]))
+	}
+
+	return output, nil
+}
+
+func (h *ExtraOpeningHandler) UpdateExtraOpening(ctx context.Context, input *models.UpdateExtraOpeningInput) (*models.UpdateExtraOpeningOutput, error) {
+	h.logger.Info("Received extra opening update request",
+		"id", input.ID,
+		"start_date", input.Body.StartDate.String(),
+		"end_date", input.Body.EndDate.String(),
+		"opens", input.Body.Opens.String(),
+		"closes", input.Body.Closes.String(),
+		"capacity", input.Body.Capacity)
+
+	opening, err := h.extraOpeningService.UpdateExtraOpening(ctx, input.ID, toExtraOpeningRequest(input.Body))
+	if err != nil {
+		h.logger.Error("Failed to update extra opening", "error", err, "id", input.ID)
+		return nil, extraOpeningError(err)
+	}
+
+	return &models.UpdateExtraOpeningOutput{Body: toExtraOpeningBody(opening)}, nil
+}
+
+func (h *ExtraOpeningHandler) DeleteExtraOpening(ctx context.Context, input *models.DeleteExtraOpeningInput) (*struct{}, error) {
+	h.logger.Info("Received extra opening deletion request", "id", input.ID)
+
+	if err := h.extraOpeningService.DeleteExtraOpening(ctx, input.ID); err != nil {
+		return nil, extraOpeningError(err)
+	}
+
+	return nil, nil
+}
+
+// maps domain errors from extra opening operations to HTTP errors
+func extraOpeningError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrExtraOpeningNotFound):
+		return notFound("Extra opening not found", err)
+	case errors.Is(err, database.ErrExtraOpeningOverlap):
+		return conflict("The dates overlap another extra opening", err)
+	case errors.Is(err, services.ErrInvalidDateRange):
+		return unprocessable("The start date must not be after the end date", err)
+	case errors.Is(err, services.ErrDateRangeTooLong):
+		return unprocessable("An extra opening must not exceed a year", err)
+	case errors.Is(err, services.ErrInvalidInput):
+		return unprocessable("Invalid input data", err)
+	default:
+		return internalError()
+	}
+}
+
+func toExtraOpeningRequest(body models.ExtraOpeningInputBody) *services.ExtraOpeningRequest {
+	return &services.ExtraOpeningRequest{
+		StartDate: body.StartDate,
+		EndDate:   body.EndDate,
+		Reason:    body.Reason,
+		Opens:     body.Opens,
+		Closes:    body.Closes,
+		Capacity:  body.Capacity,
+	}
+}
+
+// maps a database extra opening to its API representation
+func toExtraOpeningBody(opening *dbModels.ExtraOpening) models.ExtraOpeningBody {
+	return models.ExtraOpeningBody{
+		ID:        opening.ID,
+		StartDate: opening.StartDate,
+		EndDate:   opening.EndDate,
+		Reason:    opening.Reason,
+		Opens:     opening.Opens,
+		Closes:    opening.Closes,
+		Capacity:  opening.Capacity,
+		CreatedAt: opening.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: opening.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
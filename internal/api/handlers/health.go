@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"citynext/internal/api/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+)
+
+type HealthHandler struct {
+	provider services.ProviderStater
+	logger   *slog.Logger
+}
+
+func NewHealthHandler(provider services.ProviderStater, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// reports the service as degraded, not down, while the holiday provider's circuit breaker is not closed:
+// bookings still work from cached and stored holidays
+func (h *HealthHandler) GetHealth(ctx context.Context, input *struct{}) (*models.GetHealthOutput, error) {
+	state := h.provider.ProviderState()
+
+	output := &models.GetHealthOutput{}
+	output.Body.Status = "ok"
+	output.Body.HolidayProvider = state.String()
+	if state != client.CircuitClosed {
+		output.Body.Status = "degraded"
+		h.logger.Debug("Reporting degraded health", "holiday_provider", state.String())
+	}
+	return output, nil
+}
@@ -3,19 +3,124 @@ package models
 // represents the input for creating an appointment
 type CreateAppointmentInput struct {
 	Body struct {
-		FirstName string `json:"firstName" example:"John" doc:"First name of the person" maxLength:"50"`
-		LastName  string `json:"lastName" example:"Doe" doc:"Last name of the person" maxLength:"50"`
-		VisitDate Date   `json:"visitDate" example:"2025-08-15" doc:"Visit date (YYYY-MM-DD format)"`
+		FirstName string    `json:"firstName" example:"John" doc:"First name of the person" maxLength:"50"`
+		LastName  string    `json:"lastName" example:"Doe" doc:"Last name of the person" maxLength:"50"`
+		VisitDate Date      `json:"visitDate" example:"2025-08-15" doc:"Visit date (YYYY-MM-DD format)"`
+		StartTime TimeOfDay `json:"startTime" example:"09:30" doc:"Start of the appointment slot (HH:MM, 24-hour clock)"`
 	}
 }
 
+// represents an appointment as returned by the API
+type AppointmentBody struct {
+	ID        uint      `json:"id" example:"1" doc:"Appointment ID"`
+	Reference string    `json:"reference,omitempty" example:"CN-7K4Q-92XD" doc:"Booking reference to quote when managing the appointment"`
+	FirstName string    `json:"firstName" example:"John" doc:"First name of the person"`
+	LastName  string    `json:"lastName" example:"Doe" doc:"Last name of the person"`
+	VisitDate Date      `json:"visitDate" example:"2025-08-15" doc:"Visit date"`
+	StartTime TimeOfDay `json:"startTime" example:"09:30" doc:"Start of the appointment slot"`
+	Status    string    `json:"status" enum:"active,cancelled" example:"active" doc:"Whether the appointment is active or cancelled"`
+	CreatedAt string    `json:"createdAt" example:"2025-08-15T10:30:00Z" doc:"Creation timestamp"`
+
+	CancelledAt        string `json:"cancelledAt,omitempty" example:"2025-08-10T09:00:00Z" doc:"Cancellation timestamp"`
+	CancelledBy        string `json:"cancelledBy,omitempty" example:"front-desk" doc:"Who cancelled the appointment"`
+	CancellationReason string `json:"cancellationReason,omitempty" example:"Citizen request" doc:"Why the appointment was cancelled"`
+
+	HolidayUnverified bool `json:"holidayUnverified,omitempty" example:"false" doc:"Set when the visit date was accepted while public holidays could not be checked; it is re-checked once holiday data is available"`
+	HolidayConflict   bool `json:"holidayConflict,omitempty" example:"false" doc:"Set when the re-check found the visit date to be a public holiday; staff should contact the citizen to rebook"`
+}
+
+// represents a newly booked appointment, including the secret needed to manage it
+type CreatedAppointmentBody struct {
+	AppointmentBody
+	ManagementToken string `json:"managementToken" example:"pX3w9b0kQ2nH7sYdV1uR8cLmT4eJ6aFz5gK0iNoBqWs" doc:"Secret for viewing, cancelling or rescheduling the booking by reference; it is only returned once"`
+}
+
 // represents the output of a successful appointment creation
 type CreateAppointmentOutput struct {
+	Body CreatedAppointmentBody
+}
+
+// represents the input for checking whether an appointment could be booked, without booking it
+type CheckAppointmentInput struct {
+	Body struct {
+		FirstName string    `json:"firstName" example:"John" doc:"First name of the person" maxLength:"50"`
+		LastName  string    `json:"lastName" example:"Doe" doc:"Last name of the person" maxLength:"50"`
+		VisitDate Date      `json:"visitDate" example:"2025-08-15" doc:"Visit date (YYYY-MM-DD format)"`
+		StartTime TimeOfDay `json:"startTime" example:"09:30" doc:"Start of the appointment slot (HH:MM, 24-hour clock)"`
+	}
+}
+
+// represents a booking rule an appointment breaks
+type ViolationBody struct {
+	Rule     string `json:"rule" example:"holiday" doc:"Name of the rule"`
+	Code     string `json:"code" example:"DATE_IS_HOLIDAY" doc:"Error code a booking breaking the rule is rejected with"`
+	Message  string `json:"message" example:"visit date is a public holiday" doc:"Human-readable description of the violation"`
+	Location string `json:"location,omitempty" example:"body.visitDate" doc:"Request field the violation refers to"`
+}
+
+// represents every booking rule an appointment breaks
+type CheckAppointmentOutput struct {
+	Body struct {
+		Bookable          bool            `json:"bookable" example:"false" doc:"Whether the appointment breaks no rule; it may still be taken before it is booked"`
+		Violations        []ViolationBody `json:"violations" doc:"Every rule the appointment breaks, in the order they are checked; a booking is rejected with the first"`
+		HolidayUnverified bool            `json:"holidayUnverified,omitempty" example:"false" doc:"Set when public holidays could not be checked for the visit date"`
+	}
+}
+
+// represents the input for retrieving a single appointment
+type GetAppointmentInput struct {
+	ID uint `path:"id" example:"1" doc:"Appointment ID"`
+}
+
+// represents the output of a single appointment lookup
+type GetAppointmentOutput struct {
+	Body AppointmentBody
+}
+
+// represents the input for listing appointments
+type ListAppointmentsInput struct {
+	From             Date   `query:"from" example:"2025-08-01" doc:"Only include visits on or after this date (YYYY-MM-DD format)"`
+	To               Date   `query:"to" example:"2025-08-31" doc:"Only include visits on or before this date (YYYY-MM-DD format)"`
+	LastName         string `query:"lastName" example:"Doe" doc:"Only include appointments for this last name (case-insensitive)" maxLength:"50"`
+	IncludeCancelled bool   `query:"includeCancelled" default:"false" doc:"Also include cancelled appointments"`
+	Page             int    `query:"page" default:"1" minimum:"1" doc:"Page number, starting at 1"`
+	PageSize         int    `query:"pageSize" default:"20" minimum:"1" maximum:"100" doc:"Number of appointments per page"`
+}
+
+// represents one page of appointments
+type ListAppointmentsOutput struct {
+	Body struct {
+		Items    []AppointmentBody `json:"items" doc:"Appointments on this page, ordered by visit date"`
+		Total    int64             `json:"total" example:"42" doc:"Total number of matching appointments"`
+		Page     int               `json:"page" example:"1" doc:"Current page number"`
+		PageSize int               `json:"pageSize" example:"20" doc:"Number of appointments per page"`
+	}
+}
+
+// represents the input for cancelling an appointment
+type CancelAppointmentInput struct {
+	ID   uint `path:"id" example:"1" doc:"Appointment ID"`
 	Body struct {
-		ID        uint   `json:"id" example:"1" doc:"Appointment ID"`
-		FirstName string `json:"firstName" example:"John" doc:"First name of the person"`
-		LastName  string `json:"lastName" example:"Doe" doc:"Last name of the person"`
-		VisitDate Date   `json:"visitDate" example:"2025-08-15" doc:"Visit date"`
-		CreatedAt string `json:"createdAt" example:"2025-08-15T10:30:00Z" doc:"Creation timestamp"`
+		CancelledBy string `json:"cancelledBy" example:"front-desk" doc:"Who is cancelling the appointment" minLength:"1" maxLength:"100"`
+		Reason      string `json:"reason,omitempty" example:"Citizen request" doc:"Why the appointment is cancelled" maxLength:"500"`
 	}
 }
+
+// represents the output of a successful cancellation
+type CancelAppointmentOutput struct {
+	Body AppointmentBody
+}
+
+// represents the input for moving an appointment to a new slot
+type RescheduleAppointmentInput struct {
+	ID   uint `path:"id" example:"1" doc:"Appointment ID"`
+	Body struct {
+		VisitDate Date      `json:"visitDate" example:"2025-08-22" doc:"New visit date (YYYY-MM-DD format)"`
+		StartTime TimeOfDay `json:"startTime" example:"14:00" doc:"Start of the new appointment slot (HH:MM, 24-hour clock)"`
+	}
+}
+
+// represents the output of a successful reschedule
+type RescheduleAppointmentOutput struct {
+	Body AppointmentBody
+}
@@ -0,0 +1,31 @@
+package models
+
+// represents the input for checking which dates can be booked
+type GetAvailabilityInput struct {
+	From Date `query:"from" required:"true" example:"2025-09-01" doc:"First date to check (YYYY-MM-DD format)"`
+	To   Date `query:"to" required:"true" example:"2025-09-30" doc:"Last date to check, inclusive (YYYY-MM-DD format)"`
+}
+
+// represents the bookability of a single date
+type DayAvailabilityBody struct {
+	Date         Date     `json:"date" example:"2025-09-25" doc:"Calendar date"`
+	Bookable     bool     `json:"bookable" example:"true" doc:"Whether an appointment can be booked on this date"`
+	Reason       string   `json:"reason,omitempty" enum:"past,too_soon,too_far,weekend,holiday,closed,full" example:"holiday" doc:"Why the date cannot be booked; the first of reasons"`
+	Reasons      []string `json:"reasons,omitempty" enum:"past,too_soon,too_far,weekend,holiday,closed,full" example:"[\"holiday\"]" doc:"Every reason the date cannot be booked, most important first"`
+	HolidayName  string   `json:"holidayName,omitempty" example:"Christmas Day" doc:"Name of the public holiday, when the date is one"`
+	Closure      string   `json:"closureReason,omitempty" example:"Staff training" doc:"Why the office is closed, when reason is closed or the office closes at midday"`
+	HalfDay      bool     `json:"halfDay,omitempty" example:"false" doc:"Set when the office closes at midday, so only morning slots can be booked"`
+	ExtraOpening string   `json:"extraOpening,omitempty" example:"Saturday surgery" doc:"Why the office opens on a date that would otherwise be closed"`
+	Remaining    int      `json:"remaining" example:"12" doc:"Number of appointments still available on this date"`
+
+	HolidayUnverified bool `json:"holidayUnverified,omitempty" example:"false" doc:"Set when public holidays could not be checked for this date"`
+}
+
+// represents the bookability of every date in the requested range
+type GetAvailabilityOutput struct {
+	Body struct {
+		From Date                  `json:"from" example:"2025-09-01" doc:"First date checked"`
+		To   Date                  `json:"to" example:"2025-09-30" doc:"Last date checked"`
+		Days []DayAvailabilityBody `json:"days" doc:"One entry per date, in order"`
+	}
+}
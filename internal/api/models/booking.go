@@ -0,0 +1,44 @@
+package models
+
+// identifies a booking for citizen self-service, without an account
+type BookingCredentials struct {
+	Reference string `path:"reference" example:"CN-7K4Q-92XD" doc:"Booking reference" maxLength:"20"`
+	Token     string `header:"X-Management-Token" required:"true" doc:"Management token returned when the appointment was booked" maxLength:"100"`
+}
+
+// represents the input for a citizen viewing their booking
+type GetBookingInput struct {
+	BookingCredentials
+}
+
+// represents the output of a booking lookup
+type GetBookingOutput struct {
+	Body AppointmentBody
+}
+
+// represents the input for a citizen cancelling their booking
+type CancelBookingInput struct {
+	BookingCredentials
+	Body *struct {
+		Reason string `json:"reason,omitempty" example:"Cannot make it" doc:"Why the appointment is cancelled" maxLength:"500"`
+	}
+}
+
+// represents the output of a successful booking cancellation
+type CancelBookingOutput struct {
+	Body AppointmentBody
+}
+
+// represents the input for a citizen moving their booking to a new slot
+type RescheduleBookingInput struct {
+	BookingCredentials
+	Body struct {
+		VisitDate Date      `json:"visitDate" example:"2025-08-22" doc:"New visit date (YYYY-MM-DD format)"`
+		StartTime TimeOfDay `json:"startTime" example:"14:00" doc:"Start of the new appointment slot (HH:MM, 24-hour clock)"`
+	}
+}
+
+// represents the output of a successful booking reschedule
+type RescheduleBookingOutput struct {
+	Body AppointmentBody
+}
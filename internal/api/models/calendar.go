@@ -0,0 +1,76 @@
+package models
+
+// represents a public holiday the office observes
+type HolidayBody struct {
+	Date      Date     `json:"date" example:"2025-12-25" doc:"Date of the holiday"`
+	Name      string   `json:"name" example:"Christmas Day" doc:"English name of the holiday"`
+	LocalName string   `json:"localName" example:"Christmas Day" doc:"Name of the holiday in the local language"`
+	Global    bool     `json:"global" example:"true" doc:"Whether the holiday is observed across the whole country"`
+	Counties  []string `json:"counties,omitempty" example:"[\"GB-ENG\",\"GB-WLS\"]" doc:"Subdivisions observing the holiday, when it is not global"`
+	Types     []string `json:"types,omitempty" example:"[\"Public\"]" doc:"Kinds of holiday reported by the holiday source, e.g. Public or Bank"`
+}
+
+// represents the input for listing a year's public holidays
+type GetHolidaysInput struct {
+	Year        int    `query:"year" required:"true" minimum:"1900" maximum:"2200" example:"2025" doc:"Year to list holidays for, from last year through next year or the last bookable year"`
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag of a cached response; an unchanged response is answered with 304 Not Modified"`
+}
+
+// represents the public holidays of a year
+type GetHolidaysOutput struct {
+	Status       int
+	ETag         string `header:"ETag" doc:"Version of the response, for use with If-None-Match"`
+	CacheControl string `header:"Cache-Control" doc:"How long the response may be cached"`
+	Body         struct {
+		Year     int           `json:"year" example:"2025" doc:"Year listed"`
+		Holidays []HolidayBody `json:"holidays" doc:"Observed public holidays, in date order"`
+	}
+}
+
+// represents the input for listing the dates the office is shut
+type GetCalendarInput struct {
+	From        Date   `query:"from" required:"true" example:"2025-12-01" doc:"First date to list (YYYY-MM-DD format)"`
+	To          Date   `query:"to" required:"true" example:"2025-12-31" doc:"Last date to list, inclusive (YYYY-MM-DD format)"`
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag of a cached response; an unchanged response is answered with 304 Not Modified"`
+}
+
+// represents a reason the office is shut, or shuts early, on a date
+type CalendarEntryBody struct {
+	Date    Date         `json:"date" example:"2025-12-25" doc:"Calendar date"`
+	Reason  string       `json:"reason" enum:"weekend,holiday,closed" example:"holiday" doc:"Why the office is shut: a day of the week it does not open, a public holiday or a closure"`
+	Name    string       `json:"name,omitempty" example:"Christmas Day" doc:"Name of the holiday or reason for the closure"`
+	Holiday *HolidayBody `json:"holiday,omitempty" doc:"The public holiday, when reason is holiday"`
+	HalfDay bool         `json:"halfDay,omitempty" example:"false" doc:"Set when a closure only shuts the office from midday"`
+}
+
+// represents the dates the office is shut in the requested range
+type GetCalendarOutput struct {
+	Status       int
+	ETag         string `header:"ETag" doc:"Version of the response, for use with If-None-Match"`
+	CacheControl string `header:"Cache-Control" doc:"How long the response may be cached"`
+	Body         struct {
+		From    Date                `json:"from" example:"2025-12-01" doc:"First date listed"`
+		To      Date                `json:"to" example:"2025-12-31" doc:"Last date listed"`
+		Entries []CalendarEntryBody `json:"entries" doc:"One entry per reason the office is shut on a date, in date order; dates the office opens as usual are omitted"`
+	}
+}
+
+// represents the input for working-day arithmetic
+type GetWorkingDaysInput struct {
+	From Date `query:"from" example:"2025-12-22" doc:"Date to count from (YYYY-MM-DD format), at most 366 days from today; defaults to today at the office"`
+	Days int  `query:"days" minimum:"-366" maximum:"366" example:"2" doc:"Number of working days to move on from 'from', or back when negative"`
+	To   Date `query:"to" example:"2026-01-09" doc:"Date to count working days up to, inclusive (YYYY-MM-DD format)"`
+}
+
+// represents working-day arithmetic from a date
+type GetWorkingDaysOutput struct {
+	Body struct {
+		From               Date  `json:"from" example:"2025-12-22" doc:"Date counted from"`
+		IsWorkingDay       bool  `json:"isWorkingDay" example:"true" doc:"Whether 'from' is a working day"`
+		NextWorkingDay     Date  `json:"nextWorkingDay" example:"2025-12-23" doc:"First working day after 'from'"`
+		Days               int   `json:"days" example:"2" doc:"Number of working days moved"`
+		Date               Date  `json:"date" example:"2025-12-24" doc:"The date 'days' working days on from 'from'"`
+		To                 *Date `json:"to,omitempty" example:"2026-01-09" doc:"Date counted up to, when requested"`
+		WorkingDaysBetween *int  `json:"workingDaysBetween,omitempty" example:"11" doc:"Working days after 'from' up to and including 'to', negative when 'to' is before 'from'"`
+	}
+}
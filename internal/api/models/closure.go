@@ -0,0 +1,75 @@
+package models
+
+// fields of a closure as sent by staff when creating or replacing it
+type ClosureInputBody struct {
+	StartDate Date   `json:"startDate" example:"2025-11-03" doc:"First day of the closure (YYYY-MM-DD format)"`
+	EndDate   Date   `json:"endDate,omitempty" example:"2025-11-04" doc:"Last day of the closure, inclusive (YYYY-MM-DD format); defaults to startDate"`
+	Reason    string `json:"reason" example:"Staff training" doc:"Why the office is closed" minLength:"1" maxLength:"200"`
+	HalfDay   bool   `json:"halfDay,omitempty" default:"false" doc:"Close from midday only, keeping morning slots bookable"`
+}
+
+// represents a closure as returned by the API
+type ClosureBody struct {
+	ID        uint   `json:"id" example:"1" doc:"Closure ID"`
+	StartDate Date   `json:"startDate" example:"2025-11-03" doc:"First day of the closure"`
+	EndDate   Date   `json:"endDate" example:"2025-11-04" doc:"Last day of the closure, inclusive"`
+	Reason    string `json:"reason" example:"Staff training" doc:"Why the office is closed"`
+	HalfDay   bool   `json:"halfDay" example:"false" doc:"Whether the office only closes from midday"`
+	CreatedAt string `json:"createdAt" example:"2025-10-01T10:30:00Z" doc:"Creation timestamp"`
+	UpdatedAt string `json:"updatedAt" example:"2025-10-01T10:30:00Z" doc:"Last update timestamp"`
+}
+
+// represents a closure together with the active appointments that fall inside it
+type ClosureWithAffectedBody struct {
+	ClosureBody
+	AffectedAppointments []AppointmentBody `json:"affectedAppointments" doc:"Active appointments inside the closure, whose citizens need to be contacted"`
+}
+
+// represents the input for creating a closure
+type CreateClosureInput struct {
+	Body ClosureInputBody
+}
+
+// represents the output of a successful closure creation
+type CreateClosureOutput struct {
+	Body ClosureWithAffectedBody
+}
+
+// represents the input for retrieving a single closure
+type GetClosureInput struct {
+	ID uint `path:"id" example:"1" doc:"Closure ID"`
+}
+
+// represents the output of a single closure lookup
+type GetClosureOutput struct {
+	Body ClosureBody
+}
+
+// represents the input for listing closures
+type ListClosuresInput struct {
+	From Date `query:"from" example:"2025-11-01" doc:"Only include closures lasting until this date or later (YYYY-MM-DD format)"`
+	To   Date `query:"to" example:"2025-11-30" doc:"Only include closures starting on or before this date (YYYY-MM-DD format)"`
+}
+
+// represents every closure matching the list filters
+type ListClosuresOutput struct {
+	Body struct {
+		Items []ClosureBody `json:"items" doc:"Closures ordered by start date"`
+	}
+}
+
+// represents the input for replacing a closure
+type UpdateClosureInput struct {
+	ID   uint `path:"id" example:"1" doc:"Closure ID"`
+	Body ClosureInputBody
+}
+
+// represents the output of a successful closure update
+type UpdateClosureOutput struct {
+	Body ClosureWithAffectedBody
+}
+
+// represents the input for deleting a closure
+type DeleteClosureInput struct {
+	ID uint `path:"id" example:"1" doc:"Closure ID"`
+}
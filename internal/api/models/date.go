@@ -40,6 +40,16 @@ func (d Date) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + d.Time.Format(dateLayout) + `"`), nil
 }
 
+// parses a date string (YYYY-MM-DD), used for query and path parameters
+func (d *Date) UnmarshalText(b []byte) error {
+	return d.UnmarshalJSON(b)
+}
+
+// formats the date as YYYY-MM-DD, used for query and path parameters
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
 // implements the driver.Value interface for database serialization
 func (d Date) Value() (driver.Value, error) {
 	if d.Time.IsZero() {
@@ -72,6 +82,17 @@ func (d *Date) Scan(value interface{}) error {
 	return fmt.Errorf("cannot scan type %T into Date", value)
 }
 
+// returns the calendar date of an instant as seen in the given location
+func DateOf(t time.Time, loc *time.Location) Date {
+	t = t.In(loc)
+	return Date{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// returns the instant at which the given time of day starts on this date, in the given location
+func (d Date) At(t TimeOfDay, loc *time.Location) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, t.Minutes(), 0, 0, loc)
+}
+
 // returns the date as YYYY-MM-DD
 func (d Date) String() string {
 	return d.Time.Format(dateLayout)
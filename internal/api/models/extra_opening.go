@@ -0,0 +1,73 @@
+package models
+
+// fields of an extra opening as sent by staff when creating or replacing it
+type ExtraOpeningInputBody struct {
+	StartDate Date      `json:"startDate" example:"2025-11-08" doc:"First day of the extra opening (YYYY-MM-DD format)"`
+	EndDate   Date      `json:"endDate,omitempty" example:"2025-11-08" doc:"Last day of the extra opening, inclusive (YYYY-MM-DD format); defaults to startDate"`
+	Reason    string    `json:"reason" example:"Saturday surgery" doc:"Why the office opens" minLength:"1" maxLength:"200"`
+	Opens     TimeOfDay `json:"opens" example:"09:00" doc:"Opening time on these days (HH:MM, 24-hour clock)"`
+	Closes    TimeOfDay `json:"closes" example:"13:00" doc:"Closing time on these days (HH:MM, 24-hour clock)"`
+	Capacity  int       `json:"capacity" example:"10" doc:"Maximum number of appointments per day" minimum:"1"`
+}
+
+// represents an extra opening as returned by the API
+type ExtraOpeningBody struct {
+	ID        uint      `json:"id" example:"1" doc:"Extra opening ID"`
+	StartDate Date      `json:"startDate" example:"2025-11-08" doc:"First day of the extra opening"`
+	EndDate   Date      `json:"endDate" example:"2025-11-08" doc:"Last day of the extra opening, inclusive"`
+	Reason    string    `json:"reason" example:"Saturday surgery" doc:"Why the office opens"`
+	Opens     TimeOfDay `json:"opens" example:"09:00" doc:"Opening time on these days"`
+	Closes    TimeOfDay `json:"closes" example:"13:00" doc:"Closing time on these days"`
+	Capacity  int       `json:"capacity" example:"10" doc:"Maximum number of appointments per day"`
+	CreatedAt string    `json:"createdAt" example:"2025-10-01T10:30:00Z" doc:"Creation timestamp"`
+	UpdatedAt string    `json:"updatedAt" example:"2025-10-01T10:30:00Z" doc:"Last update timestamp"`
+}
+
+// represents the input for creating an extra opening
+type CreateExtraOpeningInput struct {
+	Body ExtraOpeningInputBody
+}
+
+// represents the output of a successful extra opening creation
+type CreateExtraOpeningOutput struct {
+	Body ExtraOpeningBody
+}
+
+// represents the input for retrieving a single extra opening
+type GetExtraOpeningInput struct {
+	ID uint `path:"id" example:"1" doc:"Extra opening ID"`
+}
+
+// represents the output of a single extra opening lookup
+type GetExtraOpeningOutput struct {
+	Body ExtraOpeningBody
+}
+
+// represents the input for listing extra openings
+type ListExtraOpeningsInput struct {
+	From Date `query:"from" example:"2025-11-01" doc:"Only include extra openings lasting until this date or later (YYYY-MM-DD format)"`
+	To   Date `query:"to" example:"2025-11-30" doc:"Only include extra openings starting on or before this date (YYYY-MM-DD format)"`
+}
+
+// represents every extra opening matching the list filters
+type ListExtraOpeningsOutput struct {
+	Body struct {
+		Items []ExtraOpeningBody `json:"items" doc:"Extra openings ordered by start date"`
+	}
+}
+
+// represents the input for replacing an extra opening
+type UpdateExtraOpeningInput struct {
+	ID   uint `path:"id" example:"1" doc:"Extra opening ID"`
+	Body ExtraOpeningInputBody
+}
+
+// represents the output of a successful extra opening update
+type UpdateExtraOpeningOutput struct {
+	Body ExtraOpeningBody
+}
+
+// represents the input for deleting an extra opening
+type DeleteExtraOpeningInput struct {
+	ID uint `path:"id" example:"1" doc:"Extra opening ID"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// represents the health of the service and of the dependencies it cannot work around
+type GetHealthOutput struct {
+	Body struct {
+		Status          string `json:"status" enum:"ok,degraded" example:"ok" doc:"degraded while the holiday provider is unreachable and holidays come from the cache and the store only"`
+		HolidayProvider string `json:"holidayProvider" enum:"closed,open,half-open" example:"closed" doc:"State of the holiday provider's circuit breaker"`
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeOfDay represents a wall-clock time (HH:MM) with no date component
+// Implements JSON, text and SQL interfaces
+// Stored as minutes since midnight
+
+type TimeOfDay struct {
+	minutes int
+}
+
+const timeOfDayLayout = "15:04"
+
+// builds a TimeOfDay from hours and minutes
+func NewTimeOfDay(hour, minute int) TimeOfDay {
+	return TimeOfDay{minutes: hour*60 + minute}
+}
+
+// parses a time string (HH:MM) into a TimeOfDay object
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := time.Parse(timeOfDayLayout, strings.TrimSpace(s))
+	if err != nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day: %w", err)
+	}
+	return NewTimeOfDay(t.Hour(), t.Minute()), nil
+}
+
+// parses a time string (HH:MM) into a TimeOfDay object
+func (t *TimeOfDay) UnmarshalJSON(b []byte) error {
+	return t.UnmarshalText([]byte(strings.Trim(string(b), `"`)))
+}
+
+// formats the time as HH:MM
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// parses a time string (HH:MM), used for query and path parameters
+func (t *TimeOfDay) UnmarshalText(b []byte) error {
+	parsed, err := ParseTimeOfDay(string(b))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// formats the time as HH:MM, used for query and path parameters
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// implements the driver.Value interface for database serialization
+func (t TimeOfDay) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// implements the sql.Scanner interface for database deserialization
+func (t *TimeOfDay) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = TimeOfDay{}
+		return nil
+	case string:
+		return t.UnmarshalText([]byte(v))
+	case []byte:
+		return t.UnmarshalText(v)
+	}
+	return fmt.Errorf("cannot scan type %T into TimeOfDay", value)
+}
+
+// returns the number of minutes since midnight
+func (t TimeOfDay) Minutes() int {
+	return t.minutes
+}
+
+// returns the time shifted by the given duration, truncated to whole minutes
+func (t TimeOfDay) Add(d time.Duration) TimeOfDay {
+	return TimeOfDay{minutes: t.minutes + int(d/time.Minute)}
+}
+
+// reports whether t is earlier than other
+func (t TimeOfDay) Before(other TimeOfDay) bool {
+	return t.minutes < other.minutes
+}
+
+// reports whether t is later than other
+func (t TimeOfDay) After(other TimeOfDay) bool {
+	return t.minutes > other.minutes
+}
+
+// returns the time as HH:MM
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t.minutes/60, t.minutes%60)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"net/http"
+	"reflect"
 
 	"citynext/internal/api/handlers"
 
@@ -9,10 +10,87 @@ import (
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
 )
 
-func RegisterRoutes(router *http.ServeMux, appointmentHandler *handlers.AppointmentHandler) {
+// registers the appointment API and returns it, so that further endpoint groups can be added; booking is
+// public, while managing appointments by ID needs the admin token, as the IDs can be guessed
+func RegisterRoutes(router *http.ServeMux, appointmentHandler *handlers.AppointmentHandler, adminAuth *handlers.AdminAuth) huma.API {
 
-	api := humago.New(router, huma.DefaultConfig("CityNext Appointment API", "1.0.0"))
+	// every error response, including Huma's own validation errors, carries machine-readable codes
+	config := huma.DefaultConfig("CityNext Appointment API", "1.0.0")
+	config.Transformers = append(config.Transformers, handlers.TransformProblem)
+	config.OpenAPI.OnAddOperation = append(config.OpenAPI.OnAddOperation, documentProblems)
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		handlers.AdminSecurityScheme: {Type: "http", Scheme: "bearer", Description: "Admin token set by ADMIN_TOKEN"},
+	}
 
-	// expose the appointment creation endpoint
+	api := humago.New(router, config)
+
+	// expose the appointment endpoints
 	huma.Post(api, "/appointments", appointmentHandler.CreateAppointment)
+	huma.Post(api, "/appointments/check", appointmentHandler.CheckAppointment)
+	huma.Get(api, "/appointments", appointmentHandler.ListAppointments, adminOnly(adminAuth))
+	huma.Get(api, "/appointments/{id}", appointmentHandler.GetAppointment, adminOnly(adminAuth))
+	huma.Patch(api, "/appointments/{id}", appointmentHandler.RescheduleAppointment, adminOnly(adminAuth))
+	huma.Delete(api, "/appointments/{id}", appointmentHandler.CancelAppointment, adminOnly(adminAuth))
+
+	// expose citizen self-service by booking reference and management token
+	huma.Get(api, "/bookings/{reference}", appointmentHandler.GetBooking)
+	huma.Patch(api, "/bookings/{reference}", appointmentHandler.RescheduleBooking)
+	huma.Delete(api, "/bookings/{reference}", appointmentHandler.CancelBooking)
+
+	// expose the availability calendar
+	huma.Get(api, "/availability", appointmentHandler.GetAvailability)
+
+	return api
+}
+
+// exposes staff management of office closures; reading them stays public, changing them needs the admin token
+func RegisterClosureRoutes(api huma.API, closureHandler *handlers.ClosureHandler, adminAuth *handlers.AdminAuth) {
+	huma.Post(api, "/closures", closureHandler.CreateClosure, adminOnly(adminAuth))
+	huma.Get(api, "/closures", closureHandler.ListClosures)
+	huma.Get(api, "/closures/{id}", closureHandler.GetClosure)
+	huma.Put(api, "/closures/{id}", closureHandler.UpdateClosure, adminOnly(adminAuth))
+	huma.Delete(api, "/closures/{id}", closureHandler.DeleteClosure, adminOnly(adminAuth))
+}
+
+// exposes staff management of extra opening days; reading them stays public, changing them needs the admin token
+func RegisterExtraOpeningRoutes(api huma.API, extraOpeningHandler *handlers.ExtraOpeningHandler, adminAuth *handlers.AdminAuth) {
+	huma.Post(api, "/extra-openings", extraOpeningHandler.CreateExtraOpening, adminOnly(adminAuth))
+	huma.Get(api, "/extra-openings", extraOpeningHandler.ListExtraOpenings)
+	huma.Get(api, "/extra-openings/{id}", extraOpeningHandler.GetExtraOpening)
+	huma.Put(api, "/extra-openings/{id}", extraOpeningHandler.UpdateExtraOpening, adminOnly(adminAuth))
+	huma.Delete(api, "/extra-openings/{id}", extraOpeningHandler.DeleteExtraOpening, adminOnly(adminAuth))
+}
+
+// exposes the public holidays and the dates the office is shut, for booking UIs to grey out
+func RegisterCalendarRoutes(api huma.API, calendarHandler *handlers.CalendarHandler) {
+	huma.Get(api, "/holidays", calendarHandler.GetHolidays)
+	huma.Get(api, "/calendar", calendarHandler.GetCalendar)
+	huma.Get(api, "/calendar/working-days", calendarHandler.GetWorkingDays)
+}
+
+// exposes the service health, for load balancers and monitoring
+func RegisterHealthRoutes(api huma.API, healthHandler *handlers.HealthHandler) {
+	huma.Get(api, "/health", healthHandler.GetHealth)
+}
+
+// requires the admin token for an operation and documents its 401 response
+func adminOnly(adminAuth *handlers.AdminAuth) func(o *huma.Operation) {
+	return func(o *huma.Operation) {
+		o.Security = []map[string][]string{{handlers.AdminSecurityScheme: {}}}
+		o.Errors = append(o.Errors, http.StatusUnauthorized)
+		o.Middlewares = append(o.Middlewares, adminAuth.Middleware)
+	}
+}
+
+// documents error responses as Problems rather than Huma's ErrorModel, which TransformProblem replaces
+func documentProblems(oapi *huma.OpenAPI, op *huma.Operation) {
+	errorModel := oapi.Components.Schemas.Schema(reflect.TypeOf(huma.ErrorModel{}), true, "")
+	problem := oapi.Components.Schemas.Schema(reflect.TypeOf(handlers.Problem{}), true, "")
+	for _, response := range op.Responses {
+		for _, media := range response.Content {
+			if media.Schema != nil && media.Schema.Ref == errorModel.Ref {
+				media.Schema = problem
+			}
+		}
+	}
 }
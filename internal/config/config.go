@@ -3,22 +3,91 @@ package config
 import (
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	ServerPort      string
-	DBPath          string
-	LogLevel        slog.Level
-	NagerAPIBaseURL string
+	ServerPort  string
+	DBPath      string
+	LogLevel    slog.Level
+	OpeningTime string
+	ClosingTime string
+	SlotMinutes int
+
+	WeeklySchedule string
+
+	MinNotice          time.Duration
+	MinLeadWorkingDays int
+	MaxDaysAhead       int
+	SameDayCutoff      string
+
+	DailyCapacity     int
+	CapacityOverrides string
+	PersonLimit       int
+
+	HalfDayClosingTime string
+	AdminToken         string
+
+	OfficeRegion     string
+	OfficeTimeZone   string
+	HolidayProviders string
+
+	HolidayFailPolicy         string
+	HolidayCacheTTL           time.Duration
+	HolidayRevalidateInterval time.Duration
+	HolidayRefreshInterval    time.Duration
+
+	HolidayRetryAttempts    int
+	HolidayRetryBaseDelay   time.Duration
+	HolidayRetryMaxDelay    time.Duration
+	HolidayBreakerThreshold int
+	HolidayBreakerCooldown  time.Duration
 }
 
 func Load() *Config {
 	return &Config{
-		ServerPort:      getEnv("SERVER_PORT", "9119"),
-		DBPath:          getEnv("DB_PATH", "citynext.db"),
-		LogLevel:        parseLogLevel(getEnv("LOG_LEVEL", "info")),
-		NagerAPIBaseURL: getEnv("NAGER_API_BASE_URL", "https://date.nager.at/api/v3"),
+		ServerPort:  getEnv("SERVER_PORT", "9119"),
+		DBPath:      getEnv("DB_PATH", "citynext.db"),
+		LogLevel:    parseLogLevel(getEnv("LOG_LEVEL", "info")),
+		OpeningTime: getEnv("OPENING_TIME", "09:00"),
+		ClosingTime: getEnv("CLOSING_TIME", "16:30"),
+		SlotMinutes: getEnvInt("SLOT_MINUTES", 15),
+
+		// empty means Monday to Friday, OPENING_TIME to CLOSING_TIME
+		WeeklySchedule: getEnv("WEEKLY_SCHEDULE", ""),
+
+		// zero values leave the booking window open; the horizon defaults to a
+		// quarter so that bookings never look up holidays of arbitrary years
+		MinNotice:          getEnvDuration("MIN_NOTICE", 0),
+		MinLeadWorkingDays: getEnvInt("MIN_LEAD_WORKING_DAYS", 0),
+		MaxDaysAhead:       getEnvInt("MAX_DAYS_AHEAD", 90),
+		SameDayCutoff:      getEnv("SAME_DAY_CUTOFF", ""),
+
+		DailyCapacity:     getEnvInt("DAILY_CAPACITY", 30),
+		CapacityOverrides: getEnv("CAPACITY_OVERRIDES", ""),
+		PersonLimit:       getEnvInt("MAX_APPOINTMENTS_PER_PERSON", 0),
+
+		HalfDayClosingTime: getEnv("HALF_DAY_CLOSING_TIME", "12:00"),
+		// empty disables the staff endpoints that change closures and extra openings
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		OfficeRegion:   getEnv("OFFICE_REGION", "GB-ENG"),
+		OfficeTimeZone: getEnv("OFFICE_TIME_ZONE", "Europe/London"),
+		// NAGER_API_BASE_URL predates HOLIDAY_PROVIDERS and still picks the Nager URL when no providers are set
+		HolidayProviders: getEnv("HOLIDAY_PROVIDERS", "nager="+getEnv("NAGER_API_BASE_URL", "https://date.nager.at/api/v3")),
+
+		HolidayFailPolicy:         getEnv("HOLIDAY_FAIL_POLICY", "closed"),
+		HolidayCacheTTL:           getEnvDuration("HOLIDAY_CACHE_TTL", 7*24*time.Hour),
+		HolidayRevalidateInterval: getEnvDuration("HOLIDAY_REVALIDATE_INTERVAL", 15*time.Minute),
+		HolidayRefreshInterval:    getEnvDuration("HOLIDAY_REFRESH_INTERVAL", 24*time.Hour),
+
+		HolidayRetryAttempts:    getEnvInt("HOLIDAY_RETRY_ATTEMPTS", 3),
+		HolidayRetryBaseDelay:   getEnvDuration("HOLIDAY_RETRY_BASE_DELAY", 250*time.Millisecond),
+		HolidayRetryMaxDelay:    getEnvDuration("HOLIDAY_RETRY_MAX_DELAY", 5*time.Second),
+		HolidayBreakerThreshold: getEnvInt("HOLIDAY_BREAKER_THRESHOLD", 5),
+		HolidayBreakerCooldown:  getEnvDuration("HOLIDAY_BREAKER_COOLDOWN", 30*time.Second),
 	}
 }
 
@@ -29,6 +98,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key))); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key))); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func parseLogLevel(level string) slog.Level {
 	switch strings.ToLower(strings.TrimSpace(level)) {
 	case "debug":
@@ -0,0 +1,136 @@
+package database
+
+import (
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+	"context"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// interface for office closure data operations
+type ClosureRepository interface {
+	Create(ctx context.Context, closure *dbModels.Closure) error
+	GetByID(ctx context.Context, id uint) (*dbModels.Closure, error)
+	ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.Closure, error)
+	Update(ctx context.Context, closure *dbModels.Closure) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// SQLite implementation of the ClosureRepository interface
+type SQLiteClosureRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSQLiteClosureRepository(db *gorm.DB, logger *slog.Logger) *SQLiteClosureRepository {
+	return &SQLiteClosureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// saves a new closure to the database
+func (r *SQLiteClosureRepository) Create(ctx context.Context, closure *dbModels.Closure) error {
+	r.logger.Info("Creating closure",
+		"start_date", closure.StartDate.String(),
+		"end_date", closure.EndDate.String(),
+		"half_day", closure.HalfDay)
+
+	if err := r.db.WithContext(ctx).Create(closure).Error; err != nil {
+		r.logger.Error("Failed to create closure",
+			"error", err,
+			"start_date", closure.StartDate.String(),
+			"end_date", closure.EndDate.String())
+		return err
+	}
+
+	r.logger.Info("Closure created successfully", "id", closure.ID)
+	return nil
+}
+
+// retrieves a closure by its ID
+func (r *SQLiteClosureRepository) GetByID(ctx context.Context, id uint) (*dbModels.Closure, error) {
+	var closure dbModels.Closure
+	err := r.db.WithContext(ctx).First(&closure, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClosureNotFound
+		}
+		r.logger.Error("Failed to get closure by ID",
+			"error", err,
+			"id", id)
+		return nil, err
+	}
+
+	return &closure, nil
+}
+
+// retrieves the closures that cover at least one date from..to (inclusive), ordered by start date;
+// a zero from or to leaves that side of the range open
+func (r *SQLiteClosureRepository) ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.Closure, error) {
+	query := r.db.WithContext(ctx).Model(&dbModels.Closure{})
+	if !from.IsZero() {
+		query = query.Where("DATE(end_date) >= DATE(?)", from.String())
+	}
+	if !to.IsZero() {
+		query = query.Where("DATE(start_date) <= DATE(?)", to.String())
+	}
+
+	var closures []dbModels.Closure
+	err := query.Order("start_date ASC").Order("id ASC").Find(&closures).Error
+	if err != nil {
+		r.logger.Error("Failed to list closures",
+			"error", err,
+			"from", from.String(),
+			"to", to.String())
+		return nil, err
+	}
+
+	return closures, nil
+}
+
+// saves the dates, reason and half-day flag of an existing closure
+func (r *SQLiteClosureRepository) Update(ctx context.Context, closure *dbModels.Closure) error {
+	r.logger.Info("Updating closure",
+		"id", closure.ID,
+		"start_date", closure.StartDate.String(),
+		"end_date", closure.EndDate.String(),
+		"half_day", closure.HalfDay)
+
+	result := r.db.WithContext(ctx).Model(closure).
+		Select("start_date", "end_date", "reason", "half_day").
+		Updates(closure)
+	if result.Error != nil {
+		r.logger.Error("Failed to update closure",
+			"error", result.Error,
+			"id", closure.ID)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrClosureNotFound
+	}
+
+	return nil
+}
+
+// removes a closure, reopening its dates for bookings
+func (r *SQLiteClosureRepository) Delete(ctx context.Context, id uint) error {
+	r.logger.Info("Deleting closure", "id", id)
+
+	result := r.db.WithContext(ctx).Delete(&dbModels.Closure{}, id)
+	if result.Error != nil {
+		r.logger.Error("Failed to delete closure",
+			"error", result.Error,
+			"id", id)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrClosureNotFound
+	}
+
+	r.logger.Info("Closure deleted successfully", "id", id)
+	return nil
+}
@@ -1,31 +1,119 @@
 package database
 
 import (
+	apiModels "citynext/internal/api/models"
 	"citynext/internal/database/models"
 	"log/slog"
+	"strings"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// unique indexes from earlier schemas that would still block bookings
+var legacyAppointmentIndexes = []string{
+	"idx_appointments_visit_date",        // one appointment per date, including cancelled ones
+	"idx_appointments_active_visit_date", // one active appointment per date, before time slots
+}
+
 func NewSQLiteConnection(dbPath string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(dbPath)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&models.Appointment{})
+	err = db.AutoMigrate(&models.Appointment{}, &models.Holiday{}, &models.Closure{}, &models.ExtraOpening{})
 	if err != nil {
 		return nil, err
 	}
 
+	for _, index := range legacyAppointmentIndexes {
+		if !db.Migrator().HasIndex(&models.Appointment{}, index) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(&models.Appointment{}, index); err != nil {
+			return nil, err
+		}
+		slog.Info("Dropped legacy appointment index", "index", index)
+	}
+
 	slog.Info("Database connection established and migrations completed", "db_path", dbPath)
 	return db, nil
 }
 
+// start time the start_time column default gives appointments booked before time slots existed
+var legacyStartTime = apiModels.NewTimeOfDay(0, 0)
+
+// moves appointments booked before time slots existed, which the column default left at 00:00,
+// to the first slot of their date that no other active appointment holds. slotsFor returns
+// the slot start times of a date in order; dates whose slots all start at 00:00 are left alone.
+func MigrateLegacyStartTimes(db *gorm.DB, slotsFor func(date apiModels.Date) []apiModels.TimeOfDay) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var legacy []models.Appointment
+		if err := tx.Where("start_time = ?", legacyStartTime).Order("id").Find(&legacy).Error; err != nil {
+			return err
+		}
+
+		for _, appointment := range legacy {
+			slots := slotsFor(appointment.VisitDate)
+			if len(slots) == 0 || slots[0] == legacyStartTime {
+				continue
+			}
+
+			// cancelled appointments hold no slot, so any slot will do for them
+			taken := map[apiModels.TimeOfDay]bool{}
+			if !appointment.IsCancelled() {
+				var starts []apiModels.TimeOfDay
+				err := tx.Model(&models.Appointment{}).
+					Where("visit_date = ? AND cancelled_at IS NULL AND id <> ?", appointment.VisitDate, appointment.ID).
+					Pluck("start_time", &starts).Error
+				if err != nil {
+					return err
+				}
+				for _, start := range starts {
+					taken[start] = true
+				}
+			}
+
+			slot, found := legacyStartTime, false
+			for _, candidate := range slots {
+				if !taken[candidate] {
+					slot, found = candidate, true
+					break
+				}
+			}
+			if !found {
+				slog.Warn("No free slot for appointment booked before time slots",
+					"id", appointment.ID,
+					"visit_date", appointment.VisitDate.String())
+				continue
+			}
+
+			if err := tx.Model(&models.Appointment{}).Where("id = ?", appointment.ID).Update("start_time", slot).Error; err != nil {
+				return err
+			}
+			slog.Info("Moved appointment booked before time slots to a slot",
+				"id", appointment.ID,
+				"visit_date", appointment.VisitDate.String(),
+				"start_time", slot.String())
+		}
+		return nil
+	})
+}
+
+// opens every transaction with BEGIN IMMEDIATE, so read-then-write sequences such as
+// capacity checks are serialised between connections instead of racing
+func sqliteDSN(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + "_txlock=immediate"
+}
+
 func CloseConnection(db *gorm.DB) error {
 	sqlDB, err := db.DB()
 	if err != nil {
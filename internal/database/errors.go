@@ -1,9 +1,16 @@
 package database
 
-import "errors"
+import "citynext/internal/errcode"
 
 // Custom error types for the database layer
 var (
-	ErrDuplicateAppointment = errors.New("appointment already exists for this date")
-	ErrAppointmentNotFound  = errors.New("appointment not found")
+	ErrDuplicateAppointment = errcode.New("DUPLICATE_BOOKING", "body.startTime", "appointment already exists for this slot")
+	ErrAppointmentNotFound  = errcode.New("APPOINTMENT_NOT_FOUND", "path.id", "appointment not found")
+	ErrAlreadyCancelled     = errcode.New("ALREADY_CANCELLED", "path.id", "appointment has already been cancelled")
+	ErrDateFullyBooked      = errcode.New("DATE_FULLY_BOOKED", "body.visitDate", "no appointments left on this date")
+	ErrClosureNotFound      = errcode.New("CLOSURE_NOT_FOUND", "path.id", "closure not found")
+	ErrExtraOpeningNotFound = errcode.New("EXTRA_OPENING_NOT_FOUND", "path.id", "extra opening not found")
+	ErrExtraOpeningOverlap  = errcode.New("EXTRA_OPENING_OVERLAP", "body.startDate", "dates overlap another extra opening")
+	ErrReferenceTaken       = errcode.New("REFERENCE_TAKEN", "", "booking reference is already in use")
+	ErrPersonLimitReached   = errcode.New("PERSON_LIMIT_REACHED", "", "person already holds the maximum number of upcoming appointments")
 )
@@ -0,0 +1,185 @@
+package database
+
+import (
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+	"context"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// interface for extra opening data operations; Create and Update reject an extra opening
+// whose dates overlap another one with ErrExtraOpeningOverlap, so that every date has at
+// most one set of hours and capacity
+type ExtraOpeningRepository interface {
+	Create(ctx context.Context, opening *dbModels.ExtraOpening) error
+	GetByID(ctx context.Context, id uint) (*dbModels.ExtraOpening, error)
+	ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.ExtraOpening, error)
+	Update(ctx context.Context, opening *dbModels.ExtraOpening) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// SQLite implementation of the ExtraOpeningRepository interface
+type SQLiteExtraOpeningRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSQLiteExtraOpeningRepository(db *gorm.DB, logger *slog.Logger) *SQLiteExtraOpeningRepository {
+	return &SQLiteExtraOpeningRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// saves a new extra opening to the database, checking for overlaps in the same transaction
+func (r *SQLiteExtraOpeningRepository) Create(ctx context.Context, opening *dbModels.ExtraOpening) error {
+	r.logger.Info("Creating extra opening",
+		"start_date", opening.StartDate.String(),
+		"end_date", opening.EndDate.String(),
+		"opens", opening.Opens.String(),
+		"closes", opening.Closes.String(),
+		"capacity", opening.Capacity)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkExtraOpeningOverlap(tx, opening); err != nil {
+			return err
+		}
+		return tx.Create(opening).Error
+	})
+	if errors.Is(err, ErrExtraOpeningOverlap) {
+		r.logger.Warn("Extra opening overlaps another",
+			"start_date", opening.StartDate.String(),
+			"end_date", opening.EndDate.String())
+		return err
+	}
+	if err != nil {
+		r.logger.Error("Failed to create extra opening",
+			"error", err,
+			"start_date", opening.StartDate.String(),
+			"end_date", opening.EndDate.String())
+		return err
+	}
+
+	r.logger.Info("Extra opening created successfully", "id", opening.ID)
+	return nil
+}
+
+// rejects an extra opening whose dates overlap another one, ignoring the opening itself; runs inside
+// the transaction that saves it, which SQLite's immediate transactions serialise
+func checkExtraOpeningOverlap(tx *gorm.DB, opening *dbModels.ExtraOpening) error {
+	var overlapping int64
+	err := tx.Model(&dbModels.ExtraOpening{}).
+		Where("DATE(end_date) >= DATE(?) AND DATE(start_date) <= DATE(?) AND id <> ?",
+			opening.StartDate.String(), opening.EndDate.String(), opening.ID).
+		Count(&overlapping).Error
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrExtraOpeningOverlap
+	}
+	return nil
+}
+
+// retrieves an extra opening by its ID
+func (r *SQLiteExtraOpeningRepository) GetByID(ctx context.Context, id uint) (*dbModels.ExtraOpening, error) {
+	var opening dbModels.ExtraOpening
+	err := r.db.WithContext(ctx).First(&opening, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExtraOpeningNotFound
+		}
+		r.logger.Error("Failed to get extra opening by ID",
+			"error", err,
+			"id", id)
+		return nil, err
+	}
+
+	return &opening, nil
+}
+
+// retrieves the extra openings that cover at least one date from..to (inclusive), ordered by start date;
+// a zero from or to leaves that side of the range open
+func (r *SQLiteExtraOpeningRepository) ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.ExtraOpening, error) {
+	query := r.db.WithContext(ctx).Model(&dbModels.ExtraOpening{})
+	if !from.IsZero() {
+		query = query.Where("DATE(end_date) >= DATE(?)", from.String())
+	}
+	if !to.IsZero() {
+		query = query.Where("DATE(start_date) <= DATE(?)", to.String())
+	}
+
+	var openings []dbModels.ExtraOpening
+	err := query.Order("start_date ASC").Order("id ASC").Find(&openings).Error
+	if err != nil {
+		r.logger.Error("Failed to list extra openings",
+			"error", err,
+			"from", from.String(),
+			"to", to.String())
+		return nil, err
+	}
+
+	return openings, nil
+}
+
+// saves the dates, reason, hours and capacity of an existing extra opening, checking for overlaps
+// in the same transaction
+func (r *SQLiteExtraOpeningRepository) Update(ctx context.Context, opening *dbModels.ExtraOpening) error {
+	r.logger.Info("Updating extra opening",
+		"id", opening.ID,
+		"start_date", opening.StartDate.String(),
+		"end_date", opening.EndDate.String(),
+		"opens", opening.Opens.String(),
+		"closes", opening.Closes.String(),
+		"capacity", opening.Capacity)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkExtraOpeningOverlap(tx, opening); err != nil {
+			return err
+		}
+		result := tx.Model(opening).
+			Select("start_date", "end_date", "reason", "opens", "closes", "capacity").
+			Updates(opening)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrExtraOpeningNotFound
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrExtraOpeningOverlap):
+		r.logger.Warn("Extra opening overlaps another",
+			"id", opening.ID,
+			"start_date", opening.StartDate.String(),
+			"end_date", opening.EndDate.String())
+	case err != nil && !errors.Is(err, ErrExtraOpeningNotFound):
+		r.logger.Error("Failed to update extra opening",
+			"error", err,
+			"id", opening.ID)
+	}
+	return err
+}
+
+// removes an extra opening, so its dates follow the normal rules again
+func (r *SQLiteExtraOpeningRepository) Delete(ctx context.Context, id uint) error {
+	r.logger.Info("Deleting extra opening", "id", id)
+
+	result := r.db.WithContext(ctx).Delete(&dbModels.ExtraOpening{}, id)
+	if result.Error != nil {
+		r.logger.Error("Failed to delete extra opening",
+			"error", result.Error,
+			"id", id)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExtraOpeningNotFound
+	}
+
+	r.logger.Info("Extra opening deleted successfully", "id", id)
+	return nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	dbModels "citynext/internal/database/models"
+	"context"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// interface for holiday data operations
+type HolidayRepository interface {
+	ReplaceYear(ctx context.Context, countryCode string, year int, holidays []dbModels.Holiday) error
+	ListByYear(ctx context.Context, countryCode string, year int) ([]dbModels.Holiday, error)
+	ListAll(ctx context.Context, countryCode string) ([]dbModels.Holiday, error)
+}
+
+// SQLite implementation of the HolidayRepository interface
+type SQLiteHolidayRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSQLiteHolidayRepository(db *gorm.DB, logger *slog.Logger) *SQLiteHolidayRepository {
+	return &SQLiteHolidayRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// replaces every stored holiday of a country and year with a freshly fetched set, in one transaction
+func (r *SQLiteHolidayRepository) ReplaceYear(ctx context.Context, countryCode string, year int, holidays []dbModels.Holiday) error {
+	r.logger.Debug("Replacing stored holidays",
+		"country_code", countryCode,
+		"year", year,
+		"holidays_count", len(holidays))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("country_code = ? AND year = ?", countryCode, year).
+			Delete(&dbModels.Holiday{}).Error
+		if err != nil {
+			return err
+		}
+		if len(holidays) == 0 {
+			return nil
+		}
+		return tx.Create(&holidays).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to store holidays",
+			"error", err,
+			"country_code", countryCode,
+			"year", year)
+		return err
+	}
+
+	return nil
+}
+
+// retrieves the stored holidays of a country for one year, ordered by date
+func (r *SQLiteHolidayRepository) ListByYear(ctx context.Context, countryCode string, year int) ([]dbModels.Holiday, error) {
+	var holidays []dbModels.Holiday
+	err := r.db.WithContext(ctx).
+		Where("country_code = ? AND year = ?", countryCode, year).
+		Order("date").
+		Find(&holidays).Error
+	if err != nil {
+		r.logger.Error("Failed to list stored holidays",
+			"error", err,
+			"country_code", countryCode,
+			"year", year)
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+// retrieves every stored holiday of a country, ordered by date
+func (r *SQLiteHolidayRepository) ListAll(ctx context.Context, countryCode string) ([]dbModels.Holiday, error) {
+	var holidays []dbModels.Holiday
+	err := r.db.WithContext(ctx).
+		Where("country_code = ?", countryCode).
+		Order("date").
+		Find(&holidays).Error
+	if err != nil {
+		r.logger.Error("Failed to list stored holidays",
+			"error", err,
+			"country_code", countryCode)
+		return nil, err
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+)
+
+// implements ClosureRepository interface using in-memory storage for testing
+type MemoryClosureRepository struct {
+	closures map[uint]dbModels.Closure // id -> closure
+	mutex    sync.RWMutex
+	nextID   uint
+	logger   *slog.Logger
+}
+
+func NewMemoryClosureRepository(logger *slog.Logger) *MemoryClosureRepository {
+	return &MemoryClosureRepository{
+		closures: make(map[uint]dbModels.Closure),
+		nextID:   1,
+		logger:   logger,
+	}
+}
+
+func (r *MemoryClosureRepository) Create(ctx context.Context, closure *dbModels.Closure) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.logger.Info("Creating closure in memory",
+		"start_date", closure.StartDate.String(),
+		"end_date", closure.EndDate.String(),
+		"half_day", closure.HalfDay)
+
+	closure.ID = r.nextID
+	closure.CreatedAt = time.Now()
+	closure.UpdatedAt = time.Now()
+	r.closures[closure.ID] = *closure
+	r.nextID++
+
+	return nil
+}
+
+func (r *MemoryClosureRepository) GetByID(ctx context.Context, id uint) (*dbModels.Closure, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	closure, exists := r.closures[id]
+	if !exists {
+		return nil, ErrClosureNotFound
+	}
+	return &closure, nil
+}
+
+func (r *MemoryClosureRepository) ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.Closure, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var closures []dbModels.Closure
+	for _, closure := range r.closures {
+		if !from.IsZero() && closure.EndDate.Before(from.Time) {
+			continue
+		}
+		if !to.IsZero() && closure.StartDate.After(to.Time) {
+			continue
+		}
+		closures = append(closures, closure)
+	}
+	sort.Slice(closures, func(i, j int) bool {
+		if !closures[i].StartDate.Equal(closures[j].StartDate.Time) {
+			return closures[i].StartDate.Before(closures[j].StartDate.Time)
+		}
+		return closures[i].ID < closures[j].ID
+	})
+
+	return closures, nil
+}
+
+func (r *MemoryClosureRepository) Update(ctx context.Context, closure *dbModels.Closure) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.closures[closure.ID]
+	if !exists {
+		return ErrClosureNotFound
+	}
+
+	stored.StartDate = closure.StartDate
+	stored.EndDate = closure.EndDate
+	stored.Reason = closure.Reason
+	stored.HalfDay = closure.HalfDay
+	stored.UpdatedAt = time.Now()
+	r.closures[closure.ID] = stored
+
+	return nil
+}
+
+func (r *MemoryClosureRepository) Delete(ctx context.Context, id uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.closures[id]; !exists {
+		return ErrClosureNotFound
+	}
+	delete(r.closures, id)
+
+	return nil
+}
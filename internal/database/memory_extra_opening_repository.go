@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+)
+
+// implements ExtraOpeningRepository interface using in-memory storage for testing
+type MemoryExtraOpeningRepository struct {
+	openings map[uint]dbModels.ExtraOpening // id -> extra opening
+	mutex    sync.RWMutex
+	nextID   uint
+	logger   *slog.Logger
+}
+
+func NewMemoryExtraOpeningRepository(logger *slog.Logger) *MemoryExtraOpeningRepository {
+	return &MemoryExtraOpeningRepository{
+		openings: make(map[uint]dbModels.ExtraOpening),
+		nextID:   1,
+		logger:   logger,
+	}
+}
+
+func (r *MemoryExtraOpeningRepository) Create(ctx context.Context, opening *dbModels.ExtraOpening) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.logger.Info("Creating extra opening in memory",
+		"start_date", opening.StartDate.String(),
+		"end_date", opening.EndDate.String(),
+		"capacity", opening.Capacity)
+
+	if r.overlaps(opening) {
+		return ErrExtraOpeningOverlap
+	}
+
+	opening.ID = r.nextID
+	opening.CreatedAt = time.Now()
+	opening.UpdatedAt = time.Now()
+	r.openings[opening.ID] = *opening
+	r.nextID++
+
+	return nil
+}
+
+// reports whether another stored opening shares a date with opening; callers hold the lock
+func (r *MemoryExtraOpeningRepository) overlaps(opening *dbModels.ExtraOpening) bool {
+	for id, other := range r.openings {
+		if id != opening.ID && !other.EndDate.Before(opening.StartDate.Time) && !other.StartDate.After(opening.EndDate.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryExtraOpeningRepository) GetByID(ctx context.Context, id uint) (*dbModels.ExtraOpening, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	opening, exists := r.openings[id]
+	if !exists {
+		return nil, ErrExtraOpeningNotFound
+	}
+	return &opening, nil
+}
+
+func (r *MemoryExtraOpeningRepository) ListOverlapping(ctx context.Context, from, to apiModels.Date) ([]dbModels.ExtraOpening, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var openings []dbModels.ExtraOpening
+	for _, opening := range r.openings {
+		if !from.IsZero() && opening.EndDate.Before(from.Time) {
+			continue
+		}
+		if !to.IsZero() && opening.StartDate.After(to.Time) {
+			continue
+		}
+		openings = append(openings, opening)
+	}
+	sort.Slice(openings, func(i, j int) bool {
+		if !openings[i].StartDate.Equal(openings[j].StartDate.Time) {
+			return openings[i].StartDate.Before(openings[j].StartDate.Time)
+		}
+		return openings[i].ID < openings[j].ID
+	})
+
+	return openings, nil
+}
+
+func (r *MemoryExtraOpeningRepository) Update(ctx context.Context, opening *dbModels.ExtraOpening) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.overlaps(opening) {
+		return ErrExtraOpeningOverlap
+	}
+	stored, exists := r.openings[opening.ID]
+	if !exists {
+		return ErrExtraOpeningNotFound
+	}
+
+	stored.StartDate = opening.StartDate
+	stored.EndDate = opening.EndDate
+	stored.Reason = opening.Reason
+	stored.Opens = opening.Opens
+	stored.Closes = opening.Closes
+	stored.Capacity = opening.Capacity
+	stored.UpdatedAt = time.Now()
+	r.openings[opening.ID] = stored
+
+	return nil
+}
+
+func (r *MemoryExtraOpeningRepository) Delete(ctx context.Context, id uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.openings[id]; !exists {
+		return ErrExtraOpeningNotFound
+	}
+	delete(r.openings, id)
+
+	return nil
+}
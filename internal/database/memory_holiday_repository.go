@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	dbModels "citynext/internal/database/models"
+)
+
+// implements HolidayRepository interface using in-memory storage for testing
+type MemoryHolidayRepository struct {
+	holidays map[string][]dbModels.Holiday // "country year" -> holidays
+	mutex    sync.RWMutex
+	nextID   uint
+	logger   *slog.Logger
+}
+
+func NewMemoryHolidayRepository(logger *slog.Logger) *MemoryHolidayRepository {
+	return &MemoryHolidayRepository{
+		holidays: make(map[string][]dbModels.Holiday),
+		nextID:   1,
+		logger:   logger,
+	}
+}
+
+func holidayYearKey(countryCode string, year int) string {
+	return fmt.Sprintf("%s %d", countryCode, year)
+}
+
+func (r *MemoryHolidayRepository) ReplaceYear(ctx context.Context, countryCode string, year int, holidays []dbModels.Holiday) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.logger.Debug("Replacing stored holidays in memory",
+		"country_code", countryCode,
+		"year", year,
+		"holidays_count", len(holidays))
+
+	stored := make([]dbModels.Holiday, len(holidays))
+	for i, holiday := range holidays {
+		holiday.ID = r.nextID
+		holiday.CreatedAt = time.Now()
+		holiday.UpdatedAt = time.Now()
+		r.nextID++
+		stored[i] = holiday
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Date.Before(stored[j].Date.Time) })
+
+	r.holidays[holidayYearKey(countryCode, year)] = stored
+	return nil
+}
+
+func (r *MemoryHolidayRepository) ListByYear(ctx context.Context, countryCode string, year int) ([]dbModels.Holiday, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stored := r.holidays[holidayYearKey(countryCode, year)]
+	return append([]dbModels.Holiday(nil), stored...), nil
+}
+
+func (r *MemoryHolidayRepository) ListAll(ctx context.Context, countryCode string) ([]dbModels.Holiday, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var holidays []dbModels.Holiday
+	for _, stored := range r.holidays {
+		for _, holiday := range stored {
+			if holiday.CountryCode == countryCode {
+				holidays = append(holidays, holiday)
+			}
+		}
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date.Time) })
+
+	return holidays, nil
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"log/slog"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,7 +14,8 @@ import (
 
 // implements AppointmentRepository interface using in-memory storage for testing
 type MemoryAppointmentRepository struct {
-	appointments map[string]*dbModels.Appointment // date string -> appointment
+	appointments map[uint]*dbModels.Appointment // id -> appointment
+	slotIndex    map[string]uint                // slot key -> active appointment id
 	mutex        sync.RWMutex
 	nextID       uint
 	logger       *slog.Logger
@@ -20,72 +23,386 @@ type MemoryAppointmentRepository struct {
 
 func NewMemoryAppointmentRepository(logger *slog.Logger) *MemoryAppointmentRepository {
 	return &MemoryAppointmentRepository{
-		appointments: make(map[string]*dbModels.Appointment),
+		appointments: make(map[uint]*dbModels.Appointment),
+		slotIndex:    make(map[string]uint),
 		nextID:       1,
 		logger:       logger,
 	}
 }
 
+// builds the slotIndex key for a date and start time
+func slotKey(date apiModels.Date, start apiModels.TimeOfDay) string {
+	return date.String() + " " + start.String()
+}
+
 func (r *MemoryAppointmentRepository) Create(ctx context.Context, appointment *dbModels.Appointment) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	dateKey := appointment.VisitDate.String()
+	key := slotKey(appointment.VisitDate, appointment.StartTime)
 
 	r.logger.Info("Creating appointment in memory",
 		"first_name", appointment.FirstName,
 		"last_name", appointment.LastName,
-		"visit_date", dateKey)
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String())
 
 	// Check if appointment already exists
-	if _, exists := r.appointments[dateKey]; exists {
-		r.logger.Warn("Appointment already exists for date",
-			"date", dateKey)
+	if _, exists := r.slotIndex[key]; exists {
+		r.logger.Warn("Appointment already exists for slot",
+			"slot", key)
 		return ErrDuplicateAppointment
 	}
+	if r.referenceTaken(appointment.Reference) {
+		r.logger.Warn("Booking reference already in use", "reference", appointment.Reference)
+		return ErrReferenceTaken
+	}
+
+	appointment.ID = r.nextID
+	appointment.CreatedAt = time.Now()
+	appointment.UpdatedAt = time.Now()
+
+	r.appointments[appointment.ID] = appointment
+	r.slotIndex[key] = appointment.ID
+	r.nextID++
+
+	r.logger.Info("Appointment created successfully in memory",
+		"id", appointment.ID,
+		"slot", key)
+	return nil
+}
+
+func (r *MemoryAppointmentRepository) CreateWithinCapacity(ctx context.Context, appointment *dbModels.Appointment, capacity int, limit PersonLimit) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := slotKey(appointment.VisitDate, appointment.StartTime)
+
+	r.logger.Info("Creating appointment within capacity in memory",
+		"first_name", appointment.FirstName,
+		"last_name", appointment.LastName,
+		"slot", key,
+		"capacity", capacity)
+
+	if err := r.checkSlotAndCapacity(0, appointment.VisitDate, appointment.StartTime, capacity); err != nil {
+		r.logger.Warn("Appointment cannot be created in memory", "error", err, "slot", key)
+		return err
+	}
+	if err := r.checkPersonLimit(appointment, limit); err != nil {
+		r.logger.Warn("Appointment cannot be created in memory", "error", err, "slot", key)
+		return err
+	}
+	if r.referenceTaken(appointment.Reference) {
+		r.logger.Warn("Booking reference already in use", "reference", appointment.Reference)
+		return ErrReferenceTaken
+	}
 
 	appointment.ID = r.nextID
 	appointment.CreatedAt = time.Now()
 	appointment.UpdatedAt = time.Now()
 
-	r.appointments[dateKey] = appointment
+	r.appointments[appointment.ID] = appointment
+	r.slotIndex[key] = appointment.ID
 	r.nextID++
 
 	r.logger.Info("Appointment created successfully in memory",
 		"id", appointment.ID,
-		"date", dateKey)
+		"slot", key)
+	return nil
+}
+
+// mirrors the partial unique index on reference, callers must hold the lock
+func (r *MemoryAppointmentRepository) referenceTaken(reference string) bool {
+	if reference == "" {
+		return false
+	}
+	for _, appointment := range r.appointments {
+		if appointment.Reference == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// same rules as the SQLite checkSlotAndCapacity, callers must hold the write lock
+func (r *MemoryAppointmentRepository) checkSlotAndCapacity(excludeID uint, date apiModels.Date, start apiModels.TimeOfDay, capacity int) error {
+	if id, taken := r.slotIndex[slotKey(date, start)]; taken && id != excludeID {
+		return ErrDuplicateAppointment
+	}
+
+	dayCount := 0
+	for id, appointment := range r.appointments {
+		if id != excludeID && !appointment.IsCancelled() && appointment.VisitDate.Equal(date.Time) {
+			dayCount++
+		}
+	}
+	if dayCount >= capacity {
+		return ErrDateFullyBooked
+	}
+
 	return nil
 }
 
-func (r *MemoryAppointmentRepository) GetByDate(ctx context.Context, date apiModels.Date) (*dbModels.Appointment, error) {
+// same rule as the SQLite checkPersonLimit, callers must hold the write lock
+func (r *MemoryAppointmentRepository) checkPersonLimit(appointment *dbModels.Appointment, limit PersonLimit) error {
+	if limit.Max <= 0 {
+		return nil
+	}
+
+	held := 0
+	for _, other := range r.appointments {
+		if !other.IsCancelled() && !other.VisitDate.Before(limit.From.Time) &&
+			strings.EqualFold(other.FirstName, appointment.FirstName) && strings.EqualFold(other.LastName, appointment.LastName) {
+			held++
+		}
+	}
+	if held >= limit.Max {
+		return ErrPersonLimitReached
+	}
+	return nil
+}
+
+func (r *MemoryAppointmentRepository) GetByID(ctx context.Context, id uint) (*dbModels.Appointment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.logger.Debug("Getting appointment by ID from memory", "id", id)
+
+	appointment, exists := r.appointments[id]
+	if !exists {
+		r.logger.Debug("No appointment found for ID in memory", "id", id)
+		return nil, ErrAppointmentNotFound
+	}
+
+	r.logger.Debug("Appointment found in memory", "id", appointment.ID)
+	return appointment, nil
+}
+
+func (r *MemoryAppointmentRepository) GetByReference(ctx context.Context, reference string) (*dbModels.Appointment, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	dateKey := date.String()
+	r.logger.Debug("Getting appointment by reference from memory", "reference", reference)
 
-	r.logger.Debug("Getting appointment by date from memory", "date", dateKey)
+	if reference != "" {
+		for _, appointment := range r.appointments {
+			if appointment.Reference == reference {
+				r.logger.Debug("Appointment found in memory", "id", appointment.ID)
+				return appointment, nil
+			}
+		}
+	}
+
+	r.logger.Debug("No appointment found for reference in memory", "reference", reference)
+	return nil, ErrAppointmentNotFound
+}
 
-	appointment, exists := r.appointments[dateKey]
+func (r *MemoryAppointmentRepository) GetBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (*dbModels.Appointment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key := slotKey(date, start)
+
+	r.logger.Debug("Getting appointment by slot from memory", "slot", key)
+
+	id, exists := r.slotIndex[key]
 	if !exists {
-		r.logger.Debug("No appointment found for date in memory", "date", dateKey)
+		r.logger.Debug("No appointment found for slot in memory", "slot", key)
 		return nil, ErrAppointmentNotFound
 	}
 
-	r.logger.Debug("Appointment found in memory", "id", appointment.ID, "date", dateKey)
+	appointment := r.appointments[id]
+	r.logger.Debug("Appointment found in memory", "id", appointment.ID, "slot", key)
 	return appointment, nil
 }
 
-func (r *MemoryAppointmentRepository) ExistsByDate(ctx context.Context, date apiModels.Date) (bool, error) {
+func (r *MemoryAppointmentRepository) ExistsBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (bool, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	dateKey := date.String()
+	key := slotKey(date, start)
 
-	r.logger.Debug("Checking if appointment exists for date in memory", "date", dateKey)
+	r.logger.Debug("Checking if appointment exists for slot in memory", "slot", key)
 
-	_, exists := r.appointments[dateKey]
+	_, exists := r.slotIndex[key]
 	r.logger.Debug("Appointment existence check result in memory",
-		"date", dateKey,
+		"slot", key,
 		"exists", exists)
 	return exists, nil
 }
+
+func (r *MemoryAppointmentRepository) List(ctx context.Context, filter AppointmentFilter) ([]dbModels.Appointment, int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.logger.Debug("Listing appointments from memory",
+		"from", filter.From.String(),
+		"to", filter.To.String(),
+		"last_name", filter.LastName,
+		"limit", filter.Limit,
+		"offset", filter.Offset)
+
+	var matches []dbModels.Appointment
+	for _, appointment := range r.appointments {
+		if !filter.From.IsZero() && appointment.VisitDate.Before(filter.From.Time) {
+			continue
+		}
+		if !filter.To.IsZero() && appointment.VisitDate.After(filter.To.Time) {
+			continue
+		}
+		if filter.LastName != "" && !strings.EqualFold(appointment.LastName, filter.LastName) {
+			continue
+		}
+		if !filter.IncludeCancelled && appointment.IsCancelled() {
+			continue
+		}
+		matches = append(matches, *appointment)
+	}
+
+	// same ordering as the SQLite repository: visit date, start time, then ID
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].VisitDate.Equal(matches[j].VisitDate.Time) {
+			return matches[i].VisitDate.Before(matches[j].VisitDate.Time)
+		}
+		if matches[i].StartTime != matches[j].StartTime {
+			return matches[i].StartTime.Before(matches[j].StartTime)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	total := int64(len(matches))
+	start := min(filter.Offset, len(matches))
+	end := len(matches)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(matches))
+	}
+
+	r.logger.Debug("Appointments listed from memory", "count", end-start, "total", total)
+	return matches[start:end], total, nil
+}
+
+func (r *MemoryAppointmentRepository) CountByDateRange(ctx context.Context, from, to apiModels.Date) (map[string]int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.logger.Debug("Counting appointments by date in memory", "from", from.String(), "to", to.String())
+
+	counts := make(map[string]int)
+	for _, appointment := range r.appointments {
+		if appointment.IsCancelled() || appointment.VisitDate.Before(from.Time) || appointment.VisitDate.After(to.Time) {
+			continue
+		}
+		counts[appointment.VisitDate.String()]++
+	}
+
+	return counts, nil
+}
+
+func (r *MemoryAppointmentRepository) Cancel(ctx context.Context, id uint, cancelledBy, reason string, cancelledAt time.Time) (*dbModels.Appointment, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.logger.Info("Cancelling appointment in memory",
+		"id", id,
+		"cancelled_by", cancelledBy)
+
+	appointment, exists := r.appointments[id]
+	if !exists {
+		r.logger.Debug("No appointment found for ID in memory", "id", id)
+		return nil, ErrAppointmentNotFound
+	}
+
+	if appointment.IsCancelled() {
+		r.logger.Warn("Appointment already cancelled in memory", "id", id)
+		return nil, ErrAlreadyCancelled
+	}
+
+	appointment.CancelledAt = &cancelledAt
+	appointment.CancelledBy = cancelledBy
+	appointment.CancellationReason = reason
+	appointment.UpdatedAt = time.Now()
+
+	// free the slot for new bookings
+	delete(r.slotIndex, slotKey(appointment.VisitDate, appointment.StartTime))
+
+	r.logger.Info("Appointment cancelled successfully in memory", "id", id)
+	return appointment, nil
+}
+
+func (r *MemoryAppointmentRepository) Reschedule(ctx context.Context, id uint, newDate apiModels.Date, newStart apiModels.TimeOfDay, capacity int, holidayUnverified bool) (*dbModels.Appointment, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	newKey := slotKey(newDate, newStart)
+
+	r.logger.Info("Rescheduling appointment in memory",
+		"id", id,
+		"new_slot", newKey)
+
+	appointment, exists := r.appointments[id]
+	if !exists {
+		r.logger.Debug("No appointment found for ID in memory", "id", id)
+		return nil, ErrAppointmentNotFound
+	}
+
+	if appointment.IsCancelled() {
+		r.logger.Warn("Cannot reschedule cancelled appointment in memory", "id", id)
+		return nil, ErrAlreadyCancelled
+	}
+
+	if err := r.checkSlotAndCapacity(id, newDate, newStart, capacity); err != nil {
+		r.logger.Warn("Appointment cannot be rescheduled in memory", "error", err, "slot", newKey)
+		return nil, err
+	}
+
+	delete(r.slotIndex, slotKey(appointment.VisitDate, appointment.StartTime))
+	appointment.VisitDate = newDate
+	appointment.StartTime = newStart
+	appointment.HolidayUnverified = holidayUnverified
+	appointment.HolidayConflict = false
+	appointment.UpdatedAt = time.Now()
+	r.slotIndex[newKey] = id
+
+	r.logger.Info("Appointment rescheduled successfully in memory",
+		"id", id,
+		"slot", newKey)
+	return appointment, nil
+}
+
+// returns the active appointments booked while holidays could not be checked, ordered by visit date
+func (r *MemoryAppointmentRepository) ListHolidayUnverified(ctx context.Context) ([]dbModels.Appointment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matches []dbModels.Appointment
+	for _, appointment := range r.appointments {
+		if appointment.HolidayUnverified && !appointment.IsCancelled() {
+			matches = append(matches, *appointment)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].VisitDate.Equal(matches[j].VisitDate.Time) {
+			return matches[i].VisitDate.Before(matches[j].VisitDate.Time)
+		}
+		return matches[i].StartTime.Before(matches[j].StartTime)
+	})
+
+	return matches, nil
+}
+
+// clears the holiday_unverified flag once the visit date has been checked, recording whether it is a holiday
+func (r *MemoryAppointmentRepository) MarkHolidayVerified(ctx context.Context, id uint, onHoliday bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	appointment, exists := r.appointments[id]
+	if !exists {
+		return ErrAppointmentNotFound
+	}
+
+	appointment.HolidayUnverified = false
+	appointment.HolidayConflict = onHoliday
+	appointment.UpdatedAt = time.Now()
+	return nil
+}
@@ -9,16 +9,32 @@ import (
 
 // represents an appointment in the database
 type Appointment struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	FirstName string         `gorm:"not null" json:"firstName"`
-	LastName  string         `gorm:"not null" json:"lastName"`
-	VisitDate models.Date    `gorm:"not null;uniqueIndex;type:date" json:"visitDate"`
-	CreatedAt time.Time      `json:"createdAt"`
-	UpdatedAt time.Time      `json:"updatedAt"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                  uint             `gorm:"primarykey" json:"id"`
+	Reference           string           `gorm:"not null;type:varchar(12);default:'';uniqueIndex:idx_appointments_reference,where:reference <> ''" json:"reference"`
+	ManagementTokenHash string           `gorm:"not null;type:varchar(64);default:''" json:"-"`
+	FirstName           string           `gorm:"not null" json:"firstName"`
+	LastName            string           `gorm:"not null" json:"lastName"`
+	VisitDate           models.Date      `gorm:"not null;type:date;uniqueIndex:idx_appointments_active_slot,where:cancelled_at IS NULL AND deleted_at IS NULL" json:"visitDate"`
+	StartTime           models.TimeOfDay `gorm:"not null;type:varchar(5);default:'00:00';uniqueIndex:idx_appointments_active_slot" json:"startTime"`
+	CancelledAt         *time.Time       `gorm:"index" json:"cancelledAt,omitempty"`
+	CancelledBy         string           `json:"cancelledBy,omitempty"`
+	CancellationReason  string           `json:"cancellationReason,omitempty"`
+	HolidayUnverified   bool             `gorm:"not null;default:false;index" json:"holidayUnverified"`
+	HolidayConflict     bool             `gorm:"not null;default:false" json:"holidayConflict"` // unverified date that turned out to be a holiday
+	CreatedAt           time.Time        `json:"createdAt"`
+	UpdatedAt           time.Time        `json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt   `gorm:"index" json:"-"`
+
+	// plaintext management token, only set on the appointment returned at creation
+	ManagementToken string `gorm:"-" json:"-"`
 }
 
 // specifies the table name for the Appointment model
 func (Appointment) TableName() string {
 	return "appointments"
 }
+
+// reports whether the appointment has been cancelled and no longer holds its date
+func (a *Appointment) IsCancelled() bool {
+	return a.CancelledAt != nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"citynext/internal/api/models"
+	"time"
+)
+
+// represents a period in which the office is closed for reasons other than public holidays,
+// e.g. staff training, elections or refurbishment
+type Closure struct {
+	ID        uint        `gorm:"primarykey" json:"id"`
+	StartDate models.Date `gorm:"not null;type:date;index:idx_closures_dates" json:"startDate"`
+	EndDate   models.Date `gorm:"not null;type:date;index:idx_closures_dates" json:"endDate"` // inclusive
+	Reason    string      `gorm:"not null" json:"reason"`
+	HalfDay   bool        `gorm:"not null;default:false" json:"halfDay"` // closed from midday only
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// specifies the table name for the Closure model
+func (Closure) TableName() string {
+	return "closures"
+}
+
+// reports whether the closure covers the given date
+func (c *Closure) Covers(date models.Date) bool {
+	return !date.Before(c.StartDate.Time) && !date.After(c.EndDate.Time)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"citynext/internal/api/models"
+	"time"
+)
+
+// represents days on which the office opens although it would normally be closed, e.g. a Saturday
+// surgery or a bank holiday used to clear a backlog; it has its own hours and capacity
+type ExtraOpening struct {
+	ID        uint             `gorm:"primarykey" json:"id"`
+	StartDate models.Date      `gorm:"not null;type:date;index:idx_extra_openings_dates" json:"startDate"`
+	EndDate   models.Date      `gorm:"not null;type:date;index:idx_extra_openings_dates" json:"endDate"` // inclusive
+	Reason    string           `gorm:"not null" json:"reason"`
+	Opens     models.TimeOfDay `gorm:"not null;type:varchar(5)" json:"opens"`
+	Closes    models.TimeOfDay `gorm:"not null;type:varchar(5)" json:"closes"`
+	Capacity  int              `gorm:"not null" json:"capacity"` // maximum appointments per day
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// specifies the table name for the ExtraOpening model
+func (ExtraOpening) TableName() string {
+	return "extra_openings"
+}
+
+// reports whether the extra opening covers the given date
+func (o *ExtraOpening) Covers(date models.Date) bool {
+	return !date.Before(o.StartDate.Time) && !date.After(o.EndDate.Time)
+}
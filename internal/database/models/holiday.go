@@ -0,0 +1,28 @@
+package models
+
+import (
+	"citynext/internal/api/models"
+	"time"
+)
+
+// represents a public holiday saved from an upstream holiday source
+type Holiday struct {
+	ID          uint        `gorm:"primarykey" json:"id"`
+	CountryCode string      `gorm:"not null;type:varchar(2);uniqueIndex:idx_holidays_country_date;index:idx_holidays_country_year" json:"countryCode"`
+	Year        int         `gorm:"not null;index:idx_holidays_country_year" json:"year"`
+	Date        models.Date `gorm:"not null;type:date;uniqueIndex:idx_holidays_country_date" json:"date"`
+	LocalName   string      `json:"localName"`
+	Name        string      `gorm:"not null" json:"name"`
+	Global      bool        `gorm:"not null" json:"global"`
+	Counties    string      `json:"counties,omitempty"` // comma-separated subdivision codes, e.g. GB-SCT
+	Types       string      `json:"types,omitempty"`    // comma-separated, e.g. Public,Bank
+	Source      string      `gorm:"not null" json:"source"`
+	FetchedAt   time.Time   `gorm:"not null" json:"fetchedAt"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// specifies the table name for the Holiday model
+func (Holiday) TableName() string {
+	return "holidays"
+}
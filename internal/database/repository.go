@@ -4,7 +4,9 @@ import (
 	apiModels "citynext/internal/api/models"
 	dbModels "citynext/internal/database/models"
 	"context"
+	"errors"
 	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -12,8 +14,35 @@ import (
 // interface for appointment data operations
 type AppointmentRepository interface {
 	Create(ctx context.Context, appointment *dbModels.Appointment) error
-	GetByDate(ctx context.Context, date apiModels.Date) (*dbModels.Appointment, error)
-	ExistsByDate(ctx context.Context, date apiModels.Date) (bool, error)
+	CreateWithinCapacity(ctx context.Context, appointment *dbModels.Appointment, capacity int, limit PersonLimit) error
+	GetByID(ctx context.Context, id uint) (*dbModels.Appointment, error)
+	GetByReference(ctx context.Context, reference string) (*dbModels.Appointment, error)
+	GetBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (*dbModels.Appointment, error)
+	ExistsBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (bool, error)
+	List(ctx context.Context, filter AppointmentFilter) ([]dbModels.Appointment, int64, error)
+	CountByDateRange(ctx context.Context, from, to apiModels.Date) (map[string]int, error)
+	Cancel(ctx context.Context, id uint, cancelledBy, reason string, cancelledAt time.Time) (*dbModels.Appointment, error)
+	Reschedule(ctx context.Context, id uint, newDate apiModels.Date, newStart apiModels.TimeOfDay, capacity int, holidayUnverified bool) (*dbModels.Appointment, error)
+	ListHolidayUnverified(ctx context.Context) ([]dbModels.Appointment, error)
+	MarkHolidayVerified(ctx context.Context, id uint, onHoliday bool) error
+}
+
+// criteria for listing appointments, zero values mean "no restriction"
+type AppointmentFilter struct {
+	From     apiModels.Date
+	To       apiModels.Date
+	LastName string
+	Limit    int
+	Offset   int
+
+	IncludeCancelled bool
+}
+
+// caps the upcoming active appointments one person may hold; a person is matched by first and
+// last name, ignoring case, and a zero Max means no limit
+type PersonLimit struct {
+	Max  int
+	From apiModels.Date // first visit date that counts as upcoming, today at the office
 }
 
 // SQLite implementation of the AppointmentRepository interface
@@ -34,15 +63,68 @@ func (r *SQLiteAppointmentRepository) Create(ctx context.Context, appointment *d
 	r.logger.Info("Creating appointment",
 		"first_name", appointment.FirstName,
 		"last_name", appointment.LastName,
-		"visit_date", appointment.VisitDate.String())
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String())
 
-	err := r.db.WithContext(ctx).Create(appointment).Error
+	err := appointmentInsertError(r.db.WithContext(ctx).Create(appointment).Error)
+	if errors.Is(err, ErrDuplicateAppointment) || errors.Is(err, ErrReferenceTaken) {
+		r.logger.Warn("Appointment cannot be created",
+			"error", err,
+			"visit_date", appointment.VisitDate.String(),
+			"start_time", appointment.StartTime.String())
+		return err
+	}
 	if err != nil {
 		r.logger.Error("Failed to create appointment",
 			"error", err,
 			"first_name", appointment.FirstName,
 			"last_name", appointment.LastName,
-			"visit_date", appointment.VisitDate.String())
+			"visit_date", appointment.VisitDate.String(),
+			"start_time", appointment.StartTime.String())
+		return err
+	}
+
+	r.logger.Info("Appointment created successfully", "id", appointment.ID)
+	return nil
+}
+
+// saves a new appointment if its slot is free, its date has fewer than capacity active appointments and its
+// person holds fewer than limit.Max upcoming ones; the checks and the insert share one immediate transaction,
+// so concurrent bookings can neither overfill a date nor take a person past their limit
+func (r *SQLiteAppointmentRepository) CreateWithinCapacity(ctx context.Context, appointment *dbModels.Appointment, capacity int, limit PersonLimit) error {
+	r.logger.Info("Creating appointment within capacity",
+		"first_name", appointment.FirstName,
+		"last_name", appointment.LastName,
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String(),
+		"capacity", capacity)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkSlotAndCapacity(tx, 0, appointment.VisitDate, appointment.StartTime, capacity); err != nil {
+			return err
+		}
+		if err := checkPersonLimit(tx, appointment, limit); err != nil {
+			return err
+		}
+		// the active slot index is the last line of defence if the checks above are ever bypassed
+		return appointmentInsertError(tx.Create(appointment).Error)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDuplicateAppointment), errors.Is(err, ErrDateFullyBooked), errors.Is(err, ErrReferenceTaken),
+			errors.Is(err, ErrPersonLimitReached):
+			r.logger.Warn("Appointment cannot be created",
+				"error", err,
+				"visit_date", appointment.VisitDate.String(),
+				"start_time", appointment.StartTime.String())
+		default:
+			r.logger.Error("Failed to create appointment",
+				"error", err,
+				"first_name", appointment.FirstName,
+				"last_name", appointment.LastName,
+				"visit_date", appointment.VisitDate.String(),
+				"start_time", appointment.StartTime.String())
+		}
 		return err
 	}
 
@@ -50,43 +132,351 @@ func (r *SQLiteAppointmentRepository) Create(ctx context.Context, appointment *d
 	return nil
 }
 
-// retrieves an appointment by date
-func (r *SQLiteAppointmentRepository) GetByDate(ctx context.Context, date apiModels.Date) (*dbModels.Appointment, error) {
-	r.logger.Debug("Getting appointment by date", "date", date.String())
+// returns ErrDuplicateAppointment if the slot is taken or ErrDateFullyBooked if the date has no capacity left,
+// ignoring the appointment with excludeID so a reschedule does not count against itself
+func checkSlotAndCapacity(tx *gorm.DB, excludeID uint, date apiModels.Date, start apiModels.TimeOfDay, capacity int) error {
+	var slotCount int64
+	err := tx.Model(&dbModels.Appointment{}).
+		Where("DATE(visit_date) = DATE(?) AND start_time = ? AND cancelled_at IS NULL AND id <> ?", date.String(), start.String(), excludeID).
+		Count(&slotCount).Error
+	if err != nil {
+		return err
+	}
+	if slotCount > 0 {
+		return ErrDuplicateAppointment
+	}
+
+	var dayCount int64
+	err = tx.Model(&dbModels.Appointment{}).
+		Where("DATE(visit_date) = DATE(?) AND cancelled_at IS NULL AND id <> ?", date.String(), excludeID).
+		Count(&dayCount).Error
+	if err != nil {
+		return err
+	}
+	if dayCount >= int64(capacity) {
+		return ErrDateFullyBooked
+	}
+
+	return nil
+}
+
+// returns ErrPersonLimitReached if the appointment's person already holds limit.Max active appointments from limit.From on
+func checkPersonLimit(tx *gorm.DB, appointment *dbModels.Appointment, limit PersonLimit) error {
+	if limit.Max <= 0 {
+		return nil
+	}
+
+	var held int64
+	err := tx.Model(&dbModels.Appointment{}).
+		Where("DATE(visit_date) >= DATE(?) AND cancelled_at IS NULL", limit.From.String()).
+		Where("LOWER(first_name) = LOWER(?) AND LOWER(last_name) = LOWER(?)", appointment.FirstName, appointment.LastName).
+		Count(&held).Error
+	if err != nil {
+		return err
+	}
+	if held >= int64(limit.Max) {
+		return ErrPersonLimitReached
+	}
+	return nil
+}
+
+// retrieves an appointment by its ID
+func (r *SQLiteAppointmentRepository) GetByID(ctx context.Context, id uint) (*dbModels.Appointment, error) {
+	r.logger.Debug("Getting appointment by ID", "id", id)
+
+	var appointment dbModels.Appointment
+	err := r.db.WithContext(ctx).First(&appointment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Debug("No appointment found for ID", "id", id)
+			return nil, ErrAppointmentNotFound
+		}
+		r.logger.Error("Failed to get appointment by ID",
+			"error", err,
+			"id", id)
+		return nil, err
+	}
+
+	r.logger.Debug("Appointment found", "id", appointment.ID)
+	return &appointment, nil
+}
+
+// retrieves an appointment by its booking reference
+func (r *SQLiteAppointmentRepository) GetByReference(ctx context.Context, reference string) (*dbModels.Appointment, error) {
+	r.logger.Debug("Getting appointment by reference", "reference", reference)
+
+	var appointment dbModels.Appointment
+	err := r.db.WithContext(ctx).Where("reference = ? AND reference <> ''", reference).First(&appointment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Debug("No appointment found for reference", "reference", reference)
+			return nil, ErrAppointmentNotFound
+		}
+		r.logger.Error("Failed to get appointment by reference",
+			"error", err,
+			"reference", reference)
+		return nil, err
+	}
+
+	r.logger.Debug("Appointment found", "id", appointment.ID)
+	return &appointment, nil
+}
+
+// retrieves the active appointment for a slot
+func (r *SQLiteAppointmentRepository) GetBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (*dbModels.Appointment, error) {
+	r.logger.Debug("Getting appointment by slot", "date", date.String(), "start_time", start.String())
 
 	var appointment dbModels.Appointment
-	err := r.db.WithContext(ctx).Where("DATE(visit_date) = DATE(?)", date.String()).First(&appointment).Error
+	err := r.db.WithContext(ctx).
+		Where("DATE(visit_date) = DATE(?) AND start_time = ? AND cancelled_at IS NULL", date.String(), start.String()).
+		First(&appointment).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			r.logger.Debug("No appointment found for date", "date", date.String())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Debug("No appointment found for slot", "date", date.String(), "start_time", start.String())
 			return nil, ErrAppointmentNotFound
 		}
-		r.logger.Error("Failed to get appointment by date",
+		r.logger.Error("Failed to get appointment by slot",
 			"error", err,
-			"date", date.String())
+			"date", date.String(),
+			"start_time", start.String())
 		return nil, err
 	}
 
-	r.logger.Debug("Appointment found", "id", appointment.ID, "date", date.String())
+	r.logger.Debug("Appointment found", "id", appointment.ID, "date", date.String(), "start_time", start.String())
 	return &appointment, nil
 }
 
-// checks if an appointment exists for a given date
-func (r *SQLiteAppointmentRepository) ExistsByDate(ctx context.Context, date apiModels.Date) (bool, error) {
-	r.logger.Debug("Checking if appointment exists for date", "date", date.String())
+// checks if an active appointment exists for a given slot
+func (r *SQLiteAppointmentRepository) ExistsBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (bool, error) {
+	r.logger.Debug("Checking if appointment exists for slot", "date", date.String(), "start_time", start.String())
 
 	var count int64
-	err := r.db.WithContext(ctx).Model(&dbModels.Appointment{}).Where("DATE(visit_date) = DATE(?)", date.String()).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&dbModels.Appointment{}).
+		Where("DATE(visit_date) = DATE(?) AND start_time = ? AND cancelled_at IS NULL", date.String(), start.String()).
+		Count(&count).Error
 	if err != nil {
 		r.logger.Error("Failed to check appointment existence",
 			"error", err,
-			"date", date.String())
+			"date", date.String(),
+			"start_time", start.String())
 		return false, err
 	}
 
 	exists := count > 0
 	r.logger.Debug("Appointment existence check result",
 		"date", date.String(),
+		"start_time", start.String(),
 		"exists", exists)
 	return exists, nil
 }
+
+// returns one page of appointments matching the filter, ordered by visit date, plus the total match count
+func (r *SQLiteAppointmentRepository) List(ctx context.Context, filter AppointmentFilter) ([]dbModels.Appointment, int64, error) {
+	r.logger.Debug("Listing appointments",
+		"from", filter.From.String(),
+		"to", filter.To.String(),
+		"last_name", filter.LastName,
+		"limit", filter.Limit,
+		"offset", filter.Offset)
+
+	query := r.db.WithContext(ctx).Model(&dbModels.Appointment{})
+	if !filter.From.IsZero() {
+		query = query.Where("DATE(visit_date) >= DATE(?)", filter.From.String())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("DATE(visit_date) <= DATE(?)", filter.To.String())
+	}
+	if filter.LastName != "" {
+		query = query.Where("LOWER(last_name) = LOWER(?)", filter.LastName)
+	}
+	if !filter.IncludeCancelled {
+		query = query.Where("cancelled_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		r.logger.Error("Failed to count appointments", "error", err)
+		return nil, 0, err
+	}
+
+	page := query.Session(&gorm.Session{}).Order("visit_date ASC").Order("start_time ASC").Order("id ASC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		page = page.Limit(filter.Limit)
+	}
+
+	var appointments []dbModels.Appointment
+	err := page.Find(&appointments).Error
+	if err != nil {
+		r.logger.Error("Failed to list appointments", "error", err)
+		return nil, 0, err
+	}
+
+	r.logger.Debug("Appointments listed", "count", len(appointments), "total", total)
+	return appointments, total, nil
+}
+
+// counts active appointments per date from..to (inclusive) in a single query, dates without appointments are omitted
+func (r *SQLiteAppointmentRepository) CountByDateRange(ctx context.Context, from, to apiModels.Date) (map[string]int, error) {
+	r.logger.Debug("Counting appointments by date", "from", from.String(), "to", to.String())
+
+	var rows []struct {
+		Day   string
+		Total int
+	}
+	err := r.db.WithContext(ctx).Model(&dbModels.Appointment{}).
+		Select("DATE(visit_date) AS day, COUNT(*) AS total").
+		Where("DATE(visit_date) BETWEEN DATE(?) AND DATE(?) AND cancelled_at IS NULL", from.String(), to.String()).
+		Group("DATE(visit_date)").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Failed to count appointments by date",
+			"error", err,
+			"from", from.String(),
+			"to", to.String())
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.Total
+	}
+
+	r.logger.Debug("Appointments counted by date", "dates", len(counts))
+	return counts, nil
+}
+
+// marks an active appointment as cancelled at cancelledAt, which releases its date for new bookings
+func (r *SQLiteAppointmentRepository) Cancel(ctx context.Context, id uint, cancelledBy, reason string, cancelledAt time.Time) (*dbModels.Appointment, error) {
+	r.logger.Info("Cancelling appointment",
+		"id", id,
+		"cancelled_by", cancelledBy)
+
+	result := r.db.WithContext(ctx).Model(&dbModels.Appointment{}).
+		Where("id = ? AND cancelled_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"cancelled_at":        cancelledAt,
+			"cancelled_by":        cancelledBy,
+			"cancellation_reason": reason,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to cancel appointment",
+			"error", result.Error,
+			"id", id)
+		return nil, result.Error
+	}
+
+	appointment, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing was updated, so the appointment was cancelled before
+	if result.RowsAffected == 0 {
+		r.logger.Warn("Appointment already cancelled", "id", id)
+		return nil, ErrAlreadyCancelled
+	}
+
+	r.logger.Info("Appointment cancelled successfully", "id", id)
+	return appointment, nil
+}
+
+// moves an active appointment to a new slot in a single transaction, the old slot is kept if the new one is taken
+// or the new date has no capacity left
+func (r *SQLiteAppointmentRepository) Reschedule(ctx context.Context, id uint, newDate apiModels.Date, newStart apiModels.TimeOfDay, capacity int, holidayUnverified bool) (*dbModels.Appointment, error) {
+	r.logger.Info("Rescheduling appointment",
+		"id", id,
+		"new_visit_date", newDate.String(),
+		"new_start_time", newStart.String())
+
+	var appointment dbModels.Appointment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&appointment, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAppointmentNotFound
+			}
+			return err
+		}
+
+		if appointment.IsCancelled() {
+			return ErrAlreadyCancelled
+		}
+
+		if err := checkSlotAndCapacity(tx, id, newDate, newStart, capacity); err != nil {
+			return err
+		}
+
+		appointment.VisitDate = newDate
+		appointment.StartTime = newStart
+		appointment.HolidayUnverified = holidayUnverified
+		appointment.HolidayConflict = false // the new date passed the holiday check or is re-checked later
+		err := tx.Model(&appointment).Updates(map[string]interface{}{
+			"visit_date":         newDate,
+			"start_time":         newStart,
+			"holiday_unverified": holidayUnverified,
+			"holiday_conflict":   false,
+		}).Error
+		if isUniqueViolation(err) {
+			return ErrDuplicateAppointment
+		}
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAppointmentNotFound), errors.Is(err, ErrAlreadyCancelled), errors.Is(err, ErrDuplicateAppointment), errors.Is(err, ErrDateFullyBooked):
+			r.logger.Warn("Appointment cannot be rescheduled",
+				"error", err,
+				"id", id,
+				"new_visit_date", newDate.String(),
+				"new_start_time", newStart.String())
+		default:
+			r.logger.Error("Failed to reschedule appointment",
+				"error", err,
+				"id", id,
+				"new_visit_date", newDate.String(),
+				"new_start_time", newStart.String())
+		}
+		return nil, err
+	}
+
+	r.logger.Info("Appointment rescheduled successfully",
+		"id", id,
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String())
+	return &appointment, nil
+}
+
+// returns the active appointments booked while holidays could not be checked, ordered by visit date
+func (r *SQLiteAppointmentRepository) ListHolidayUnverified(ctx context.Context) ([]dbModels.Appointment, error) {
+	var appointments []dbModels.Appointment
+	err := r.db.WithContext(ctx).
+		Where("holiday_unverified = ? AND cancelled_at IS NULL", true).
+		Order("visit_date, start_time").
+		Find(&appointments).Error
+	if err != nil {
+		r.logger.Error("Failed to list holiday-unverified appointments", "error", err)
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+// clears the holiday_unverified flag once the visit date has been checked, recording whether it is a holiday
+func (r *SQLiteAppointmentRepository) MarkHolidayVerified(ctx context.Context, id uint, onHoliday bool) error {
+	result := r.db.WithContext(ctx).Model(&dbModels.Appointment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"holiday_unverified": false,
+			"holiday_conflict":   onHoliday,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to mark appointment holiday-verified",
+			"error", result.Error,
+			"id", id)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAppointmentNotFound
+	}
+
+	return nil
+}
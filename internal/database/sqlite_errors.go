@@ -0,0 +1,38 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// reports whether err is a UNIQUE constraint violation, either as raised by the
+// glebarez/modernc driver or as translated by GORM
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var sqliteErr *gosqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// reports whether err is a UNIQUE violation of an index on column, given as "table.column";
+// SQLite names every column of the violated index in its message
+func isUniqueViolationOf(err error, column string) bool {
+	return isUniqueViolation(err) && strings.Contains(err.Error(), column)
+}
+
+// maps a failed appointment insert to the domain error for the index it violated
+func appointmentInsertError(err error) error {
+	switch {
+	case isUniqueViolationOf(err, "appointments.reference"):
+		return ErrReferenceTaken
+	case isUniqueViolation(err):
+		return ErrDuplicateAppointment
+	}
+	return err
+}
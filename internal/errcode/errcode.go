@@ -0,0 +1,69 @@
+package errcode
+
+import "errors"
+
+// domain error with a stable, machine-readable code that API clients can match on
+type Error struct {
+	Code     string // e.g. DATE_IN_PAST; never changes once published
+	Location string // request field the error refers to, e.g. body.visitDate; empty if none
+	message  string
+	parent   *Error // more general error this one is a case of, if any
+}
+
+func New(code, location, message string) *Error {
+	return &Error{
+		Code:     code,
+		Location: location,
+		message:  message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// returns a copy of the error pointing at another request field;
+// errors.Is still matches the original
+func (e *Error) At(location string) *Error {
+	return &Error{
+		Code:     e.Code,
+		Location: location,
+		message:  e.message,
+		parent:   e.parent,
+	}
+}
+
+// returns a copy of the error that is a specific case of parent: it keeps its own code,
+// but errors.Is also matches parent
+func (e *Error) Under(parent *Error) *Error {
+	return &Error{
+		Code:     e.Code,
+		Location: e.Location,
+		message:  e.message,
+		parent:   parent,
+	}
+}
+
+// errors with the same code are the same error, wherever they point; an error
+// also matches the more general errors it was declared under
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	for current := e; current != nil; current = current.parent {
+		if current.Code == t.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the coded error in err's chain, or nil if there is none
+func From(err error) *Error {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded
+	}
+	return nil
+}
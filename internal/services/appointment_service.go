@@ -5,82 +5,469 @@ import (
 	"citynext/internal/database"
 	dbModels "citynext/internal/database/models"
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type AppointmentService struct {
 	repo           database.AppointmentRepository
 	holidayService HolidayServiceInterface
+	capacity       DailyCapacity
+	rules          Pipeline // booking rules, run after the calendar rules
+	personLimit    int      // upcoming appointments one person may hold, zero means no limit
+	clock          Clock
+	location       *time.Location // office time zone, which decides which day it is
 	logger         *slog.Logger
 }
 
-func NewAppointmentService(repo database.AppointmentRepository, holidayService HolidayServiceInterface, logger *slog.Logger) *AppointmentService {
-	return &AppointmentService{
+// configures optional AppointmentService behaviour
+type AppointmentServiceOption func(*AppointmentService)
+
+// sets the maximum number of appointments per day
+func WithDailyCapacity(capacity DailyCapacity) AppointmentServiceOption {
+	return func(s *AppointmentService) {
+		s.capacity = capacity
+	}
+}
+
+// replaces the rules applied after the calendar rules, and their order; see BookingRules for the defaults.
+// The person limit is still enforced when a booking is saved, whichever rules run before.
+func WithBookingRules(rules Pipeline) AppointmentServiceOption {
+	return func(s *AppointmentService) {
+		s.rules = rules
+	}
+}
+
+// sets how many upcoming appointments one person may hold; zero means no limit
+func WithPersonLimit(max int) AppointmentServiceOption {
+	return func(s *AppointmentService) {
+		s.personLimit = max
+	}
+}
+
+// sets where the current time comes from and the office time zone it is read in; pass the
+// same ones as to the holiday service, so both agree on which day it is
+func WithOfficeClock(clock Clock, location *time.Location) AppointmentServiceOption {
+	return func(s *AppointmentService) {
+		s.clock = clock
+		s.location = location
+	}
+}
+
+func NewAppointmentService(repo database.AppointmentRepository, holidayService HolidayServiceInterface, logger *slog.Logger, opts ...AppointmentServiceOption) *AppointmentService {
+	s := &AppointmentService{
 		repo:           repo,
 		holidayService: holidayService,
+		capacity:       DefaultDailyCapacity(),
+		clock:          time.Now,
+		location:       DefaultOfficeLocation(),
 		logger:         logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.rules == nil {
+		s.rules = BookingRules(s.personLimit)
+	}
+	return s
 }
 
 type CreateAppointmentRequest struct {
-	FirstName string         `json:"firstName"`
-	LastName  string         `json:"lastName"`
-	VisitDate apiModels.Date `json:"visitDate"`
+	FirstName string              `json:"firstName"`
+	LastName  string              `json:"lastName"`
+	VisitDate apiModels.Date      `json:"visitDate"`
+	StartTime apiModels.TimeOfDay `json:"startTime"`
 }
 
-// creates a new appointment with validation
+// creates a new appointment with validation; names are stored trimmed, so that the person limit
+// matches them however they were typed
 func (s *AppointmentService) CreateAppointment(ctx context.Context, req *CreateAppointmentRequest) (*dbModels.Appointment, error) {
+	req.FirstName, req.LastName = strings.TrimSpace(req.FirstName), strings.TrimSpace(req.LastName)
 	s.logger.Info("Creating appointment",
 		"first_name", req.FirstName,
 		"last_name", req.LastName,
-		"visit_date", req.VisitDate.String())
-
-	if req.FirstName == "" || req.LastName == "" {
-		s.logger.Warn("Invalid input: missing first or last name")
-		return nil, ErrInvalidInput
-	}
+		"visit_date", req.VisitDate.String(),
+		"start_time", req.StartTime.String())
 
-	if err := s.holidayService.ValidateDate(ctx, req.VisitDate); err != nil {
-		s.logger.Warn("Date validation failed",
-			"error", err,
-			"visit_date", req.VisitDate.String())
+	in, err := s.bookingInput(ctx, &Person{FirstName: req.FirstName, LastName: req.LastName}, req.VisitDate, req.StartTime, nil)
+	if err != nil {
 		return nil, err
 	}
 
-	// Check for existing appointment
-	exists, err := s.repo.ExistsByDate(ctx, req.VisitDate)
+	holidayUnverified, err := s.validateBooking(ctx, in)
 	if err != nil {
-		s.logger.Error("Failed to check existing appointment",
-			"error", err,
-			"visit_date", req.VisitDate.String())
 		return nil, err
 	}
 
-	if exists {
-		s.logger.Warn("Duplicate appointment attempt", "visit_date", req.VisitDate.String())
-		return nil, database.ErrDuplicateAppointment
+	token, tokenHash, err := newManagementToken()
+	if err != nil {
+		s.logger.Error("Failed to generate management token", "error", err)
+		return nil, err
 	}
 
 	appointment := &dbModels.Appointment{
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		VisitDate: req.VisitDate,
+		ManagementTokenHash: tokenHash,
+		FirstName:           req.FirstName,
+		LastName:            req.LastName,
+		VisitDate:           req.VisitDate,
+		StartTime:           req.StartTime,
+		HolidayUnverified:   holidayUnverified,
 	}
 
-	if err := s.repo.Create(ctx, appointment); err != nil {
-		s.logger.Error("Failed to create appointment",
-			"error", err,
-			"first_name", req.FirstName,
-			"last_name", req.LastName,
-			"visit_date", req.VisitDate.String())
+	// the repository checks the slot, the daily capacity and the person limit atomically with the insert
+	limit := database.PersonLimit{Max: s.personLimit, From: s.today()}
+	if err := s.createWithReference(ctx, appointment, in.Capacity, limit); err != nil {
+		switch {
+		case errors.Is(err, database.ErrDuplicateAppointment), errors.Is(err, database.ErrDateFullyBooked),
+			errors.Is(err, database.ErrPersonLimitReached):
+			s.logger.Warn("Slot not available",
+				"error", err,
+				"visit_date", req.VisitDate.String(),
+				"start_time", req.StartTime.String(),
+				"capacity", in.Capacity)
+		default:
+			s.logger.Error("Failed to create appointment",
+				"error", err,
+				"first_name", req.FirstName,
+				"last_name", req.LastName,
+				"visit_date", req.VisitDate.String(),
+				"start_time", req.StartTime.String())
+		}
 		return nil, err
 	}
 
+	// handed out once; only the hash is kept
+	appointment.ManagementToken = token
+
 	s.logger.Info("Appointment created successfully",
 		"id", appointment.ID,
+		"reference", appointment.Reference,
 		"first_name", appointment.FirstName,
 		"last_name", appointment.LastName,
-		"visit_date", appointment.VisitDate.String())
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String(),
+		"holiday_unverified", appointment.HolidayUnverified)
+
+	return appointment, nil
+}
+
+// inserts the appointment under a fresh booking reference, drawing a new one when the reference is already taken
+func (s *AppointmentService) createWithReference(ctx context.Context, appointment *dbModels.Appointment, capacity int, limit database.PersonLimit) error {
+	for attempt := 1; ; attempt++ {
+		reference, err := newBookingReference()
+		if err != nil {
+			s.logger.Error("Failed to generate booking reference", "error", err)
+			return err
+		}
+		appointment.Reference = reference
+
+		err = s.repo.CreateWithinCapacity(ctx, appointment, capacity, limit)
+		if !errors.Is(err, database.ErrReferenceTaken) || attempt == referenceAttempts {
+			return err
+		}
+		s.logger.Warn("Booking reference already in use, drawing another", "reference", reference, "attempt", attempt)
+	}
+}
+
+// checks a booking without making it, reporting every rule it breaks
+func (s *AppointmentService) CheckAppointment(ctx context.Context, req *CreateAppointmentRequest) (*RuleResult, error) {
+	s.logger.Debug("Checking appointment",
+		"visit_date", req.VisitDate.String(),
+		"start_time", req.StartTime.String())
+
+	in, err := s.bookingInput(ctx, &Person{FirstName: req.FirstName, LastName: req.LastName}, req.VisitDate, req.StartTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkBooking(ctx, in, CollectAll)
+}
+
+// builds the rule input for booking a slot for person; moving is the appointment being rescheduled,
+// if any, which does not count against the date's capacity
+func (s *AppointmentService) bookingInput(ctx context.Context, person *Person, date apiModels.Date, start apiModels.TimeOfDay, moving *dbModels.Appointment) (*RuleInput, error) {
+	capacity, err := s.capacityFor(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &RuleInput{
+		Date:     date,
+		Start:    &start,
+		Person:   person,
+		Now:      s.clock().In(s.location),
+		Capacity: capacity,
+		Booked: func(ctx context.Context) (int, error) {
+			counts, err := s.repo.CountByDateRange(ctx, date, date)
+			if err != nil {
+				s.logger.Error("Failed to count appointments", "error", err, "date", date.String())
+				return 0, err
+			}
+			booked := counts[date.String()]
+			if moving != nil && moving.VisitDate.String() == date.String() {
+				booked--
+			}
+			return booked, nil
+		},
+	}
+	if person != nil {
+		in.PersonBookings = func(ctx context.Context) (int, error) {
+			return s.upcomingBookings(ctx, *person)
+		}
+	}
+	return in, nil
+}
+
+// checks a booking against every rule: the request itself first, then the calendar, then the
+// appointments already booked. FailFast stops at the first violation, CollectAll reports them all.
+func (s *AppointmentService) checkBooking(ctx context.Context, in *RuleInput, mode RuleMode) (*RuleResult, error) {
+	result := &RuleResult{}
+	if err := (Pipeline{NameRule{}}).runInto(ctx, in, mode, result); err != nil || result.done(mode) {
+		return result, err
+	}
+
+	if err := s.checkCalendar(ctx, in.Date, *in.Start, mode, result); err != nil || result.done(mode) {
+		return result, err
+	}
+
+	if err := s.rules.runInto(ctx, in, mode, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// adds what the calendar rules find about a slot to result
+func (s *AppointmentService) checkCalendar(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay, mode RuleMode, result *RuleResult) error {
+	calendar, err := s.holidayService.CheckRules(ctx, date, &start, mode)
+	if err != nil {
+		return err
+	}
+	result.Violations = append(result.Violations, calendar.Violations...)
+	result.HolidayUnverified = result.HolidayUnverified || calendar.HolidayUnverified
+	return nil
+}
+
+// applies every rule to a booking up to the first violation; a slot accepted without a holiday
+// check under the open fail policy is reported as holidayUnverified instead of as an error
+func (s *AppointmentService) validateBooking(ctx context.Context, in *RuleInput) (holidayUnverified bool, err error) {
+	result, err := s.checkBooking(ctx, in, FailFast)
+	if err != nil {
+		s.logger.Error("Failed to check booking rules",
+			"error", err,
+			"visit_date", in.Date.String(),
+			"start_time", in.Start.String())
+		return false, err
+	}
+
+	if err := result.Err(); err != nil {
+		s.logger.Warn("Booking rejected",
+			"error", err,
+			"rule", result.Violations[0].Rule,
+			"visit_date", in.Date.String(),
+			"start_time", in.Start.String())
+		return false, err
+	}
+	return result.HolidayUnverified, nil
+}
+
+// counts a person's active appointments from today on
+func (s *AppointmentService) upcomingBookings(ctx context.Context, person Person) (int, error) {
+	appointments, _, err := s.repo.List(ctx, database.AppointmentFilter{From: s.today(), LastName: person.LastName})
+	if err != nil {
+		s.logger.Error("Failed to list appointments for person limit", "error", err)
+		return 0, err
+	}
+
+	held := 0
+	for _, appointment := range appointments {
+		if strings.EqualFold(appointment.FirstName, person.FirstName) {
+			held++
+		}
+	}
+	return held, nil
+}
+
+// returns the current date in the office time zone
+func (s *AppointmentService) today() apiModels.Date {
+	return apiModels.DateOf(s.clock(), s.location)
+}
+
+// returns the maximum number of appointments on a date: an extra opening's own capacity, or the daily capacity
+func (s *AppointmentService) capacityFor(ctx context.Context, date apiModels.Date) (int, error) {
+	opening, err := s.holidayService.ExtraOpeningOn(ctx, date)
+	if err != nil {
+		return 0, err
+	}
+	if opening != nil {
+		return opening.Capacity, nil
+	}
+	return s.capacity.For(date), nil
+}
+
+// retrieves a single appointment by ID
+func (s *AppointmentService) GetAppointment(ctx context.Context, id uint) (*dbModels.Appointment, error) {
+	s.logger.Debug("Getting appointment", "id", id)
+
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, database.ErrAppointmentNotFound) {
+			s.logger.Error("Failed to get appointment",
+				"error", err,
+				"id", id)
+		}
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+type ListAppointmentsRequest struct {
+	From             apiModels.Date `json:"from"`
+	To               apiModels.Date `json:"to"`
+	LastName         string         `json:"lastName"`
+	IncludeCancelled bool           `json:"includeCancelled"`
+	Page             int            `json:"page"`
+	PageSize         int            `json:"pageSize"`
+}
+
+// returns one page of appointments matching the request filters and the total number of matches
+func (s *AppointmentService) ListAppointments(ctx context.Context, req *ListAppointmentsRequest) ([]dbModels.Appointment, int64, error) {
+	s.logger.Debug("Listing appointments",
+		"from", req.From.String(),
+		"to", req.To.String(),
+		"last_name", req.LastName,
+		"page", req.Page,
+		"page_size", req.PageSize)
+
+	if req.Page < 1 || req.PageSize < 1 {
+		s.logger.Warn("Invalid input: bad pagination", "page", req.Page, "page_size", req.PageSize)
+		if req.Page < 1 {
+			return nil, 0, ErrInvalidInput.At("query.page")
+		}
+		return nil, 0, ErrInvalidInput.At("query.pageSize")
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && req.From.After(req.To.Time) {
+		s.logger.Warn("Invalid date range",
+			"from", req.From.String(),
+			"to", req.To.String())
+		return nil, 0, ErrInvalidDateRange
+	}
+
+	filter := database.AppointmentFilter{
+		From:             req.From,
+		To:               req.To,
+		LastName:         strings.TrimSpace(req.LastName),
+		Limit:            req.PageSize,
+		Offset:           (req.Page - 1) * req.PageSize,
+		IncludeCancelled: req.IncludeCancelled,
+	}
+
+	appointments, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list appointments", "error", err)
+		return nil, 0, err
+	}
+
+	return appointments, total, nil
+}
+
+type CancelAppointmentRequest struct {
+	ID          uint   `json:"id"`
+	CancelledBy string `json:"cancelledBy"`
+	Reason      string `json:"reason"`
+}
+
+// cancels an active appointment, recording who cancelled it and why, and frees its date for rebooking
+func (s *AppointmentService) CancelAppointment(ctx context.Context, req *CancelAppointmentRequest) (*dbModels.Appointment, error) {
+	s.logger.Info("Cancelling appointment",
+		"id", req.ID,
+		"cancelled_by", req.CancelledBy,
+		"reason", req.Reason)
+
+	cancelledBy := strings.TrimSpace(req.CancelledBy)
+	if cancelledBy == "" {
+		s.logger.Warn("Invalid input: missing cancelledBy")
+		return nil, ErrInvalidInput.At("body.cancelledBy")
+	}
+
+	appointment, err := s.repo.Cancel(ctx, req.ID, cancelledBy, strings.TrimSpace(req.Reason), s.clock().In(s.location))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrAppointmentNotFound), errors.Is(err, database.ErrAlreadyCancelled):
+			s.logger.Warn("Appointment cannot be cancelled", "error", err, "id", req.ID)
+		default:
+			s.logger.Error("Failed to cancel appointment", "error", err, "id", req.ID)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Appointment cancelled successfully",
+		"id", appointment.ID,
+		"visit_date", appointment.VisitDate.String(),
+		"cancelled_by", appointment.CancelledBy)
+
+	return appointment, nil
+}
+
+type RescheduleAppointmentRequest struct {
+	ID        uint                `json:"id"`
+	VisitDate apiModels.Date      `json:"visitDate"`
+	StartTime apiModels.TimeOfDay `json:"startTime"`
+}
+
+// moves an active appointment to a new slot, the new slot goes through the same validation as a new booking
+func (s *AppointmentService) RescheduleAppointment(ctx context.Context, req *RescheduleAppointmentRequest) (*dbModels.Appointment, error) {
+	s.logger.Info("Rescheduling appointment",
+		"id", req.ID,
+		"new_visit_date", req.VisitDate.String(),
+		"new_start_time", req.StartTime.String())
+
+	current, err := s.GetAppointment(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.IsCancelled() {
+		s.logger.Warn("Cannot reschedule cancelled appointment", "id", req.ID)
+		return nil, database.ErrAlreadyCancelled
+	}
+	// a visit that has already taken place is spent and cannot be moved to a new date
+	if current.VisitDate.Before(s.today().Time) {
+		s.logger.Warn("Cannot reschedule past appointment", "id", req.ID, "visit_date", current.VisitDate.String())
+		return nil, ErrAppointmentInPast
+	}
+	oldDate, oldStart := current.VisitDate, current.StartTime
+
+	// the person is left out, as moving an appointment neither adds one to their limit nor changes their name
+	in, err := s.bookingInput(ctx, nil, req.VisitDate, req.StartTime, current)
+	if err != nil {
+		return nil, err
+	}
+
+	holidayUnverified, err := s.validateBooking(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	// the repository re-checks status and availability inside its transaction
+	appointment, err := s.repo.Reschedule(ctx, req.ID, req.VisitDate, req.StartTime, in.Capacity, holidayUnverified)
+	if err != nil {
+		s.logger.Warn("Failed to reschedule appointment",
+			"error", err,
+			"id", req.ID,
+			"new_visit_date", req.VisitDate.String(),
+			"new_start_time", req.StartTime.String())
+		return nil, err
+	}
+
+	s.logger.Info("Appointment rescheduled successfully",
+		"id", appointment.ID,
+		"old_visit_date", oldDate.String(),
+		"old_start_time", oldStart.String(),
+		"visit_date", appointment.VisitDate.String(),
+		"start_time", appointment.StartTime.String())
 
 	return appointment, nil
 }
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	"citynext/internal/errcode"
+)
+
+// longest range, in days, accepted by GetAvailability
+const MaxAvailabilityDays = 92
+
+// machine-readable reason why a date cannot be booked
+type UnavailableReason string
+
+const (
+	ReasonPast    UnavailableReason = "past"
+	ReasonTooSoon UnavailableReason = "too_soon"
+	ReasonTooFar  UnavailableReason = "too_far"
+	ReasonWeekend UnavailableReason = "weekend"
+	ReasonHoliday UnavailableReason = "holiday"
+	ReasonClosed  UnavailableReason = "closed"
+	ReasonFull    UnavailableReason = "full"
+)
+
+// bookability of a single date
+type DayAvailability struct {
+	Date         apiModels.Date
+	Bookable     bool
+	Reason       UnavailableReason   // empty when bookable, otherwise the first of Reasons
+	Reasons      []UnavailableReason // every reason the date cannot be booked, in rule order
+	HolidayName  string              // set when the date is a public holiday the office observes
+	Closure      string              // reason for the closure, set when Reason is ReasonClosed or the office closes at midday
+	HalfDay      bool                // the office closes early for a half-day closure
+	ExtraOpening string              // reason for an extra opening that makes the date bookable
+	Remaining    int                 // appointments still available, zero when not bookable
+
+	HolidayUnverified bool // bookable only because holidays could not be looked up
+}
+
+// reports for each date from..to (inclusive) whether it can be booked and why not,
+// using one holiday lookup per year and one appointment count query for the whole range
+func (s *AppointmentService) GetAvailability(ctx context.Context, from, to apiModels.Date) ([]DayAvailability, error) {
+	s.logger.Debug("Getting availability",
+		"from", from.String(),
+		"to", to.String())
+
+	if from.After(to.Time) {
+		s.logger.Warn("Invalid date range",
+			"from", from.String(),
+			"to", to.String())
+		return nil, ErrInvalidDateRange
+	}
+
+	if to.Sub(from.Time).Hours()/24 >= MaxAvailabilityDays {
+		s.logger.Warn("Date range too long",
+			"from", from.String(),
+			"to", to.String(),
+			"max_days", MaxAvailabilityDays)
+		return nil, ErrDateRangeTooLong
+	}
+
+	checks, err := s.holidayService.CheckDates(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Failed to check dates", "error", err)
+		return nil, err
+	}
+
+	counts, err := s.repo.CountByDateRange(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Failed to count appointments", "error", err)
+		return nil, err
+	}
+
+	hours := s.holidayService.OpeningHours()
+
+	days := make([]DayAvailability, 0, len(checks))
+	for _, check := range checks {
+		day := DayAvailability{Date: check.Date, HolidayUnverified: check.Unverified}
+		if check.Closure != nil {
+			day.Closure = check.Closure.Reason
+			day.HalfDay = check.Closure.HalfDay
+		}
+		if check.Holiday != nil {
+			day.HolidayName = check.Holiday.Name
+		}
+
+		calendar, err := calendarViolations(check)
+		if err != nil {
+			return nil, err
+		}
+
+		in := &RuleInput{
+			Date:     check.Date,
+			Closure:  check.Closure,
+			Hours:    check.Hours,
+			Capacity: s.capacity.For(check.Date),
+			// zero from a holiday service that does not report it, which means the default
+			HalfDayCloses: check.HalfDay,
+			Booked: func(context.Context) (int, error) {
+				return counts[check.Date.String()], nil
+			},
+		}
+		if in.Hours.SlotLength == 0 {
+			in.Hours = hours
+		}
+		if check.Opening != nil {
+			in.Capacity = check.Opening.Capacity
+		}
+
+		// every rule runs, so a date is listed with all the reasons it cannot be booked
+		result := &RuleResult{Violations: slices.Clone(calendar)}
+		if err := s.rules.runInto(ctx, in, CollectAll, result); err != nil {
+			return nil, err
+		}
+		for _, found := range result.Violations {
+			reason, known := reasonFor(found.Err)
+			if !known {
+				return nil, found.Err
+			}
+			if !slices.Contains(day.Reasons, reason) {
+				day.Reasons = append(day.Reasons, reason)
+			}
+		}
+
+		if len(calendar) == 0 && check.Opening != nil {
+			day.ExtraOpening = check.Opening.Reason
+		}
+		if len(day.Reasons) > 0 {
+			day.Reason = day.Reasons[0]
+		} else {
+			day.Bookable = true
+			day.Remaining = dailyLimit(in) - counts[check.Date.String()]
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// names the violation standing in for a date check that carries only its first error
+const calendarRuleName = "calendar"
+
+// returns the calendar rules a date breaks; a check carrying only Err stands for a single violation
+func calendarViolations(check DateCheck) ([]Violation, error) {
+	if len(check.Violations) > 0 || check.Err == nil {
+		return check.Violations, nil
+	}
+	coded := errcode.From(check.Err)
+	if coded == nil {
+		return nil, check.Err
+	}
+	return []Violation{{Rule: calendarRuleName, Err: coded}}, nil
+}
+
+// returns the availability reason for a rule violation, and false for violations that dates cannot have;
+// specific errors come before the more general ones they are declared under
+func reasonFor(err *errcode.Error) (UnavailableReason, bool) {
+	switch {
+	case errors.Is(err, ErrDateInPast):
+		return ReasonPast, true
+	case errors.Is(err, ErrDateTooSoon), errors.Is(err, ErrSameDayCutoff):
+		return ReasonTooSoon, true
+	case errors.Is(err, ErrDateTooFar):
+		return ReasonTooFar, true
+	case errors.Is(err, ErrClosedWeekday):
+		return ReasonWeekend, true
+	case errors.Is(err, ErrOfficeClosed):
+		return ReasonClosed, true
+	case errors.Is(err, ErrDateIsHoliday):
+		return ReasonHoliday, true
+	case errors.Is(err, database.ErrDateFullyBooked):
+		return ReasonFull, true
+	default:
+		return "", false
+	}
+}
+
+// number of alternative dates offered when a booking is rejected
+const DefaultSuggestionCount = 3
+
+// how far ahead of the requested date SuggestDates looks
+const suggestionWindowDays = 31
+
+// returns up to count bookable dates on or after from, earliest first,
+// applying the same rules and capacity checks as GetAvailability
+func (s *AppointmentService) SuggestDates(ctx context.Context, from apiModels.Date, count int) ([]apiModels.Date, error) {
+	to := apiModels.Date{Time: from.AddDate(0, 0, suggestionWindowDays-1)}
+
+	days, err := s.GetAvailability(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]apiModels.Date, 0, count)
+	for _, day := range days {
+		if len(suggestions) == count {
+			break
+		}
+		if day.Bookable {
+			suggestions = append(suggestions, day.Date)
+		}
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	dbModels "citynext/internal/database/models"
+)
+
+// Crockford's base32 alphabet: no I, L, O or U, so references survive being read out over the phone
+const referenceAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const referencePrefix = "CN-"
+
+// how many references a booking draws before giving up; 32^8 references make even a second draw rare
+const referenceAttempts = 3
+
+// generates a random, non-sequential booking reference such as CN-7K4Q-92XD
+func newBookingReference() (string, error) {
+	var random [8]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, len(random))
+	for i, b := range random {
+		code[i] = referenceAlphabet[int(b)%len(referenceAlphabet)] // 256 is a multiple of 32, so no bias
+	}
+
+	return referencePrefix + string(code[:4]) + "-" + string(code[4:]), nil
+}
+
+// brings a reference typed by a citizen into its stored form
+func normalizeBookingReference(reference string) string {
+	return strings.ToUpper(strings.TrimSpace(reference))
+}
+
+// generates a secret management token and the hash that is stored in its place
+func newManagementToken() (token, hash string, err error) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(random[:])
+	return token, hashManagementToken(token), nil
+}
+
+func hashManagementToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// reports whether token is the management token issued for the appointment
+func managementTokenMatches(appointment *dbModels.Appointment, token string) bool {
+	if appointment.ManagementTokenHash == "" {
+		return false // booked before references existed
+	}
+	return subtle.ConstantTimeCompare([]byte(hashManagementToken(token)), []byte(appointment.ManagementTokenHash)) == 1
+}
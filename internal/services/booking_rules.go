@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+
+	"citynext/internal/database"
+)
+
+// the rules AppointmentService applies around the calendar rules, in their default order;
+// a limit of zero lets a person hold any number of appointments
+func BookingRules(personLimit int) Pipeline {
+	return Pipeline{
+		CapacityRule{},
+		PersonLimitRule{Max: personLimit},
+	}
+}
+
+// rejects bookings without the name of the person they are for
+type NameRule struct{}
+
+func (NameRule) Name() string { return "name" }
+
+func (r NameRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	switch {
+	case in.Person == nil:
+		return nil, nil
+	case in.Person.FirstName == "":
+		return violation(r, ErrInvalidInput.At("body.firstName")), nil
+	case in.Person.LastName == "":
+		return violation(r, ErrInvalidInput.At("body.lastName")), nil
+	}
+	return nil, nil
+}
+
+// rejects dates with no appointments left; the repository re-checks capacity atomically with the insert
+type CapacityRule struct{}
+
+func (CapacityRule) Name() string { return "capacity" }
+
+func (r CapacityRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if in.Booked == nil {
+		return nil, nil
+	}
+
+	booked, err := in.Booked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if booked >= dailyLimit(in) {
+		return violation(r, database.ErrDateFullyBooked), nil
+	}
+	return nil, nil
+}
+
+// returns how many appointments fit on the date: its capacity, capped by the number of slots when the hours are known
+func dailyLimit(in *RuleInput) int {
+	if in.Hours.SlotLength == 0 {
+		return in.Capacity
+	}
+	slots := len(in.Hours.Slots())
+	if in.Closure != nil && in.Closure.HalfDay {
+		slots = len(in.Hours.SlotsUntil(in.halfDayCloses()))
+	}
+	return min(in.Capacity, slots)
+}
+
+// rejects bookings for a person who already holds Max upcoming appointments; zero means no limit.
+// The count is a first check only: the repository counts again in the transaction that saves the booking.
+type PersonLimitRule struct {
+	Max int
+}
+
+func (PersonLimitRule) Name() string { return "person_limit" }
+
+func (r PersonLimitRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if r.Max <= 0 || in.Person == nil || in.PersonBookings == nil {
+		return nil, nil
+	}
+
+	held, err := in.PersonBookings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if held >= r.Max {
+		return violation(r, database.ErrPersonLimitReached), nil
+	}
+	return nil, nil
+}
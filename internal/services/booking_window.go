@@ -0,0 +1,45 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// limits how soon and how far ahead appointments can be booked; zero fields impose no limit
+type BookingWindow struct {
+	MinNotice      time.Duration        // between the booking and the start of its slot
+	MinWorkingDays int                  // working days between today and the visit date
+	MaxDaysAhead   int                  // calendar days between today and the visit date
+	SameDayCutoff  *apiModels.TimeOfDay // time of day after which today can no longer be booked
+}
+
+func (w BookingWindow) Validate() error {
+	if w.MinNotice < 0 {
+		return errors.New("minimum notice must not be negative")
+	}
+	if w.MinWorkingDays < 0 {
+		return errors.New("minimum lead time must not be negative")
+	}
+	if w.MaxDaysAhead < 0 {
+		return errors.New("booking horizon must not be negative")
+	}
+	return nil
+}
+
+// returns the last date the horizon allows, and false when there is no horizon
+func (w BookingWindow) lastBookableDate(today apiModels.Date) (apiModels.Date, bool) {
+	if w.MaxDaysAhead <= 0 {
+		return apiModels.Date{}, false
+	}
+	return apiModels.Date{Time: today.AddDate(0, 0, w.MaxDaysAhead)}, true
+}
+
+// reports whether same-day bookings have closed at the given local time of day
+func (w BookingWindow) pastSameDayCutoff(now time.Time) bool {
+	if w.SameDayCutoff == nil {
+		return false
+	}
+	return !apiModels.NewTimeOfDay(now.Hour(), now.Minute()).Before(*w.SameDayCutoff)
+}
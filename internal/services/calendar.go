@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+	"citynext/pkg/businessday"
+	"citynext/pkg/client"
+)
+
+// longest range, in days, accepted by Calendar
+const MaxCalendarDays = 366
+
+// implemented by holiday services that can publish the office calendar
+type CalendarSource interface {
+	HolidaysInYear(ctx context.Context, year int) ([]client.Holiday, error)
+	Calendar(ctx context.Context, from, to apiModels.Date) ([]CalendarEntry, error)
+	WorkingDays(ctx context.Context, from apiModels.Date, days int, to apiModels.Date) (*WorkingDays, error)
+}
+
+// a reason the office is shut, or shuts early, on a date
+type CalendarEntry struct {
+	Date    apiModels.Date
+	Reason  UnavailableReason // ReasonWeekend, ReasonHoliday or ReasonClosed
+	Name    string            // holiday name or closure reason, empty for a closed day of the week
+	Holiday *client.Holiday   // set when Reason is ReasonHoliday
+	HalfDay bool              // set when a closure only shuts the office from midday
+}
+
+// working-day arithmetic from a date; working days are the open days of the weekly schedule
+// that are not public holidays, while closures and extra openings do not count
+type WorkingDays struct {
+	From           apiModels.Date
+	IsWorkingDay   bool
+	NextWorkingDay apiModels.Date // first working day after From
+	Days           int
+	Date           apiModels.Date // Days working days on from From, or back when Days is negative
+	To             apiModels.Date // zero when no end date was asked for
+	Between        int            // working days after From up to and including To, negative when To is before From
+}
+
+// lists the public holidays the office observes in a year, in date order. Unlike booking checks,
+// this fails under every fail policy: a calendar missing its holidays would be cached as if complete.
+// Years outside listableYears are rejected with ErrYearOutOfRange.
+func (s *HolidayService) HolidaysInYear(ctx context.Context, year int) ([]client.Holiday, error) {
+	if first, last := s.listableYears(); year < first || year > last {
+		return nil, ErrYearOutOfRange
+	}
+
+	byDate, err := s.holidaysForYear(ctx, year)
+	if err != nil {
+		s.logger.Error("Failed to load holidays for year",
+			"error", err,
+			"year", year)
+		return nil, ErrHolidayDataUnavailable
+	}
+
+	holidays := make([]client.Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+	return holidays, nil
+}
+
+// returns the years whose holidays can be listed: last year through next year, or through the year of
+// the booking horizon when it lies further ahead, so that no request makes the service fetch and store
+// the holidays of arbitrary years
+func (s *HolidayService) listableYears() (first, last int) {
+	today := s.Today()
+	first, last = today.Year()-1, today.Year()+1
+	if end, limited := s.window.lastBookableDate(today); limited && end.Year() > last {
+		last = end.Year()
+	}
+	return first, last
+}
+
+// lists the closed days of the week, observed public holidays and closures from..to (inclusive), in date order.
+// An extra opening lifts the weekday and holiday entries of its dates, but not its closures. Both dates must
+// lie in listableYears.
+func (s *HolidayService) Calendar(ctx context.Context, from, to apiModels.Date) ([]CalendarEntry, error) {
+	s.logger.Debug("Building calendar",
+		"from", from.String(),
+		"to", to.String())
+
+	if from.After(to.Time) {
+		return nil, ErrInvalidDateRange
+	}
+	if to.Sub(from.Time).Hours()/24 >= MaxCalendarDays {
+		return nil, ErrDateRangeTooLong
+	}
+	if first, last := s.listableYears(); from.Year() < first {
+		return nil, ErrYearOutOfRange.At("query.from")
+	} else if to.Year() > last {
+		return nil, ErrYearOutOfRange.At("query.to")
+	}
+
+	holidaysByYear := make(map[int]map[string]client.Holiday)
+	for year := from.Year(); year <= to.Year(); year++ {
+		holidays, err := s.HolidaysInYear(ctx, year)
+		if err != nil {
+			return nil, err
+		}
+		holidaysByYear[year] = make(map[string]client.Holiday, len(holidays))
+		for _, holiday := range holidays {
+			holidaysByYear[year][holiday.Date] = holiday
+		}
+	}
+
+	var closures []dbModels.Closure
+	if s.closures != nil {
+		var err error
+		closures, err = s.closures.ListOverlapping(ctx, from, to)
+		if err != nil {
+			s.logger.Error("Failed to look up office closures for calendar", "error", err)
+			return nil, err
+		}
+	}
+
+	var openings []dbModels.ExtraOpening
+	if s.openings != nil {
+		var err error
+		openings, err = s.openings.ListOverlapping(ctx, from, to)
+		if err != nil {
+			s.logger.Error("Failed to look up extra openings for calendar", "error", err)
+			return nil, err
+		}
+	}
+
+	var entries []CalendarEntry
+	for day := from.Time; !day.After(to.Time); day = day.AddDate(0, 0, 1) {
+		date := apiModels.Date{Time: day}
+
+		if coveringOpening(openings, date) == nil {
+			if !s.business.IsWorkingWeekday(day.Weekday()) {
+				entries = append(entries, CalendarEntry{Date: date, Reason: ReasonWeekend})
+			}
+			if holiday, found := holidaysByYear[day.Year()][date.String()]; found {
+				entries = append(entries, CalendarEntry{Date: date, Reason: ReasonHoliday, Name: holiday.Name, Holiday: &holiday})
+			}
+		}
+
+		if closure := decidingClosure(closures, date); closure != nil {
+			entries = append(entries, CalendarEntry{Date: date, Reason: ReasonClosed, Name: closure.Reason, HalfDay: closure.HalfDay})
+		}
+	}
+
+	return entries, nil
+}
+
+// answers working-day questions about from, which defaults to today and must lie within MaxCalendarDays
+// of it, so that no request makes the service look up holidays of arbitrary years; to is optional
+func (s *HolidayService) WorkingDays(ctx context.Context, from apiModels.Date, days int, to apiModels.Date) (*WorkingDays, error) {
+	today := s.Today()
+	if from.IsZero() {
+		from = today
+	}
+	if math.Abs(from.Sub(today.Time).Hours()/24) > MaxCalendarDays {
+		return nil, ErrDateOutOfRange
+	}
+	if !to.IsZero() && math.Abs(to.Sub(from.Time).Hours()/24) >= MaxCalendarDays {
+		return nil, ErrDateRangeTooLong
+	}
+
+	result := &WorkingDays{From: from, Days: days, To: to}
+	var err error
+	if result.IsWorkingDay, err = s.business.IsWorkingDay(ctx, from.Time); err != nil {
+		return nil, s.workingDaysFailed(err)
+	}
+
+	next, err := s.business.NextWorkingDay(ctx, from.Time)
+	if err != nil {
+		return nil, s.workingDaysFailed(err)
+	}
+	result.NextWorkingDay = apiModels.Date{Time: next}
+
+	date, err := s.business.AddWorkingDays(ctx, from.Time, days)
+	if err != nil {
+		return nil, s.workingDaysFailed(err)
+	}
+	result.Date = apiModels.Date{Time: date}
+
+	if !to.IsZero() {
+		if result.Between, err = s.business.WorkingDaysBetween(ctx, from.Time, to.Time); err != nil {
+			return nil, s.workingDaysFailed(err)
+		}
+	}
+
+	return result, nil
+}
+
+// holidays are the only lookups working days need, so any other failure means they are unavailable
+func (s *HolidayService) workingDaysFailed(err error) error {
+	if errors.Is(err, businessday.ErrNoWorkingDay) {
+		return ErrNoWorkingDay
+	}
+	s.logger.Error("Failed to count working days", "error", err)
+	return ErrHolidayDataUnavailable
+}
@@ -0,0 +1,143 @@
+package services
+
+import "context"
+
+// the rules HolidayService applies to dates and slots, in their default order:
+// checks without lookups first, so a date rejected early costs no holiday lookup
+func CalendarRules(schedule WeeklySchedule, window BookingWindow, policy HolidayFailPolicy) Pipeline {
+	return Pipeline{
+		OpeningHoursRule{},
+		PastDateRule{},
+		WeekdayRule{Schedule: schedule},
+		BookingWindowRule{Window: window, FailPolicy: policy},
+		ClosureRule{},
+		HolidayRule{FailPolicy: policy},
+	}
+}
+
+// rejects slots that do not start on a slot boundary within the date's opening hours
+type OpeningHoursRule struct{}
+
+func (OpeningHoursRule) Name() string { return "opening_hours" }
+
+func (r OpeningHoursRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if in.Start != nil && !in.Hours.IsSlotStart(*in.Start) {
+		return violation(r, ErrOutsideOpeningHours), nil
+	}
+	return nil, nil
+}
+
+// rejects dates before today at the office, and slots today that have already started
+type PastDateRule struct{}
+
+func (PastDateRule) Name() string { return "past_date" }
+
+func (r PastDateRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if in.day().Before(in.today().Time) {
+		return violation(r, ErrDateInPast), nil
+	}
+	if in.Start != nil && !in.startsAt().After(in.Now) {
+		return violation(r, ErrDateInPast), nil
+	}
+	return nil, nil
+}
+
+// rejects days of the week the office does not open, unless an extra opening covers the date
+type WeekdayRule struct {
+	Schedule WeeklySchedule
+}
+
+func (WeekdayRule) Name() string { return "weekday" }
+
+func (r WeekdayRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if in.Opening != nil {
+		return nil, nil
+	}
+	if _, open := r.Schedule.HoursOn(in.day().Weekday()); !open {
+		return violation(r, ErrClosedWeekday), nil
+	}
+	return nil, nil
+}
+
+// rejects bookings beyond the horizon, for today after the same-day cut-off, within the minimum
+// lead time or without the minimum notice. The lead time counts working days, so it is not
+// enforced while holidays cannot be looked up under the open fail policy, as the holidays themselves are not.
+type BookingWindowRule struct {
+	Window     BookingWindow
+	FailPolicy HolidayFailPolicy
+}
+
+func (BookingWindowRule) Name() string { return "booking_window" }
+
+func (r BookingWindowRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	today, day := in.today(), in.day()
+
+	if last, limited := r.Window.lastBookableDate(today); limited && day.After(last.Time) {
+		return violation(r, ErrDateTooFar), nil
+	}
+
+	if day.Equal(today.Time) && r.Window.pastSameDayCutoff(in.Now) {
+		return violation(r, ErrSameDayCutoff), nil
+	}
+
+	if r.Window.MinWorkingDays > 0 && in.Earliest != nil {
+		earliest, err := in.Earliest(ctx)
+		switch {
+		case err != nil && r.FailPolicy != HolidayFailOpen:
+			return nil, ErrHolidayDataUnavailable
+		case err == nil && day.Before(earliest.Time):
+			return violation(r, ErrDateTooSoon), nil
+		}
+	}
+
+	if in.Start != nil && in.startsAt().Before(in.Now.Add(r.Window.MinNotice)) {
+		return violation(r, ErrNoticeTooShort), nil
+	}
+	return nil, nil
+}
+
+// rejects dates the office is closed all day, and afternoon slots on half-day closures
+type ClosureRule struct{}
+
+func (ClosureRule) Name() string { return "closure" }
+
+func (r ClosureRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	if in.Closure == nil {
+		return nil, nil
+	}
+	if !in.Closure.HalfDay {
+		return violation(r, ErrOfficeClosed), nil
+	}
+	if in.Start != nil && in.Start.Add(in.Hours.SlotLength).After(in.halfDayCloses()) {
+		return violation(r, ErrOfficeClosedForSlot), nil
+	}
+	return nil, nil
+}
+
+// rejects public holidays the office observes, unless an extra opening covers the date;
+// when holidays cannot be looked up, the open fail policy lets the date through as unverified
+type HolidayRule struct {
+	FailPolicy HolidayFailPolicy
+}
+
+func (HolidayRule) Name() string { return "holiday" }
+
+func (r HolidayRule) Check(ctx context.Context, in *RuleInput) (*Violation, error) {
+	// an extra opening is decided by staff, so holidays are not looked up at all
+	if in.Opening != nil || in.Holiday == nil {
+		return nil, nil
+	}
+
+	holiday, err := in.Holiday(ctx)
+	if err != nil {
+		if r.FailPolicy != HolidayFailOpen {
+			return nil, ErrHolidayDataUnavailable
+		}
+		in.HolidayUnverified = true
+		return nil, nil
+	}
+	if holiday != nil {
+		return violation(r, ErrDateIsHoliday), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// maximum number of active appointments per day, with per-date overrides such as staff training days
+type DailyCapacity struct {
+	Default   int
+	Overrides map[string]int // date string -> capacity
+}
+
+// returns the default daily capacity: one appointment per default slot
+func DefaultDailyCapacity() DailyCapacity {
+	return DailyCapacity{Default: len(DefaultOpeningHours().Slots())}
+}
+
+// checks that dates without an override can be booked at all
+func (c DailyCapacity) Validate() error {
+	if c.Default < 1 {
+		return fmt.Errorf("daily capacity must be at least 1, got %d", c.Default)
+	}
+	return nil
+}
+
+// returns the capacity for a date, an override of zero means no bookings that day
+func (c DailyCapacity) For(date apiModels.Date) int {
+	if capacity, ok := c.Overrides[date.String()]; ok {
+		return capacity
+	}
+	return c.Default
+}
+
+// parses per-date capacity overrides in the form "2025-03-14=2,2025-04-01=0"
+func ParseCapacityOverrides(spec string) (map[string]int, error) {
+	overrides := make(map[string]int)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		dateStr, capacityStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid capacity override %q, expected YYYY-MM-DD=N", entry)
+		}
+
+		var date apiModels.Date
+		if err := date.UnmarshalText([]byte(strings.TrimSpace(dateStr))); err != nil {
+			return nil, fmt.Errorf("invalid capacity override %q: %w", entry, err)
+		}
+
+		capacity, err := strconv.Atoi(strings.TrimSpace(capacityStr))
+		if err != nil || capacity < 0 {
+			return nil, fmt.Errorf("invalid capacity override %q, capacity must be a non-negative integer", entry)
+		}
+
+		overrides[date.String()] = capacity
+	}
+	return overrides, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // the office time zone must resolve on hosts without a zoneinfo database
+)
+
+// time zone whose calendar decides which day it is at the office
+const DefaultOfficeTimeZone = "Europe/London"
+
+// source of the current time; tests replace it to pin "now"
+type Clock func() time.Time
+
+func DefaultOfficeLocation() *time.Location {
+	location, err := time.LoadLocation(DefaultOfficeTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// parses an OFFICE_TIME_ZONE value, an IANA time zone name such as "Europe/London"
+func ParseOfficeTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid office time zone %q, expected an IANA name such as %s", name, DefaultOfficeTimeZone)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid office time zone %q: %w", name, err)
+	}
+	return location, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+)
+
+// returns the time from which the office is closed on a half-day closure unless configured otherwise: midday
+func DefaultHalfDayClosingTime() apiModels.TimeOfDay {
+	return apiModels.NewTimeOfDay(12, 0)
+}
+
+// longest closure or extra opening, in days, that can be recorded in one go
+const MaxCalendarEntryDays = 366
+
+// checks the dates of a closure or extra opening and returns its end date, which defaults to start
+func calendarEntryEnd(start, end apiModels.Date) (apiModels.Date, error) {
+	if start.IsZero() {
+		return apiModels.Date{}, ErrInvalidInput.At("body.startDate")
+	}
+	if end.IsZero() {
+		end = start
+	}
+	if start.After(end.Time) {
+		return apiModels.Date{}, ErrInvalidDateRange.At("body.endDate")
+	}
+	if end.Sub(start.Time).Hours()/24 >= MaxCalendarEntryDays {
+		return apiModels.Date{}, ErrDateRangeTooLong.At("body.endDate")
+	}
+	return end, nil
+}
+
+// manages office closures and reports the bookings they affect
+type ClosureService struct {
+	closures     database.ClosureRepository
+	appointments database.AppointmentRepository
+	hours        OpeningHours        // slot length, to tell which bookings a half-day closure affects
+	halfDay      apiModels.TimeOfDay // when a half-day closure shuts the office
+	logger       *slog.Logger
+}
+
+func NewClosureService(closures database.ClosureRepository, appointments database.AppointmentRepository, hours OpeningHours, halfDayClosingTime apiModels.TimeOfDay, logger *slog.Logger) *ClosureService {
+	return &ClosureService{
+		closures:     closures,
+		appointments: appointments,
+		hours:        hours,
+		halfDay:      halfDayClosingTime,
+		logger:       logger,
+	}
+}
+
+type ClosureRequest struct {
+	StartDate apiModels.Date `json:"startDate"`
+	EndDate   apiModels.Date `json:"endDate"` // defaults to StartDate
+	Reason    string         `json:"reason"`
+	HalfDay   bool           `json:"halfDay"`
+}
+
+// checks a closure request and turns it into a closure, filling in the end date of a single-day closure
+func (s *ClosureService) toClosure(req *ClosureRequest) (*dbModels.Closure, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		s.logger.Warn("Invalid input: missing closure reason")
+		return nil, ErrInvalidInput.At("body.reason")
+	}
+	end, err := calendarEntryEnd(req.StartDate, req.EndDate)
+	if err != nil {
+		s.logger.Warn("Invalid closure dates",
+			"error", err,
+			"start_date", req.StartDate.String(),
+			"end_date", req.EndDate.String())
+		return nil, err
+	}
+
+	return &dbModels.Closure{
+		StartDate: req.StartDate,
+		EndDate:   end,
+		Reason:    reason,
+		HalfDay:   req.HalfDay,
+	}, nil
+}
+
+// records a closure and returns the active appointments that fall inside it, so staff can contact those citizens
+func (s *ClosureService) CreateClosure(ctx context.Context, req *ClosureRequest) (*dbModels.Closure, []dbModels.Appointment, error) {
+	s.logger.Info("Creating closure",
+		"start_date", req.StartDate.String(),
+		"end_date", req.EndDate.String(),
+		"half_day", req.HalfDay)
+
+	closure, err := s.toClosure(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.closures.Create(ctx, closure); err != nil {
+		s.logger.Error("Failed to create closure", "error", err)
+		return nil, nil, err
+	}
+
+	affected, err := s.affectedAppointments(ctx, closure)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("Closure created successfully",
+		"id", closure.ID,
+		"affected_appointments", len(affected))
+	return closure, affected, nil
+}
+
+// retrieves a single closure by ID
+func (s *ClosureService) GetClosure(ctx context.Context, id uint) (*dbModels.Closure, error) {
+	closure, err := s.closures.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, database.ErrClosureNotFound) {
+			s.logger.Error("Failed to get closure", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return closure, nil
+}
+
+// returns the closures covering any date from..to (inclusive), zero values leave the range open
+func (s *ClosureService) ListClosures(ctx context.Context, from, to apiModels.Date) ([]dbModels.Closure, error) {
+	if !from.IsZero() && !to.IsZero() && from.After(to.Time) {
+		s.logger.Warn("Invalid date range",
+			"from", from.String(),
+			"to", to.String())
+		return nil, ErrInvalidDateRange
+	}
+
+	closures, err := s.closures.ListOverlapping(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Failed to list closures", "error", err)
+		return nil, err
+	}
+	return closures, nil
+}
+
+// replaces the dates, reason and half-day flag of a closure and returns the active appointments that
+// now fall inside it
+func (s *ClosureService) UpdateClosure(ctx context.Context, id uint, req *ClosureRequest) (*dbModels.Closure, []dbModels.Appointment, error) {
+	s.logger.Info("Updating closure",
+		"id", id,
+		"start_date", req.StartDate.String(),
+		"end_date", req.EndDate.String(),
+		"half_day", req.HalfDay)
+
+	closure, err := s.toClosure(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closure.ID = id
+	if err := s.closures.Update(ctx, closure); err != nil {
+		if !errors.Is(err, database.ErrClosureNotFound) {
+			s.logger.Error("Failed to update closure", "error", err, "id", id)
+		}
+		return nil, nil, err
+	}
+
+	updated, err := s.closures.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	affected, err := s.affectedAppointments(ctx, updated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("Closure updated successfully",
+		"id", id,
+		"affected_appointments", len(affected))
+	return updated, affected, nil
+}
+
+// removes a closure, reopening its dates for bookings
+func (s *ClosureService) DeleteClosure(ctx context.Context, id uint) error {
+	if err := s.closures.Delete(ctx, id); err != nil {
+		if !errors.Is(err, database.ErrClosureNotFound) {
+			s.logger.Error("Failed to delete closure", "error", err, "id", id)
+		}
+		return err
+	}
+
+	s.logger.Info("Closure deleted successfully", "id", id)
+	return nil
+}
+
+// returns the active appointments the closure cancels out: every one in its range,
+// or for a half-day closure only those that end after the half-day closing time
+func (s *ClosureService) affectedAppointments(ctx context.Context, closure *dbModels.Closure) ([]dbModels.Appointment, error) {
+	appointments, _, err := s.appointments.List(ctx, database.AppointmentFilter{
+		From: closure.StartDate,
+		To:   closure.EndDate,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list appointments affected by closure",
+			"error", err,
+			"closure_id", closure.ID)
+		return nil, err
+	}
+
+	affected := make([]dbModels.Appointment, 0, len(appointments))
+	for _, appointment := range appointments {
+		if closure.HalfDay && !appointment.StartTime.Add(s.hours.SlotLength).After(s.halfDay) {
+			continue
+		}
+		affected = append(affected, appointment)
+		s.logger.Warn("Appointment falls inside office closure",
+			"closure_id", closure.ID,
+			"appointment_id", appointment.ID,
+			"reference", appointment.Reference,
+			"visit_date", appointment.VisitDate.String(),
+			"start_time", appointment.StartTime.String())
+	}
+
+	return affected, nil
+}
@@ -1,11 +1,29 @@
 package services
 
-import "errors"
+import "citynext/internal/errcode"
 
 // Custom error types for the services layer
 var (
-	ErrDateInPast    = errors.New("visit date cannot be in the past")
-	ErrDateIsHoliday = errors.New("visit date is a public holiday")
-	ErrDateIsWeekend = errors.New("visit date is a weekend")
-	ErrInvalidInput  = errors.New("invalid input data")
+	ErrDateInPast          = errcode.New("DATE_IN_PAST", "body.visitDate", "visit date cannot be in the past")
+	ErrDateTooSoon         = errcode.New("DATE_TOO_SOON", "body.visitDate", "visit date is sooner than the minimum lead time")
+	ErrDateTooFar          = errcode.New("DATE_TOO_FAR", "body.visitDate", "visit date is beyond the booking horizon")
+	ErrSameDayCutoff       = errcode.New("SAME_DAY_CUTOFF", "body.visitDate", "bookings for today have closed")
+	ErrNoticeTooShort      = errcode.New("NOTICE_TOO_SHORT", "body.startTime", "start time is sooner than the minimum notice")
+	ErrDateIsHoliday       = errcode.New("DATE_IS_HOLIDAY", "body.visitDate", "visit date is a public holiday")
+	ErrOfficeClosed        = errcode.New("OFFICE_CLOSED", "body.visitDate", "office is closed on the visit date")
+	ErrOfficeClosedForSlot = errcode.New("OFFICE_CLOSED", "body.startTime", "office is closed from midday on the visit date")
+	// the office does not open on this day of the week; the code predates configurable schedules
+	ErrClosedWeekday       = errcode.New("DATE_IS_WEEKEND", "body.visitDate", "office is not open on this day of the week").Under(ErrOfficeClosed)
+	ErrOutsideOpeningHours = errcode.New("OUTSIDE_OPENING_HOURS", "body.startTime", "start time is outside opening hours or not on a slot boundary")
+	ErrInvalidInput        = errcode.New("INVALID_INPUT", "", "invalid input data")
+	ErrInvalidDateRange    = errcode.New("INVALID_DATE_RANGE", "query.from", "start date must not be after end date")
+	ErrDateRangeTooLong    = errcode.New("DATE_RANGE_TOO_LONG", "query.to", "date range is too long")
+	ErrDateOutOfRange      = errcode.New("DATE_OUT_OF_RANGE", "query.from", "date is too far from today")
+	ErrNoWorkingDay        = errcode.New("NO_WORKING_DAY", "query.from", "no working day within a year")
+	ErrYearOutOfRange      = errcode.New("YEAR_OUT_OF_RANGE", "query.year", "year is outside the years holidays are listed for")
+	ErrAppointmentInPast   = errcode.New("APPOINTMENT_IN_PAST", "path.id", "appointment date has already passed")
+
+	ErrHolidayDataUnavailable = errcode.New("HOLIDAY_DATA_UNAVAILABLE", "", "public holiday data is currently unavailable")
+	// not a rejection: the date passed every other rule but could not be checked for holidays
+	ErrHolidayUnverified = errcode.New("HOLIDAY_UNVERIFIED", "body.visitDate", "visit date could not be checked against public holidays")
 )
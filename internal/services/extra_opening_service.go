@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+)
+
+// manages extra opening days, on which the weekend and holiday rules do not apply
+type ExtraOpeningService struct {
+	openings database.ExtraOpeningRepository
+	hours    OpeningHours // slot length that the hours of an extra opening must fit
+	logger   *slog.Logger
+}
+
+func NewExtraOpeningService(openings database.ExtraOpeningRepository, hours OpeningHours, logger *slog.Logger) *ExtraOpeningService {
+	return &ExtraOpeningService{
+		openings: openings,
+		hours:    hours,
+		logger:   logger,
+	}
+}
+
+type ExtraOpeningRequest struct {
+	StartDate apiModels.Date      `json:"startDate"`
+	EndDate   apiModels.Date      `json:"endDate"` // defaults to StartDate
+	Reason    string              `json:"reason"`
+	Opens     apiModels.TimeOfDay `json:"opens"`
+	Closes    apiModels.TimeOfDay `json:"closes"`
+	Capacity  int                 `json:"capacity"`
+}
+
+// returns the opening hours in force on an extra opening day: its own times, divided into the usual slots
+func extraOpeningHours(base OpeningHours, opening *dbModels.ExtraOpening) OpeningHours {
+	return OpeningHours{
+		Opens:      opening.Opens,
+		Closes:     opening.Closes,
+		SlotLength: base.SlotLength,
+	}
+}
+
+// checks an extra opening request and turns it into an extra opening, filling in the end date of a single day
+func (s *ExtraOpeningService) toExtraOpening(req *ExtraOpeningRequest) (*dbModels.ExtraOpening, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		s.logger.Warn("Invalid input: missing extra opening reason")
+		return nil, ErrInvalidInput.At("body.reason")
+	}
+
+	end, err := calendarEntryEnd(req.StartDate, req.EndDate)
+	if err != nil {
+		s.logger.Warn("Invalid extra opening dates",
+			"error", err,
+			"start_date", req.StartDate.String(),
+			"end_date", req.EndDate.String())
+		return nil, err
+	}
+
+	opening := &dbModels.ExtraOpening{
+		StartDate: req.StartDate,
+		EndDate:   end,
+		Reason:    reason,
+		Opens:     req.Opens,
+		Closes:    req.Closes,
+		Capacity:  req.Capacity,
+	}
+
+	if err := extraOpeningHours(s.hours, opening).Validate(); err != nil {
+		s.logger.Warn("Invalid extra opening hours",
+			"error", err,
+			"opens", req.Opens.String(),
+			"closes", req.Closes.String())
+		return nil, ErrInvalidInput.At("body.closes")
+	}
+	if req.Capacity < 1 {
+		s.logger.Warn("Invalid extra opening capacity", "capacity", req.Capacity)
+		return nil, ErrInvalidInput.At("body.capacity")
+	}
+
+	return opening, nil
+}
+
+// records an extra opening
+func (s *ExtraOpeningService) CreateExtraOpening(ctx context.Context, req *ExtraOpeningRequest) (*dbModels.ExtraOpening, error) {
+	s.logger.Info("Creating extra opening",
+		"start_date", req.StartDate.String(),
+		"end_date", req.EndDate.String(),
+		"opens", req.Opens.String(),
+		"closes", req.Closes.String(),
+		"capacity", req.Capacity)
+
+	opening, err := s.toExtraOpening(req)
+	if err != nil {
+		return nil, err
+	}
+	// the repository checks for overlapping openings in the same transaction as the insert
+	if err := s.openings.Create(ctx, opening); err != nil {
+		if !errors.Is(err, database.ErrExtraOpeningOverlap) {
+			s.logger.Error("Failed to create extra opening", "error", err)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Extra opening created successfully", "id", opening.ID)
+	return opening, nil
+}
+
+// retrieves a single extra opening by ID
+func (s *ExtraOpeningService) GetExtraOpening(ctx context.Context, id uint) (*dbModels.ExtraOpening, error) {
+	opening, err := s.openings.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, database.ErrExtraOpeningNotFound) {
+			s.logger.Error("Failed to get extra opening", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return opening, nil
+}
+
+// returns the extra openings covering any date from..to (inclusive), zero values leave the range open
+func (s *ExtraOpeningService) ListExtraOpenings(ctx context.Context, from, to apiModels.Date) ([]dbModels.ExtraOpening, error) {
+	if !from.IsZero() && !to.IsZero() && from.After(to.Time) {
+		s.logger.Warn("Invalid date range",
+			"from", from.String(),
+			"to", to.String())
+		return nil, ErrInvalidDateRange
+	}
+
+	openings, err := s.openings.ListOverlapping(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Failed to list extra openings", "error", err)
+		return nil, err
+	}
+	return openings, nil
+}
+
+// replaces the dates, reason, hours and capacity of an extra opening
+func (s *ExtraOpeningService) UpdateExtraOpening(ctx context.Context, id uint, req *ExtraOpeningRequest) (*dbModels.ExtraOpening, error) {
+	s.logger.Info("Updating extra opening",
+		"id", id,
+		"start_date", req.StartDate.String(),
+		"end_date", req.EndDate.String(),
+		"opens", req.Opens.String(),
+		"closes", req.Closes.String(),
+		"capacity", req.Capacity)
+
+	opening, err := s.toExtraOpening(req)
+	if err != nil {
+		return nil, err
+	}
+	opening.ID = id
+	if err := s.openings.Update(ctx, opening); err != nil {
+		if !errors.Is(err, database.ErrExtraOpeningNotFound) && !errors.Is(err, database.ErrExtraOpeningOverlap) {
+			s.logger.Error("Failed to update extra opening", "error", err, "id", id)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Extra opening updated successfully", "id", id)
+	return s.openings.GetByID(ctx, id)
+}
+
+// removes an extra opening, so its dates follow the weekend and holiday rules again
+func (s *ExtraOpeningService) DeleteExtraOpening(ctx context.Context, id uint) error {
+	if err := s.openings.Delete(ctx, id); err != nil {
+		if !errors.Is(err, database.ErrExtraOpeningNotFound) {
+			s.logger.Error("Failed to delete extra opening", "error", err, "id", id)
+		}
+		return err
+	}
+
+	s.logger.Info("Extra opening deleted successfully", "id", id)
+	return nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// what to do with a booking when public holidays cannot be looked up
+type HolidayFailPolicy string
+
+const (
+	// reject the booking with ErrHolidayDataUnavailable
+	HolidayFailClosed HolidayFailPolicy = "closed"
+	// accept the booking and flag it as holiday_unverified for later re-validation
+	HolidayFailOpen HolidayFailPolicy = "open"
+	// use the last known holidays however old they are, rejecting only if there are none
+	HolidayFailStale HolidayFailPolicy = "stale"
+)
+
+// parses a HOLIDAY_FAIL_POLICY value
+func ParseHolidayFailPolicy(policy string) (HolidayFailPolicy, error) {
+	switch parsed := HolidayFailPolicy(strings.ToLower(strings.TrimSpace(policy))); parsed {
+	case HolidayFailClosed, HolidayFailOpen, HolidayFailStale:
+		return parsed, nil
+	default:
+		return "", fmt.Errorf("unknown holiday fail policy %q, expected closed, open or stale", policy)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/pkg/client"
+)
+
+// implemented by holiday services that can re-fetch a year on demand
+type YearRefresher interface {
+	Today() apiModels.Date
+	RefreshYear(ctx context.Context, year int) (*YearRefresh, error)
+}
+
+// outcome of re-fetching one year, both in date order
+type YearRefresh struct {
+	Added   []client.Holiday // observed holidays the copy known before did not have
+	Closing []client.Holiday // every observed holiday that no extra opening lifts
+}
+
+// re-fetches the current and next year's holidays on a schedule, so holidays published late are picked up,
+// and reports existing appointments that a newly published holiday lands on
+type HolidayRefresher struct {
+	repo     database.AppointmentRepository
+	holidays YearRefresher
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// outcome of one refresh pass
+type RefreshReport struct {
+	Years     []int                  // years fetched successfully
+	Added     []client.Holiday       // holidays that were not known before the pass
+	Conflicts []dbModels.Appointment // active upcoming appointments found on a holiday during the pass
+}
+
+func NewHolidayRefresher(repo database.AppointmentRepository, holidays YearRefresher, interval time.Duration, logger *slog.Logger) *HolidayRefresher {
+	return &HolidayRefresher{
+		repo:     repo,
+		holidays: holidays,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// refreshes straight away, which prefetches next year at startup, then every interval until ctx is cancelled
+func (r *HolidayRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RefreshOnce(ctx); err != nil {
+			r.logger.Error("Holiday refresh failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// re-fetches the current and next year; a year that cannot be fetched keeps its cached copy and is
+// reported in the error, while the other year is still refreshed. Every upcoming holiday is checked
+// against the appointments, not only newly published ones, so conflicts are found even when the year
+// was not known before. Conflicting appointments are marked holidayConflict and reported, not
+// cancelled, so staff can contact the citizen; marked ones are not reported again.
+func (r *HolidayRefresher) RefreshOnce(ctx context.Context) (*RefreshReport, error) {
+	report := &RefreshReport{}
+	today := r.holidays.Today()
+	thisYear := today.Year()
+
+	var errs []error
+	for _, year := range []int{thisYear, thisYear + 1} {
+		refresh, err := r.holidays.RefreshYear(ctx, year)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report.Years = append(report.Years, year)
+
+		for _, holiday := range refresh.Added {
+			r.logger.Info("New public holiday published",
+				"date", holiday.Date,
+				"name", holiday.Name)
+			report.Added = append(report.Added, holiday)
+		}
+
+		for _, holiday := range refresh.Closing {
+			if holiday.Date < today.String() {
+				continue
+			}
+			conflicts, err := r.conflictsWith(ctx, holiday)
+			report.Conflicts = append(report.Conflicts, conflicts...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	r.logger.Info("Holiday refresh finished",
+		"years", report.Years,
+		"added", len(report.Added),
+		"conflicts", len(report.Conflicts))
+
+	return report, errors.Join(errs...)
+}
+
+// marks and logs the active appointments on a holiday's date that are not marked yet
+func (r *HolidayRefresher) conflictsWith(ctx context.Context, holiday client.Holiday) ([]dbModels.Appointment, error) {
+	day, err := time.Parse("2006-01-02", holiday.Date)
+	if err != nil {
+		return nil, err
+	}
+	date := apiModels.Date{Time: day}
+
+	appointments, _, err := r.repo.List(ctx, database.AppointmentFilter{From: date, To: date})
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []dbModels.Appointment
+	for _, appointment := range appointments {
+		if appointment.HolidayConflict {
+			continue
+		}
+		if err := r.repo.MarkHolidayVerified(ctx, appointment.ID, true); err != nil {
+			return conflicts, err
+		}
+		appointment.HolidayUnverified = false
+		appointment.HolidayConflict = true
+
+		r.logger.Warn("Appointment falls on a public holiday",
+			"id", appointment.ID,
+			"reference", appointment.Reference,
+			"visit_date", appointment.VisitDate.String(),
+			"start_time", appointment.StartTime.String(),
+			"holiday", holiday.Name)
+		conflicts = append(conflicts, appointment)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+)
+
+// re-checks appointments accepted under the open fail policy once holiday data is available again
+type HolidayRevalidator struct {
+	repo           database.AppointmentRepository
+	holidayService HolidayServiceInterface
+	interval       time.Duration
+	logger         *slog.Logger
+}
+
+// outcome of one revalidation pass
+type RevalidationReport struct {
+	Checked   int                    // flagged appointments whose date could be checked
+	Remaining int                    // flagged appointments left for a later pass
+	OnHoliday []dbModels.Appointment // checked appointments that turned out to fall on a public holiday
+}
+
+func NewHolidayRevalidator(repo database.AppointmentRepository, holidayService HolidayServiceInterface, interval time.Duration, logger *slog.Logger) *HolidayRevalidator {
+	return &HolidayRevalidator{
+		repo:           repo,
+		holidayService: holidayService,
+		interval:       interval,
+		logger:         logger,
+	}
+}
+
+// runs a revalidation pass every interval until ctx is cancelled
+func (r *HolidayRevalidator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RevalidateOnce(ctx); err != nil {
+				r.logger.Error("Holiday revalidation failed", "error", err)
+			}
+		}
+	}
+}
+
+// checks every flagged appointment against the public holidays and clears its flag; appointments
+// that land on a holiday are marked holidayConflict and reported, not cancelled, so staff can contact the citizen.
+// A holiday covered by an extra opening is no conflict, as it is not for new bookings.
+// A pass stops early while the holiday provider is still unavailable.
+func (r *HolidayRevalidator) RevalidateOnce(ctx context.Context) (*RevalidationReport, error) {
+	flagged, err := r.repo.ListHolidayUnverified(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RevalidationReport{Remaining: len(flagged)}
+	if len(flagged) == 0 {
+		return report, nil
+	}
+
+	r.logger.Info("Revalidating holiday-unverified appointments", "count", len(flagged))
+
+	for _, appointment := range flagged {
+		isHoliday, err := r.closedForHoliday(ctx, appointment.VisitDate)
+		if err != nil {
+			r.logger.Warn("Holiday data still unavailable, postponing revalidation",
+				"error", err,
+				"remaining", report.Remaining)
+			break
+		}
+
+		if err := r.repo.MarkHolidayVerified(ctx, appointment.ID, isHoliday); err != nil {
+			return report, err
+		}
+		appointment.HolidayUnverified = false
+		appointment.HolidayConflict = isHoliday
+
+		if isHoliday {
+			r.logger.Warn("Appointment booked on a public holiday",
+				"id", appointment.ID,
+				"reference", appointment.Reference,
+				"visit_date", appointment.VisitDate.String(),
+				"start_time", appointment.StartTime.String())
+			report.OnHoliday = append(report.OnHoliday, appointment)
+		}
+		report.Checked++
+		report.Remaining--
+	}
+
+	r.logger.Info("Holiday revalidation finished",
+		"checked", report.Checked,
+		"on_holiday", len(report.OnHoliday),
+		"remaining", report.Remaining)
+
+	return report, nil
+}
+
+// reports whether the office is shut for a public holiday on date; like HolidayRule, an extra
+// opening lifts the holiday without it being looked up
+func (r *HolidayRevalidator) closedForHoliday(ctx context.Context, date apiModels.Date) (bool, error) {
+	opening, err := r.holidayService.ExtraOpeningOn(ctx, date)
+	if err != nil {
+		return false, err
+	}
+	if opening != nil {
+		return false, nil
+	}
+	return r.holidayService.IsPublicHoliday(ctx, date)
+}
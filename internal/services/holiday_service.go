@@ -2,127 +2,778 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/pkg/businessday"
 	"citynext/pkg/client"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // interface for holiday service operations
 type HolidayServiceInterface interface {
 	IsPublicHoliday(ctx context.Context, date apiModels.Date) (bool, error)
 	ValidateDate(ctx context.Context, date apiModels.Date) error
+	ValidateSlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) error
+	CheckDates(ctx context.Context, from, to apiModels.Date) ([]DateCheck, error)
+	// reports every calendar rule a date, or a slot when start is set, breaks; FailFast stops at the first
+	CheckRules(ctx context.Context, date apiModels.Date, start *apiModels.TimeOfDay, mode RuleMode) (*RuleResult, error)
+	// returns the extra opening covering a date, whose capacity replaces the daily capacity, or nil
+	ExtraOpeningOn(ctx context.Context, date apiModels.Date) (*dbModels.ExtraOpening, error)
+	OpeningHours() OpeningHours
+}
+
+// outcome of the calendar rules for a single date
+type DateCheck struct {
+	Date       apiModels.Date
+	Err        error                  // nil when the date passes, otherwise the error ValidateDate would return
+	Violations []Violation            // every calendar rule the date breaks, in rule order; Err is the first
+	Holiday    *client.Holiday        // set when the date is a holiday the office observes and no extra opening lifts it
+	Closure    *dbModels.Closure      // the closure deciding the date, full-day or half-day
+	Opening    *dbModels.ExtraOpening // set when an extra opening lifts the weekday and holiday rules
+	Hours      OpeningHours           // opening hours in force on the date
+	HalfDay    apiModels.TimeOfDay    // when a half-day closure shuts the office
+	Unverified bool                   // set when the date passes only because holidays could not be looked up
+}
+
+// how long a loaded year is trusted before it is fetched again
+const DefaultHolidayCacheTTL = 7 * 24 * time.Hour
+
+// every holiday of one year that the office observes; a date missing from a loaded year is known not to be a holiday
+type holidayYear struct {
+	holidays  map[string]client.Holiday // date -> holiday
+	fetchedAt time.Time
 }
 
 type HolidayService struct {
-	client *client.HolidayClient
-	cache  map[int]map[string]bool // year -> date -> isHoliday
-	mutex  sync.RWMutex
-	logger *slog.Logger
+	provider   client.HolidayProvider
+	source     string                          // provenance recorded on stored holidays
+	store      database.HolidayRepository      // optional, keeps holidays across restarts and upstream outages
+	closures   database.ClosureRepository      // optional, office closures managed by staff
+	openings   database.ExtraOpeningRepository // optional, extra opening days managed by staff
+	cache      map[int]*holidayYear            // year -> holidays, for fully loaded years only
+	cacheTTL   time.Duration
+	failPolicy HolidayFailPolicy
+	region     OfficeRegion
+	mutex      sync.RWMutex
+	fetches    singleflight.Group    // keyed by year
+	hours      OpeningHours          // standard hours; their slot length also applies to extra openings
+	halfDay    apiModels.TimeOfDay   // when a half-day closure shuts the office
+	schedule   WeeklySchedule        // open days of the week and their hours; Monday to Friday with hours if unset
+	location   *time.Location        // office time zone, whose calendar decides which dates and slots are in the past
+	business   *businessday.Calendar // open days of the week minus observed holidays
+	window     BookingWindow
+	rules      Pipeline // calendar rules, in the order they run
+	clock      Clock
+	logger     *slog.Logger
 }
 
-func NewHolidayService(baseURL string, logger *slog.Logger) HolidayServiceInterface {
-	return &HolidayService{
-		client: client.NewHolidayClient(baseURL, logger),
-		cache:  make(map[int]map[string]bool),
-		logger: logger,
+// configures optional HolidayService behaviour
+type HolidayServiceOption func(*HolidayService)
+
+// sets the opening hours used to validate appointment slots
+func WithOpeningHours(hours OpeningHours) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.hours = hours
 	}
 }
 
-func (s *HolidayService) IsPublicHoliday(ctx context.Context, date apiModels.Date) (bool, error) {
-	year := date.Time.Year()
-	dateStr := date.String()
+// sets which days of the week the office opens and its hours on each of them
+func WithWeeklySchedule(schedule WeeklySchedule) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.schedule = schedule
+	}
+}
+
+// sets when the office shuts on a half-day closure
+func WithHalfDayClosingTime(closes apiModels.TimeOfDay) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.halfDay = closes
+	}
+}
+
+// persists fetched holidays, preloads them into the cache and falls back to them when the API fails
+func WithHolidayStore(store database.HolidayRepository) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.store = store
+	}
+}
+
+// checks dates against the office closures managed by staff
+func WithClosures(closures database.ClosureRepository) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.closures = closures
+	}
+}
+
+// lets extra opening days override the weekend and holiday rules, with their own hours
+func WithExtraOpenings(openings database.ExtraOpeningRepository) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.openings = openings
+	}
+}
+
+// sets where the office is, and so which regional holidays close it
+func WithOfficeRegion(region OfficeRegion) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.region = region
+	}
+}
+
+// sets the office time zone used to decide which day it is
+func WithOfficeLocation(location *time.Location) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.location = location
+	}
+}
+
+// sets where the current time comes from, so tests can pin it
+func WithClock(clock Clock) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.clock = clock
+	}
+}
+
+// limits how soon and how far ahead appointments can be booked
+func WithBookingWindow(window BookingWindow) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.window = window
+	}
+}
+
+// replaces the calendar rules dates and slots are judged by, and their order; see CalendarRules for the defaults
+func WithCalendarRules(rules Pipeline) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.rules = rules
+	}
+}
+
+// sets how long a loaded year is used before it is fetched again
+func WithHolidayCacheTTL(ttl time.Duration) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.cacheTTL = ttl
+	}
+}
+
+// sets what happens to a booking when holidays cannot be looked up
+func WithHolidayFailPolicy(policy HolidayFailPolicy) HolidayServiceOption {
+	return func(s *HolidayService) {
+		s.failPolicy = policy
+	}
+}
+
+func NewHolidayService(provider client.HolidayProvider, logger *slog.Logger, opts ...HolidayServiceOption) HolidayServiceInterface {
+	s := &HolidayService{
+		provider:   provider,
+		source:     provider.Name(),
+		cache:      make(map[int]*holidayYear),
+		cacheTTL:   DefaultHolidayCacheTTL,
+		failPolicy: HolidayFailClosed,
+		region:     DefaultOfficeRegion(),
+		hours:      DefaultOpeningHours(),
+		halfDay:    DefaultHalfDayClosingTime(),
+		location:   DefaultOfficeLocation(),
+		clock:      time.Now,
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.schedule == nil {
+		s.schedule = WeekdaySchedule(s.hours)
+	}
+	if s.rules == nil {
+		s.rules = CalendarRules(s.schedule, s.window, s.failPolicy)
+	}
+	s.business = businessday.New(s.schedule.OpenDays(), func(ctx context.Context, date time.Time) (bool, error) {
+		return s.IsPublicHoliday(ctx, apiModels.Date{Time: date})
+	})
+	if s.store != nil {
+		s.loadStoredHolidays(context.Background())
+	}
+	return s
+}
+
+// fills the cache with every year found in the store; a failure only means the API is asked instead.
+// Stored years keep their fetch time, so one older than the TTL is fetched again on first use and
+// only serves as the fallback while the API fails.
+func (s *HolidayService) loadStoredHolidays(ctx context.Context) {
+	stored, err := s.store.ListAll(ctx, s.region.CountryCode)
+	if err != nil {
+		s.logger.Warn("Failed to load stored holidays", "error", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for year, loaded := range groupStoredHolidays(stored, s.region) {
+		s.cache[year] = loaded
+	}
+
+	s.logger.Info("Loaded stored holidays",
+		"holidays_count", len(stored),
+		"years_count", len(s.cache))
+}
+
+// implemented by holiday services that can tell whether their holiday provider is reachable
+type ProviderStater interface {
+	ProviderState() client.CircuitState
+}
+
+// reports the holiday provider's circuit breaker state; anything but closed means holiday
+// lookups are served from the cache and the store only
+func (s *HolidayService) ProviderState() client.CircuitState {
+	if stater, ok := s.provider.(client.CircuitStater); ok {
+		return stater.CircuitState()
+	}
+	return client.CircuitClosed
+}
+
+// returns the current date in the office time zone
+func (s *HolidayService) Today() apiModels.Date {
+	return apiModels.DateOf(s.clock(), s.location)
+}
 
+// reports whether a loaded year is recent enough to be used without asking the API
+func (s *HolidayService) isFresh(loaded *holidayYear) bool {
+	return s.clock().Sub(loaded.fetchedAt) < s.cacheTTL
+}
+
+func (s *HolidayService) IsPublicHoliday(ctx context.Context, date apiModels.Date) (bool, error) {
 	s.logger.Debug("Checking if date is public holiday",
-		"date", dateStr,
-		"year", year)
+		"date", date.String(),
+		"year", date.Year())
+
+	holidays, err := s.holidaysForYear(ctx, date.Year())
+	if err != nil {
+		return false, err
+	}
 
-	// Check cache first
+	_, isHoliday := holidays[date.String()]
+	return isHoliday, nil
+}
+
+// returns the holidays for a year, fetching them only if the year is not loaded or has expired
+func (s *HolidayService) holidaysForYear(ctx context.Context, year int) (map[string]client.Holiday, error) {
 	s.mutex.RLock()
-	if yearCache, exists := s.cache[year]; exists {
-		if isHoliday, found := yearCache[dateStr]; found {
-			s.logger.Debug("Cache hit for holiday check",
-				"date", dateStr,
-				"is_holiday", isHoliday)
-			s.mutex.RUnlock()
-			return isHoliday, nil
+	loaded, exists := s.cache[year]
+	s.mutex.RUnlock()
+
+	if exists && s.isFresh(loaded) {
+		s.logger.Debug("Cache hit for holidays", "year", year)
+		return loaded.holidays, nil
+	}
+
+	s.logger.Debug("Cache miss for holidays, fetching from API",
+		"year", year,
+		"expired", exists)
+
+	loaded, err := s.fetchYear(ctx, year)
+	if err != nil {
+		return s.lastKnownYear(ctx, year, err)
+	}
+	return loaded.holidays, nil
+}
+
+// fetches a year from the API and replaces the cached copy; concurrent fetches of the same year,
+// whether lookups or refreshes, share a single upstream call
+func (s *HolidayService) fetchYear(ctx context.Context, year int) (*holidayYear, error) {
+	loaded, err, shared := s.fetches.Do(strconv.Itoa(year), func() (any, error) {
+		// detached from the caller, whose cancellation must not fail the requests sharing this call
+		return s.replaceYear(context.WithoutCancel(ctx), year)
+	})
+	if shared {
+		s.logger.Debug("Shared in-flight holiday fetch", "year", year)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return loaded.(*holidayYear), nil
+}
+
+// re-fetches a year however fresh its cached copy is. Holidays count as added when they are missing
+// from the copy known before, cached or stored; with no earlier copy nothing counts as added, but
+// every holiday still closing the office is returned so existing appointments can be checked against
+// it. A failed fetch keeps the cached copy.
+func (s *HolidayService) RefreshYear(ctx context.Context, year int) (*YearRefresh, error) {
+	previous := s.knownYear(ctx, year)
+
+	loaded, err := s.fetchYear(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(loaded.holidays))
+	for date := range loaded.holidays {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	refresh := &YearRefresh{}
+	for _, date := range dates {
+		holiday := loaded.holidays[date]
+		if previous != nil {
+			if _, known := previous.holidays[date]; !known {
+				refresh.Added = append(refresh.Added, holiday)
+			}
+		}
+
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			s.logger.Warn("Skipping holiday with invalid date", "error", err, "date", date)
+			continue
+		}
+		// an extra opening lifts the holiday, so appointments on it stand
+		opening, err := s.ExtraOpeningOn(ctx, apiModels.Date{Time: day})
+		if err != nil {
+			return nil, err
+		}
+		if opening == nil {
+			refresh.Closing = append(refresh.Closing, holiday)
 		}
 	}
+	return refresh, nil
+}
+
+// returns the copy of a year known before fetching it: the cached one, or else the stored one
+func (s *HolidayService) knownYear(ctx context.Context, year int) *holidayYear {
+	s.mutex.RLock()
+	known := s.cache[year]
 	s.mutex.RUnlock()
+	if known != nil || s.store == nil {
+		return known
+	}
 
-	s.logger.Debug("Cache miss for holiday check, fetching from API", "date", dateStr)
+	stored, err := s.store.ListByYear(ctx, s.region.CountryCode, year)
+	if err != nil {
+		s.logger.Warn("Failed to load stored holidays", "error", err, "year", year)
+		return nil
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+	return groupStoredHolidays(stored, s.region)[year]
+}
 
-	// Fetch holidays for the year
-	holidays, err := s.client.GetPublicHolidays(ctx, year, "GB")
+// fetches the holidays for a year from the API, then caches and stores them
+func (s *HolidayService) replaceYear(ctx context.Context, year int) (*holidayYear, error) {
+	holidays, err := s.provider.GetPublicHolidays(ctx, year, s.region.CountryCode)
 	if err != nil {
 		s.logger.Error("Failed to fetch holidays",
 			"error", err,
 			"year", year)
-		return false, err
+		return nil, err
+	}
+	// the cache and the store hold one entry per date, whatever the provider sends
+	holidays = client.MergeByDate(holidays)
+
+	loaded := &holidayYear{
+		holidays:  make(map[string]client.Holiday, len(holidays)),
+		fetchedAt: s.clock().UTC(),
+	}
+	// every holiday is stored, but only those the office observes close it
+	for _, holiday := range holidays {
+		if s.region.Observes(holiday) {
+			loaded.holidays[holiday.Date] = holiday
+		}
 	}
 
-	// Build cache for the year
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	s.cache[year] = loaded
+	s.mutex.Unlock()
+
+	s.storeYear(ctx, year, holidays, loaded.fetchedAt)
 
-	if s.cache[year] == nil {
-		s.cache[year] = make(map[string]bool)
+	s.logger.Info("Updated holiday cache",
+		"year", year,
+		"region", s.region.String(),
+		"holidays_count", len(holidays),
+		"observed_count", len(loaded.holidays))
+
+	return loaded, nil
+}
+
+// saves a freshly fetched year; failing to save is logged but does not fail the lookup
+func (s *HolidayService) storeYear(ctx context.Context, year int, holidays []client.Holiday, fetchedAt time.Time) {
+	if s.store == nil {
+		return
 	}
 
-	// Populate cache and check the specific date
-	isHoliday := false
+	stored := make([]dbModels.Holiday, 0, len(holidays))
 	for _, holiday := range holidays {
-		s.cache[year][holiday.Date] = true
-		if holiday.Date == dateStr {
-			isHoliday = true
+		record, err := toStoredHoliday(holiday, s.region.CountryCode, s.source, fetchedAt)
+		if err != nil {
+			s.logger.Warn("Skipping holiday with invalid date",
+				"error", err,
+				"date", holiday.Date,
+				"name", holiday.Name)
+			continue
 		}
+		stored = append(stored, record)
 	}
 
-	s.logger.Info("Updated holiday cache",
+	if err := s.store.ReplaceYear(ctx, s.region.CountryCode, year, stored); err != nil {
+		s.logger.Warn("Failed to store fetched holidays",
+			"error", err,
+			"year", year)
+	}
+}
+
+// answers a failed fetch with the newest cached or stored copy of the year: a copy within
+// the TTL is always used, an older one only under the stale policy; otherwise fetchErr is returned
+func (s *HolidayService) lastKnownYear(ctx context.Context, year int, fetchErr error) (map[string]client.Holiday, error) {
+	s.mutex.RLock()
+	last := s.cache[year]
+	s.mutex.RUnlock()
+
+	// another instance sharing the store may have fetched the year more recently
+	if s.store != nil {
+		stored, err := s.store.ListByYear(ctx, s.region.CountryCode, year)
+		if err == nil && len(stored) > 0 {
+			if fromStore := groupStoredHolidays(stored, s.region)[year]; last == nil || fromStore.fetchedAt.After(last.fetchedAt) {
+				last = fromStore
+			}
+		}
+	}
+
+	if last == nil {
+		return nil, fetchErr
+	}
+	if !s.isFresh(last) && s.failPolicy != HolidayFailStale {
+		s.logger.Warn("Last known holidays are too old to use",
+			"year", year,
+			"fetched_at", last.fetchedAt,
+			"policy", s.failPolicy)
+		return nil, fetchErr
+	}
+
+	s.mutex.Lock()
+	s.cache[year] = last
+	s.mutex.Unlock()
+
+	s.logger.Warn("Using last known holidays after failed fetch",
 		"year", year,
-		"holidays_count", len(holidays),
-		"date", dateStr,
-		"is_holiday", isHoliday)
+		"holidays_count", len(last.holidays),
+		"fetched_at", last.fetchedAt,
+		"stale", !s.isFresh(last))
 
-	return isHoliday, nil
+	return last.holidays, nil
 }
 
-func (s *HolidayService) ValidateDate(ctx context.Context, visitDate apiModels.Date) error {
-	s.logger.Debug("Validating appointment date", "date", visitDate.String())
+// returns the public holiday the office observes on a date, or nil when the date is not one
+func (s *HolidayService) holidayOn(ctx context.Context, date apiModels.Date) (*client.Holiday, error) {
+	holidays, err := s.holidaysForYear(ctx, date.Year())
+	if err != nil {
+		return nil, err
+	}
+	if holiday, found := holidays[date.String()]; found {
+		return &holiday, nil
+	}
+	return nil, nil
+}
+
+// returns the extra opening covering a date, or nil when there is none
+func (s *HolidayService) ExtraOpeningOn(ctx context.Context, date apiModels.Date) (*dbModels.ExtraOpening, error) {
+	if s.openings == nil {
+		return nil, nil
+	}
 
-	// Check if date is in the past
-	now := time.Now().UTC().Truncate(24 * time.Hour)
-	date := visitDate.Time.UTC().Truncate(24 * time.Hour)
-	if date.Before(now) {
-		s.logger.Warn("Attempted to book appointment in the past", "date", visitDate.String())
-		return ErrDateInPast
+	openings, err := s.openings.ListOverlapping(ctx, date, date)
+	if err != nil {
+		s.logger.Error("Failed to look up extra openings",
+			"error", err,
+			"date", date.String())
+		return nil, err
 	}
+	return coveringOpening(openings, date), nil
+}
+
+func coveringOpening(openings []dbModels.ExtraOpening, date apiModels.Date) *dbModels.ExtraOpening {
+	for i := range openings {
+		if openings[i].Covers(date) {
+			return &openings[i]
+		}
+	}
+	return nil
+}
+
+// returns the opening hours in force on a date with the given extra opening, which may be nil;
+// the standard hours stand in on days the office is closed
+func (s *HolidayService) hoursOn(date apiModels.Date, opening *dbModels.ExtraOpening) OpeningHours {
+	if opening != nil {
+		return extraOpeningHours(s.hours, opening)
+	}
+	if hours, open := s.schedule.HoursOn(date.Weekday()); open {
+		return hours
+	}
+	return s.hours
+}
 
-	// Check if date is a weekend (Saturday = 6, Sunday = 0)
-	weekday := date.Weekday()
-	if weekday == time.Saturday || weekday == time.Sunday {
-		s.logger.Warn("Attempted to book appointment on weekend",
-			"date", visitDate.String(),
-			"weekday", weekday.String())
-		return ErrDateIsWeekend
+// returns the earliest date the minimum lead time allows, counting working days from today;
+// without a lead time that is today
+func (s *HolidayService) EarliestBookableDate(ctx context.Context) (apiModels.Date, error) {
+	today := s.Today()
+	if s.window.MinWorkingDays <= 0 {
+		return today, nil
 	}
 
-	// Check if date is a public holiday
-	isHoliday, err := s.IsPublicHoliday(ctx, visitDate)
+	earliest, err := s.business.AddWorkingDays(ctx, today.Time, s.window.MinWorkingDays)
 	if err != nil {
-		s.logger.Error("Failed to check if date is holiday",
+		return apiModels.Date{}, err
+	}
+	return apiModels.Date{Time: earliest}, nil
+}
+
+// returns the closures covering a date, or none when closures are not configured
+func (s *HolidayService) closuresOn(ctx context.Context, date apiModels.Date) ([]dbModels.Closure, error) {
+	if s.closures == nil {
+		return nil, nil
+	}
+	return s.closures.ListOverlapping(ctx, date, date)
+}
+
+// picks the closure that decides a date: a full-day closure wins over a half-day one
+func decidingClosure(closures []dbModels.Closure, date apiModels.Date) *dbModels.Closure {
+	var halfDay *dbModels.Closure
+	for i := range closures {
+		if !closures[i].Covers(date) {
+			continue
+		}
+		if !closures[i].HalfDay {
+			return &closures[i]
+		}
+		if halfDay == nil {
+			halfDay = &closures[i]
+		}
+	}
+	return halfDay
+}
+
+// gathers what the calendar rules judge a date, or a slot when start is set, by;
+// holidays and the lead time are only looked up if a rule gets that far
+func (s *HolidayService) ruleInput(ctx context.Context, date apiModels.Date, start *apiModels.TimeOfDay) (*RuleInput, error) {
+	opening, err := s.ExtraOpeningOn(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.closuresOn(ctx, date)
+	if err != nil {
+		s.logger.Error("Failed to look up office closures",
 			"error", err,
-			"date", visitDate.String())
-		return err
+			"date", date.String())
+		return nil, err
 	}
 
-	if isHoliday {
-		s.logger.Warn("Attempted to book appointment on public holiday", "date", visitDate.String())
-		return ErrDateIsHoliday
+	return &RuleInput{
+		Date:    date,
+		Start:   start,
+		Now:     s.clock().In(s.location),
+		Opening: opening,
+		Closure: decidingClosure(closures, date),
+		Hours:   s.hoursOn(date, opening),
+		Holiday: func(ctx context.Context) (*client.Holiday, error) {
+			return s.holidayOn(ctx, date)
+		},
+		HalfDayCloses: s.halfDay,
+		Earliest:      s.EarliestBookableDate,
+	}, nil
+}
+
+// runs the calendar rules on a date, or on a slot when start is set
+func (s *HolidayService) CheckRules(ctx context.Context, date apiModels.Date, start *apiModels.TimeOfDay, mode RuleMode) (*RuleResult, error) {
+	in, err := s.ruleInput(ctx, date, start)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.rules.Run(ctx, in, mode)
+	if err != nil {
+		if errors.Is(err, ErrHolidayDataUnavailable) {
+			s.logger.Error("Rejecting date without holiday check",
+				"date", date.String(),
+				"policy", s.failPolicy,
+				"provider_state", s.ProviderState().String())
+		}
+		return nil, err
+	}
+
+	if len(result.Violations) > 0 {
+		s.logger.Warn("Attempted to book appointment against calendar rules",
+			"error", result.Err(),
+			"rule", result.Violations[0].Rule,
+			"violations", len(result.Violations),
+			"date", date.String(),
+			"weekday", date.Weekday().String())
+	} else if result.HolidayUnverified {
+		s.logger.Warn("Accepting date without holiday check",
+			"date", date.String(),
+			"provider_state", s.ProviderState().String())
+	}
+	return result, nil
+}
+
+func (s *HolidayService) ValidateDate(ctx context.Context, visitDate apiModels.Date) error {
+	s.logger.Debug("Validating appointment date", "date", visitDate.String())
+
+	return s.validate(ctx, visitDate, nil)
+}
+
+// validates the date like ValidateDate and checks that the slot lies within opening hours and has not started yet
+func (s *HolidayService) ValidateSlot(ctx context.Context, visitDate apiModels.Date, start apiModels.TimeOfDay) error {
+	s.logger.Debug("Validating appointment slot",
+		"date", visitDate.String(),
+		"start_time", start.String())
+
+	return s.validate(ctx, visitDate, &start)
+}
+
+// runs the calendar rules up to the first violation; a date that passes every rule but could
+// not be checked for holidays is reported as ErrHolidayUnverified
+func (s *HolidayService) validate(ctx context.Context, visitDate apiModels.Date, start *apiModels.TimeOfDay) error {
+	result, err := s.CheckRules(ctx, visitDate, start, FailFast)
+	if err != nil {
+		return err
+	}
+	if err := result.Err(); err != nil {
+		return err
+	}
+	if result.HolidayUnverified {
+		return ErrHolidayUnverified
 	}
 
 	s.logger.Debug("Date validation passed", "date", visitDate.String())
 	return nil
 }
+
+// applies the ValidateDate rules to every date from..to (inclusive), collecting every rule each date breaks;
+// each year's holidays, the closures and the extra openings are looked up once for the whole range
+func (s *HolidayService) CheckDates(ctx context.Context, from, to apiModels.Date) ([]DateCheck, error) {
+	s.logger.Debug("Checking date range",
+		"from", from.String(),
+		"to", to.String())
+
+	// dates beyond the horizon are rejected before their holidays are needed
+	lastYear := to.Year()
+	if last, limited := s.window.lastBookableDate(s.Today()); limited && last.Before(to.Time) {
+		lastYear = last.Year()
+	}
+
+	holidaysByYear := make(map[int]map[string]client.Holiday)
+	unverifiedYears := make(map[int]error)
+	for year := from.Year(); year <= lastYear; year++ {
+		holidays, err := s.holidaysForYear(ctx, year)
+		if err != nil {
+			if s.failPolicy != HolidayFailOpen {
+				s.logger.Error("Failed to load holidays for date range",
+					"error", err,
+					"year", year,
+					"policy", s.failPolicy)
+				return nil, ErrHolidayDataUnavailable
+			}
+			s.logger.Warn("Checking date range without holidays",
+				"error", err,
+				"year", year)
+			unverifiedYears[year] = err
+			continue
+		}
+		holidaysByYear[year] = holidays
+	}
+
+	earliest, err := s.EarliestBookableDate(ctx)
+	if err != nil {
+		if s.failPolicy != HolidayFailOpen {
+			s.logger.Error("Failed to work out the earliest bookable date", "error", err)
+			return nil, ErrHolidayDataUnavailable
+		}
+		earliest = s.Today()
+	}
+
+	var closures []dbModels.Closure
+	if s.closures != nil {
+		var err error
+		closures, err = s.closures.ListOverlapping(ctx, from, to)
+		if err != nil {
+			s.logger.Error("Failed to look up office closures for date range", "error", err)
+			return nil, err
+		}
+	}
+
+	var openings []dbModels.ExtraOpening
+	if s.openings != nil {
+		var err error
+		openings, err = s.openings.ListOverlapping(ctx, from, to)
+		if err != nil {
+			s.logger.Error("Failed to look up extra openings for date range", "error", err)
+			return nil, err
+		}
+	}
+
+	now := s.clock().In(s.location)
+
+	var checks []DateCheck
+	for day := from.Time; !day.After(to.Time); day = day.AddDate(0, 0, 1) {
+		date := apiModels.Date{Time: day}
+		opening := coveringOpening(openings, date)
+		// years beyond the horizon are never loaded, so their dates count as no holiday
+		holiday, isHoliday := holidaysByYear[day.Year()][date.String()]
+		yearErr := unverifiedYears[day.Year()]
+
+		in := &RuleInput{
+			Date:          date,
+			Now:           now,
+			Opening:       opening,
+			Closure:       decidingClosure(closures, date),
+			Hours:         s.hoursOn(date, opening),
+			HalfDayCloses: s.halfDay,
+			Holiday: func(context.Context) (*client.Holiday, error) {
+				if yearErr != nil {
+					return nil, yearErr
+				}
+				if isHoliday {
+					return &holiday, nil
+				}
+				return nil, nil
+			},
+			Earliest: func(context.Context) (apiModels.Date, error) {
+				return earliest, nil
+			},
+		}
+
+		result, err := s.rules.Run(ctx, in, CollectAll)
+		if err != nil {
+			return nil, err
+		}
+
+		check := DateCheck{
+			Date:       date,
+			Err:        result.Err(),
+			Violations: result.Violations,
+			Closure:    in.Closure,
+			Opening:    opening,
+			Hours:      in.Hours,
+			HalfDay:    s.halfDay,
+			Unverified: result.HolidayUnverified && len(result.Violations) == 0,
+		}
+		if isHoliday && opening == nil {
+			check.Holiday = &holiday
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// returns the standard daily opening hours; the weekly schedule and extra openings may differ from them on a given date
+func (s *HolidayService) OpeningHours() OpeningHours {
+	return s.hours
+}
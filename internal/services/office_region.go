@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"citynext/pkg/client"
+)
+
+// ISO 3166-1 country code, optionally followed by an ISO 3166-2 subdivision such as GB-SCT
+var officeRegionPattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+// where the office is, which decides the public holidays it closes on
+type OfficeRegion struct {
+	CountryCode string // e.g. GB
+	Subdivision string // e.g. GB-SCT, empty when only nationwide holidays apply
+}
+
+func DefaultOfficeRegion() OfficeRegion {
+	return OfficeRegion{CountryCode: "GB", Subdivision: "GB-ENG"}
+}
+
+// parses an OFFICE_REGION value such as "GB-ENG", or "GB" for nationwide holidays only
+func ParseOfficeRegion(region string) (OfficeRegion, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if !officeRegionPattern.MatchString(region) {
+		return OfficeRegion{}, fmt.Errorf("invalid office region %q, expected a country code such as GB or a subdivision such as GB-SCT", region)
+	}
+
+	countryCode, _, hasSubdivision := strings.Cut(region, "-")
+	if !hasSubdivision {
+		return OfficeRegion{CountryCode: countryCode}, nil
+	}
+	return OfficeRegion{CountryCode: countryCode, Subdivision: region}, nil
+}
+
+func (r OfficeRegion) String() string {
+	if r.Subdivision != "" {
+		return r.Subdivision
+	}
+	return r.CountryCode
+}
+
+// reports whether the office closes on a holiday: nationwide holidays always apply,
+// regional ones only when they list the office's subdivision
+func (r OfficeRegion) Observes(holiday client.Holiday) bool {
+	if holiday.Global {
+		return true
+	}
+	return r.Subdivision != "" && slices.Contains(holiday.Counties, r.Subdivision)
+}
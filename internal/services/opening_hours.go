@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// daily opening hours of the office, divided into fixed-length appointment slots
+type OpeningHours struct {
+	Opens      apiModels.TimeOfDay
+	Closes     apiModels.TimeOfDay
+	SlotLength time.Duration
+}
+
+// returns the default opening hours: 09:00-16:30 in 15-minute slots
+func DefaultOpeningHours() OpeningHours {
+	return OpeningHours{
+		Opens:      apiModels.NewTimeOfDay(9, 0),
+		Closes:     apiModels.NewTimeOfDay(16, 30),
+		SlotLength: 15 * time.Minute,
+	}
+}
+
+// checks that the hours describe at least one whole slot
+func (h OpeningHours) Validate() error {
+	if h.SlotLength < time.Minute || h.SlotLength%time.Minute != 0 {
+		return fmt.Errorf("slot length must be a whole number of minutes, got %s", h.SlotLength)
+	}
+	if h.Opens.Add(h.SlotLength).After(h.Closes) {
+		return fmt.Errorf("opening hours %s-%s do not fit a single %s slot", h.Opens, h.Closes, h.SlotLength)
+	}
+	return nil
+}
+
+// reports whether start is the beginning of a slot that fits inside the opening hours
+func (h OpeningHours) IsSlotStart(start apiModels.TimeOfDay) bool {
+	if start.Before(h.Opens) || start.Add(h.SlotLength).After(h.Closes) {
+		return false
+	}
+	offset := time.Duration(start.Minutes()-h.Opens.Minutes()) * time.Minute
+	return offset%h.SlotLength == 0
+}
+
+// returns the start times of all slots in a day, in order
+func (h OpeningHours) Slots() []apiModels.TimeOfDay {
+	var slots []apiModels.TimeOfDay
+	for start := h.Opens; !start.Add(h.SlotLength).After(h.Closes); start = start.Add(h.SlotLength) {
+		slots = append(slots, start)
+	}
+	return slots
+}
+
+// returns the start times of the slots that end no later than until, in order
+func (h OpeningHours) SlotsUntil(until apiModels.TimeOfDay) []apiModels.TimeOfDay {
+	var slots []apiModels.TimeOfDay
+	for _, start := range h.Slots() {
+		if start.Add(h.SlotLength).After(until) {
+			break
+		}
+		slots = append(slots, start)
+	}
+	return slots
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/errcode"
+	"citynext/pkg/client"
+)
+
+// one check a booking must pass; a rule that cannot reach a decision, e.g. because a lookup failed,
+// returns an error instead of a violation
+type Rule interface {
+	Name() string
+	Check(ctx context.Context, in *RuleInput) (*Violation, error)
+}
+
+// a rule's finding that a booking is not allowed
+type Violation struct {
+	Rule string         // name of the rule that found it
+	Err  *errcode.Error // what a booking breaking the rule is rejected with
+}
+
+func violation(rule Rule, err *errcode.Error) *Violation {
+	return &Violation{Rule: rule.Name(), Err: err}
+}
+
+// who a booking is for
+type Person struct {
+	FirstName string
+	LastName  string
+}
+
+// a booking, or a date on its own, together with what the rules judge it by
+type RuleInput struct {
+	Date   apiModels.Date
+	Start  *apiModels.TimeOfDay // nil when only the date is judged
+	Person *Person              // nil when no one is booking, as for availability
+	Now    time.Time            // current time in the office time zone
+
+	Opening *dbModels.ExtraOpening // extra opening covering the date, nil when there is none
+	Closure *dbModels.Closure      // closure deciding the date, nil when there is none
+	// when a half-day closure shuts the office; DefaultHalfDayClosingTime when zero
+	HalfDayCloses apiModels.TimeOfDay
+	Hours         OpeningHours // opening hours in force on the date; zero when unknown
+	Capacity      int          // maximum number of appointments on the date
+
+	// looked up only by the rules that need them, so a booking rejected early costs no lookups;
+	// a rule skips a lookup that is nil
+	Holiday        func(ctx context.Context) (*client.Holiday, error) // nil holiday when the date is not one
+	Earliest       func(ctx context.Context) (apiModels.Date, error)  // earliest date the minimum lead time allows
+	Booked         func(ctx context.Context) (int, error)             // active appointments on the date
+	PersonBookings func(ctx context.Context) (int, error)             // active upcoming appointments of Person
+
+	// set by HolidayRule when the date passed it only because holidays could not be looked up
+	HolidayUnverified bool
+}
+
+// returns the date as the office's calendar day
+func (in *RuleInput) day() time.Time {
+	return in.Date.Time.UTC().Truncate(24 * time.Hour)
+}
+
+// returns when a half-day closure shuts the office on the date
+func (in *RuleInput) halfDayCloses() apiModels.TimeOfDay {
+	if in.HalfDayCloses.Minutes() == 0 {
+		return DefaultHalfDayClosingTime()
+	}
+	return in.HalfDayCloses
+}
+
+// returns the current date in the office time zone
+func (in *RuleInput) today() apiModels.Date {
+	return apiModels.DateOf(in.Now, in.Now.Location())
+}
+
+// returns when the slot starts, or the zero time when only the date is judged
+func (in *RuleInput) startsAt() time.Time {
+	if in.Start == nil {
+		return time.Time{}
+	}
+	return in.Date.At(*in.Start, in.Now.Location())
+}
+
+// how a Pipeline treats the first violation it finds
+type RuleMode int
+
+const (
+	FailFast   RuleMode = iota // stop, as a booking is rejected with a single error
+	CollectAll                 // carry on, so availability and dry runs can report every reason
+)
+
+// outcome of running rules
+type RuleResult struct {
+	Violations        []Violation // in rule order
+	HolidayUnverified bool        // set when the date passed only because holidays could not be looked up
+}
+
+// returns the first violation's error, or nil when every rule passed
+func (r *RuleResult) Err() error {
+	if len(r.Violations) == 0 {
+		return nil
+	}
+	return r.Violations[0].Err
+}
+
+// reports whether a pipeline in the given mode should stop
+func (r *RuleResult) done(mode RuleMode) bool {
+	return mode == FailFast && len(r.Violations) > 0
+}
+
+// rules in the order they run; earlier rules decide which error a rejected booking gets
+type Pipeline []Rule
+
+func (p Pipeline) Run(ctx context.Context, in *RuleInput, mode RuleMode) (*RuleResult, error) {
+	result := &RuleResult{}
+	if err := p.runInto(ctx, in, mode, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runs the rules, adding to a result that earlier stages may have started
+func (p Pipeline) runInto(ctx context.Context, in *RuleInput, mode RuleMode, result *RuleResult) error {
+	for _, rule := range p {
+		if result.done(mode) {
+			break
+		}
+		found, err := rule.Check(ctx, in)
+		if err != nil {
+			return err
+		}
+		if found != nil {
+			result.Violations = append(result.Violations, *found)
+		}
+	}
+	result.HolidayUnverified = result.HolidayUnverified || in.HolidayUnverified
+	return nil
+}
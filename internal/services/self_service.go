@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+)
+
+// recorded as CancelledBy when citizens cancel their own booking
+const CancelledByCitizen = "citizen"
+
+// unknown references and wrong tokens look the same, so references cannot be probed
+var errBookingNotFound = database.ErrAppointmentNotFound.At("path.reference")
+
+// looks up an appointment by booking reference and checks its management token
+func (s *AppointmentService) authenticateBooking(ctx context.Context, reference, token string) (*dbModels.Appointment, error) {
+	reference = normalizeBookingReference(reference)
+
+	appointment, err := s.repo.GetByReference(ctx, reference)
+	if err != nil {
+		if errors.Is(err, database.ErrAppointmentNotFound) {
+			s.logger.Warn("Unknown booking reference", "reference", reference)
+			return nil, errBookingNotFound
+		}
+		s.logger.Error("Failed to get appointment by reference",
+			"error", err,
+			"reference", reference)
+		return nil, err
+	}
+
+	if !managementTokenMatches(appointment, token) {
+		s.logger.Warn("Invalid management token", "reference", reference)
+		return nil, errBookingNotFound
+	}
+
+	return appointment, nil
+}
+
+// retrieves the appointment a citizen holds the management token for
+func (s *AppointmentService) GetAppointmentByReference(ctx context.Context, reference, token string) (*dbModels.Appointment, error) {
+	s.logger.Debug("Getting appointment by reference", "reference", reference)
+
+	return s.authenticateBooking(ctx, reference, token)
+}
+
+// cancels the appointment a citizen holds the management token for
+func (s *AppointmentService) CancelAppointmentByReference(ctx context.Context, reference, token, reason string) (*dbModels.Appointment, error) {
+	s.logger.Info("Cancelling appointment by reference", "reference", reference)
+
+	appointment, err := s.authenticateBooking(ctx, reference, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CancelAppointment(ctx, &CancelAppointmentRequest{
+		ID:          appointment.ID,
+		CancelledBy: CancelledByCitizen,
+		Reason:      reason,
+	})
+}
+
+// moves the appointment a citizen holds the management token for to a new slot
+func (s *AppointmentService) RescheduleAppointmentByReference(ctx context.Context, reference, token string, visitDate apiModels.Date, startTime apiModels.TimeOfDay) (*dbModels.Appointment, error) {
+	s.logger.Info("Rescheduling appointment by reference",
+		"reference", reference,
+		"new_visit_date", visitDate.String(),
+		"new_start_time", startTime.String())
+
+	appointment, err := s.authenticateBooking(ctx, reference, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.RescheduleAppointment(ctx, &RescheduleAppointmentRequest{
+		ID:        appointment.ID,
+		VisitDate: visitDate,
+		StartTime: startTime,
+	})
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	dbModels "citynext/internal/database/models"
+	"citynext/pkg/client"
+)
+
+// converts a fetched holiday into its stored form, recording where and when it was fetched
+func toStoredHoliday(holiday client.Holiday, countryCode, source string, fetchedAt time.Time) (dbModels.Holiday, error) {
+	date, err := time.Parse("2006-01-02", holiday.Date)
+	if err != nil {
+		return dbModels.Holiday{}, err
+	}
+
+	return dbModels.Holiday{
+		CountryCode: countryCode,
+		Year:        date.Year(),
+		Date:        apiModels.Date{Time: date},
+		LocalName:   holiday.LocalName,
+		Name:        holiday.Name,
+		Global:      holiday.Global,
+		Counties:    strings.Join(holiday.Counties, ","),
+		Types:       strings.Join(holiday.Types, ","),
+		Source:      source,
+		FetchedAt:   fetchedAt,
+	}, nil
+}
+
+// converts a stored holiday back into the form returned by the API client
+func fromStoredHoliday(holiday dbModels.Holiday) client.Holiday {
+	return client.Holiday{
+		Date:        holiday.Date.String(),
+		LocalName:   holiday.LocalName,
+		Name:        holiday.Name,
+		CountryCode: holiday.CountryCode,
+		Global:      holiday.Global,
+		Counties:    splitList(holiday.Counties),
+		Types:       splitList(holiday.Types),
+	}
+}
+
+// splits a comma-separated column, returning nil rather than [""] for an empty one
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
+
+// groups the stored holidays the office observes by year; a year counts as fetched when its oldest record was
+func groupStoredHolidays(stored []dbModels.Holiday, region OfficeRegion) map[int]*holidayYear {
+	years := make(map[int]*holidayYear)
+	for _, holiday := range stored {
+		loaded, exists := years[holiday.Year]
+		if !exists {
+			loaded = &holidayYear{
+				holidays:  make(map[string]client.Holiday),
+				fetchedAt: holiday.FetchedAt,
+			}
+			years[holiday.Year] = loaded
+		}
+		if holiday.FetchedAt.Before(loaded.fetchedAt) {
+			loaded.fetchedAt = holiday.FetchedAt
+		}
+		if observed := fromStoredHoliday(holiday); region.Observes(observed) {
+			loaded.holidays[observed.Date] = observed
+		}
+	}
+	return years
+}
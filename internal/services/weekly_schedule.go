@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// opening hours for each day of the week; a day without hours is closed
+type WeeklySchedule map[time.Weekday]OpeningHours
+
+// short day names used in WEEKLY_SCHEDULE, in time.Weekday order
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// returns a Monday to Friday schedule with the same hours every day
+func WeekdaySchedule(hours OpeningHours) WeeklySchedule {
+	schedule := make(WeeklySchedule, 5)
+	for day := time.Monday; day <= time.Friday; day++ {
+		schedule[day] = hours
+	}
+	return schedule
+}
+
+// parses a schedule in the form "Mon-Fri=09:00-16:30,Sat=09:00-12:00", where each entry is a day
+// or an inclusive range of days, which may wrap around the week, e.g. "Sat-Mon"; days not listed are closed
+func ParseWeeklySchedule(spec string, slotLength time.Duration) (WeeklySchedule, error) {
+	schedule := make(WeeklySchedule)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		daysSpec, hoursSpec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule entry %q, expected DAYS=HH:MM-HH:MM", entry)
+		}
+
+		days, err := parseWeekdays(strings.TrimSpace(daysSpec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+		}
+
+		opensSpec, closesSpec, ok := strings.Cut(strings.TrimSpace(hoursSpec), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule entry %q, expected DAYS=HH:MM-HH:MM", entry)
+		}
+		opens, err := apiModels.ParseTimeOfDay(strings.TrimSpace(opensSpec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+		}
+		closes, err := apiModels.ParseTimeOfDay(strings.TrimSpace(closesSpec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+		}
+
+		hours := OpeningHours{Opens: opens, Closes: closes, SlotLength: slotLength}
+		for _, day := range days {
+			if _, exists := schedule[day]; exists {
+				return nil, fmt.Errorf("schedule lists %s more than once", weekdayNames[day])
+			}
+			schedule[day] = hours
+		}
+	}
+
+	return schedule, schedule.Validate()
+}
+
+// parses a day name such as "Mon", or an inclusive range such as "Tue-Sat"
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	firstSpec, lastSpec, isRange := strings.Cut(spec, "-")
+	first, err := parseWeekday(firstSpec)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		return []time.Weekday{first}, nil
+	}
+	last, err := parseWeekday(lastSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []time.Weekday{first}
+	for day := first; day != last; {
+		day = (day + 1) % 7
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day, dayName := range weekdayNames {
+		if strings.EqualFold(strings.TrimSpace(name), dayName) {
+			return time.Weekday(day), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q, expected one of %s", name, strings.Join(weekdayNames, ", "))
+}
+
+// checks that the office opens on at least one day and that every day's hours fit a slot
+func (w WeeklySchedule) Validate() error {
+	if len(w) == 0 {
+		return fmt.Errorf("schedule has no open days")
+	}
+	for day, hours := range w {
+		if err := hours.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", weekdayNames[day], err)
+		}
+	}
+	return nil
+}
+
+// returns the opening hours on a day of the week, and false if the office is closed that day
+func (w WeeklySchedule) HoursOn(day time.Weekday) (OpeningHours, bool) {
+	hours, open := w[day]
+	return hours, open
+}
+
+// returns the days of the week the office opens, starting on Monday
+func (w WeeklySchedule) OpenDays() []time.Weekday {
+	var days []time.Weekday
+	for i := 1; i <= 7; i++ {
+		if day := time.Weekday(i % 7); w[day].SlotLength > 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// formats the schedule one day at a time, starting on Monday, e.g. "Mon=09:00-16:30,Tue=09:00-16:30"
+func (w WeeklySchedule) String() string {
+	var entries []string
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if hours, open := w[day]; open {
+			entries = append(entries, fmt.Sprintf("%s=%s-%s", weekdayNames[day], hours.Opens, hours.Closes))
+		}
+	}
+	return strings.Join(entries, ",")
+}
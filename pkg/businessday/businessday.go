@@ -0,0 +1,115 @@
+// Package businessday does working-day arithmetic: a working day is a day of the week
+// the office works on that is not a holiday. Only the calendar date of a time.Time is used.
+package businessday
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// how many days in a row may be non-working before a search gives up, so that a calendar
+// without any working days cannot loop forever
+const maxSearchDays = 366
+
+// returned when no working day is found within maxSearchDays
+var ErrNoWorkingDay = errors.New("no working day within a year")
+
+// reports whether a date is a holiday
+type HolidayFunc func(ctx context.Context, date time.Time) (bool, error)
+
+type Calendar struct {
+	workingDays [7]bool // indexed by time.Weekday
+	isHoliday   HolidayFunc
+}
+
+// builds a calendar that works on the given days of the week; isHoliday may be nil when there are no holidays
+func New(workingDays []time.Weekday, isHoliday HolidayFunc) *Calendar {
+	c := &Calendar{isHoliday: isHoliday}
+	for _, day := range workingDays {
+		c.workingDays[day] = true
+	}
+	return c
+}
+
+// Monday to Friday
+func Weekdays() []time.Weekday {
+	return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+}
+
+// reports whether the day of the week is worked, before holidays are taken into account
+func (c *Calendar) IsWorkingWeekday(day time.Weekday) bool {
+	return c.workingDays[day]
+}
+
+func (c *Calendar) IsWorkingDay(ctx context.Context, date time.Time) (bool, error) {
+	if !c.workingDays[date.Weekday()] {
+		return false, nil
+	}
+	if c.isHoliday == nil {
+		return true, nil
+	}
+
+	holiday, err := c.isHoliday(ctx, dateOf(date))
+	if err != nil {
+		return false, err
+	}
+	return !holiday, nil
+}
+
+// returns the first working day after date
+func (c *Calendar) NextWorkingDay(ctx context.Context, date time.Time) (time.Time, error) {
+	return c.AddWorkingDays(ctx, date, 1)
+}
+
+// moves n working days on from date, or back when n is negative; date itself need not be
+// a working day, and n = 0 returns it unchanged
+func (c *Calendar) AddWorkingDays(ctx context.Context, date time.Time, n int) (time.Time, error) {
+	day, step := dateOf(date), 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	for skipped := 0; n > 0; {
+		day = day.AddDate(0, 0, step)
+		working, err := c.IsWorkingDay(ctx, day)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if working {
+			n--
+			skipped = 0
+			continue
+		}
+		if skipped++; skipped >= maxSearchDays {
+			return time.Time{}, ErrNoWorkingDay
+		}
+	}
+	return day, nil
+}
+
+// counts the working days after from, up to and including to; the count is negative when to is
+// before from, so that AddWorkingDays(from, WorkingDaysBetween(from, to)) is to whenever to is a working day
+func (c *Calendar) WorkingDaysBetween(ctx context.Context, from, to time.Time) (int, error) {
+	from, to, sign := dateOf(from), dateOf(to), 1
+	if to.Before(from) {
+		from, to, sign = to.AddDate(0, 0, -1), from.AddDate(0, 0, -1), -1
+	}
+
+	count := 0
+	for day := from.AddDate(0, 0, 1); !day.After(to); day = day.AddDate(0, 0, 1) {
+		working, err := c.IsWorkingDay(ctx, day)
+		if err != nil {
+			return 0, err
+		}
+		if working {
+			count++
+		}
+	}
+	return sign * count, nil
+}
+
+// midnight UTC on the calendar date of t
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// returned without calling the API while the circuit breaker is open
+var ErrCircuitOpen = errors.New("holiday API circuit breaker is open")
+
+// state of a circuit breaker
+type CircuitState int
+
+const (
+	// calls go through; consecutive failures are counted
+	CircuitClosed CircuitState = iota
+	// calls fail fast with ErrCircuitOpen until the cooldown has passed
+	CircuitOpen
+	// a single probe call is let through to decide whether to close again
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// orders states from healthy to unhealthy: closed, then half-open, which is about to probe, then open;
+// the constants' own order is not that order
+func (s CircuitState) unhealthiness() int {
+	switch s {
+	case CircuitClosed:
+		return 0
+	case CircuitHalfOpen:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// configures when a circuit breaker opens and when it probes again
+type BreakerConfig struct {
+	FailureThreshold int           // consecutive failed calls that open the breaker
+	Cooldown         time.Duration // how long the breaker stays open before a probe is allowed
+}
+
+// reports a config under which the breaker would open before any failure or never wait before probing
+func (c BreakerConfig) Validate() error {
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("breaker failure threshold must be at least 1, got %d", c.FailureThreshold)
+	}
+	if c.Cooldown <= 0 {
+		return fmt.Errorf("breaker cooldown must be positive, got %s", c.Cooldown)
+	}
+	return nil
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// stops calls to a failing dependency and lets one probe through after a cooldown
+type circuitBreaker struct {
+	config   BreakerConfig
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool // a half-open probe is in flight
+
+	onStateChange func(from, to CircuitState)
+}
+
+func newCircuitBreaker(config BreakerConfig, onStateChange func(from, to CircuitState)) *circuitBreaker {
+	return &circuitBreaker{
+		config:        config,
+		onStateChange: onStateChange,
+	}
+}
+
+// reports whether a call may proceed; every allowed call must be followed by record
+func (b *circuitBreaker) allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.currentState() {
+	case CircuitClosed:
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.setState(CircuitHalfOpen)
+		b.probing = true
+		return nil
+	default:
+		return ErrCircuitOpen
+	}
+}
+
+// records the outcome of an allowed call
+func (b *circuitBreaker) record(success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.setState(CircuitClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.openedAt = time.Now()
+		b.setState(CircuitOpen)
+	}
+}
+
+// ends an allowed call whose outcome says nothing about the dependency's health,
+// such as one abandoned by its caller
+func (b *circuitBreaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
+
+// returns the current state, reporting an open breaker whose cooldown has passed as half-open
+func (b *circuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.currentState()
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
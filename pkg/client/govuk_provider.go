@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// counties of each division in gov.uk's bank-holidays.json
+var govUKDivisionCounties = map[string][]string{
+	"england-and-wales": {"GB-ENG", "GB-WLS"},
+	"scotland":          {"GB-SCT"},
+	"northern-ireland":  {"GB-NIR"},
+}
+
+// the divisions in a fixed order, so merged names do not depend on map iteration
+var govUKDivisions = []string{"england-and-wales", "scotland", "northern-ireland"}
+
+type govUKDivision struct {
+	Events []struct {
+		Title string `json:"title"`
+		Date  string `json:"date"`
+		Notes string `json:"notes"`
+	} `json:"events"`
+}
+
+// HolidayProvider for the gov.uk bank-holidays.json format, e.g. https://www.gov.uk/bank-holidays.json;
+// it only covers GB, and a bank holiday observed in every division is reported as global
+type GovUKProvider struct {
+	location location
+	logger   *slog.Logger
+}
+
+func NewGovUKProvider(path string, logger *slog.Logger, opts ...HolidayClientOption) *GovUKProvider {
+	return &GovUKProvider{
+		location: newLocation(path, logger, opts...),
+		logger:   logger,
+	}
+}
+
+func (p *GovUKProvider) Name() string {
+	return "govuk:" + p.location.path
+}
+
+func (p *GovUKProvider) CircuitState() CircuitState {
+	return p.location.circuitState()
+}
+
+func (p *GovUKProvider) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
+	if countryCode != "GB" {
+		p.logger.Warn("gov.uk bank holidays only cover GB", "country_code", countryCode)
+		return nil, nil
+	}
+
+	var divisions map[string]govUKDivision
+	err := p.location.read(ctx, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&divisions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("%d-", year)
+	byDate := make(map[string]*Holiday)
+	for _, division := range govUKDivisions {
+		for _, event := range divisions[division].Events {
+			if !strings.HasPrefix(event.Date, prefix) {
+				continue
+			}
+
+			holiday, exists := byDate[event.Date]
+			if !exists {
+				holiday = &Holiday{
+					Date:        event.Date,
+					LocalName:   event.Title,
+					Name:        event.Title,
+					CountryCode: countryCode,
+					Types:       []string{"Bank"},
+				}
+				byDate[event.Date] = holiday
+			}
+			for _, county := range govUKDivisionCounties[division] {
+				if !slices.Contains(holiday.Counties, county) {
+					holiday.Counties = append(holiday.Counties, county)
+				}
+			}
+		}
+	}
+
+	holidays := make([]Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		if len(holiday.Counties) == 4 {
+			holiday.Global = true
+			holiday.Counties = nil
+		}
+		holidays = append(holidays, *holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+
+	p.logger.Info("Loaded gov.uk bank holidays",
+		"location", p.location.path,
+		"year", year,
+		"count", len(holidays))
+	return holidays, nil
+}
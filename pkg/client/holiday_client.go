@@ -4,41 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
 	"time"
 )
 
-// represents a public holiday from the Nager.Date API
+// represents a public holiday, in the shape of the Nager.Date API that every provider returns
 type Holiday struct {
-	Date        string   `json:"date"`
-	LocalName   string   `json:"localName"`
-	Name        string   `json:"name"`
-	CountryCode string   `json:"countryCode"`
-	Fixed       bool     `json:"fixed"`
-	Global      bool     `json:"global"`
-	Counties    []string `json:"counties"`
-	LaunchYear  *int     `json:"launchYear"`
-	Types       []string `json:"types"`
+	Date        string   `json:"date" yaml:"date"`
+	LocalName   string   `json:"localName" yaml:"localName"`
+	Name        string   `json:"name" yaml:"name"`
+	CountryCode string   `json:"countryCode" yaml:"countryCode"`
+	Fixed       bool     `json:"fixed" yaml:"fixed"`
+	Global      bool     `json:"global" yaml:"global"`
+	Counties    []string `json:"counties" yaml:"counties"`
+	LaunchYear  *int     `json:"launchYear" yaml:"launchYear"`
+	Types       []string `json:"types" yaml:"types"`
 }
 
+// HolidayProvider backed by the Nager.Date API
 type HolidayClient struct {
-	baseURL    string
-	httpClient *http.Client
-	logger     *slog.Logger
+	baseURL string
+	fetcher *httpFetcher
+	logger  *slog.Logger
 }
 
-func NewHolidayClient(baseURL string, logger *slog.Logger) *HolidayClient {
+func NewHolidayClient(baseURL string, logger *slog.Logger, opts ...HolidayClientOption) *HolidayClient {
 	return &HolidayClient{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		logger: logger,
+		fetcher: newHTTPFetcher(logger, opts...),
+		logger:  logger,
 	}
 }
 
-// fetches public holidays for a specific year and country
+func (c *HolidayClient) Name() string {
+	return "nager:" + c.baseURL
+}
+
+// returns the circuit breaker state; anything but CircuitClosed means the API is considered unhealthy
+func (c *HolidayClient) CircuitState() CircuitState {
+	return c.fetcher.breaker.State()
+}
+
+// fetches public holidays for a specific year and country, retrying transient failures;
+// fails fast with ErrCircuitOpen while the API is considered down
 func (c *HolidayClient) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
 	url := fmt.Sprintf("%s/PublicHolidays/%d/%s", c.baseURL, year, countryCode)
 
@@ -47,36 +56,12 @@ func (c *HolidayClient) GetPublicHolidays(ctx context.Context, year int, country
 		"year", year,
 		"country_code", countryCode)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		c.logger.Error("Failed to create request",
-			"error", err,
-			"url", url)
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		c.logger.Error("Failed to fetch public holidays",
-			"error", err,
-			"url", url)
-		return nil, fmt.Errorf("failed to fetch public holidays: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		c.logger.Error("API returned non-OK status",
-			"status_code", resp.StatusCode,
-			"url", url)
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
 	var holidays []Holiday
-	if err := json.NewDecoder(resp.Body).Decode(&holidays); err != nil {
-		c.logger.Error("Failed to decode response",
-			"error", err,
-			"url", url)
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	err := c.fetcher.fetch(ctx, url, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&holidays)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	c.logger.Info("Successfully fetched public holidays",
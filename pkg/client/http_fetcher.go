@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// non-OK response from a holiday source
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header of a 429, zero when absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// configures how failed requests are retried
+type RetryPolicy struct {
+	MaxAttempts int           // attempts per call, including the first; 1 disables retries
+	BaseDelay   time.Duration // backoff before the second attempt, doubled for every further one
+	MaxDelay    time.Duration // upper bound for a backoff, and for a Retry-After the client is willing to wait
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// reports a policy that would never attempt a call or could not compute a backoff
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry attempts must be at least 1, got %d", p.MaxAttempts)
+	}
+	if p.BaseDelay < 0 {
+		return fmt.Errorf("retry base delay must not be negative, got %s", p.BaseDelay)
+	}
+	if p.MaxDelay < 0 {
+		return fmt.Errorf("retry max delay must not be negative, got %s", p.MaxDelay)
+	}
+	return nil
+}
+
+// returns the jittered exponential backoff before the given retry (1 for the first)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0 // an unvalidated negative MaxDelay must not reach rand.N
+	}
+	// equal jitter: at least half the delay, so retries never bunch up at zero
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// configures the HTTP behaviour of the holiday sources fetched over HTTP
+type HolidayClientOption func(*httpFetcher)
+
+// sets how failed requests are retried
+func WithRetryPolicy(policy RetryPolicy) HolidayClientOption {
+	return func(f *httpFetcher) {
+		f.retry = policy
+	}
+}
+
+// sets when the circuit breaker opens and how long it waits before probing the source again
+func WithBreakerConfig(config BreakerConfig) HolidayClientOption {
+	return func(f *httpFetcher) {
+		f.breaker.config = config
+	}
+}
+
+// waits between retries; it returns early with ctx's error once ctx is done
+type Sleeper func(ctx context.Context, d time.Duration) error
+
+// replaces how the client waits between retries, e.g. so tests need not wait for real
+func WithSleeper(sleep Sleeper) HolidayClientOption {
+	return func(f *httpFetcher) {
+		f.sleep = sleep
+	}
+}
+
+// replaces the HTTP client, e.g. to change the per-attempt timeout
+func WithHTTPClient(httpClient *http.Client) HolidayClientOption {
+	return func(f *httpFetcher) {
+		f.httpClient = httpClient
+	}
+}
+
+// GETs a holiday source with retries, behind a circuit breaker
+type httpFetcher struct {
+	httpClient *http.Client
+	retry      RetryPolicy
+	breaker    *circuitBreaker
+	sleep      Sleeper
+	logger     *slog.Logger
+}
+
+func newHTTPFetcher(logger *slog.Logger, opts ...HolidayClientOption) *httpFetcher {
+	f := &httpFetcher{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		retry:  DefaultRetryPolicy(),
+		sleep:  sleepContext,
+		logger: logger,
+	}
+	f.breaker = newCircuitBreaker(DefaultBreakerConfig(), func(from, to CircuitState) {
+		f.logger.Warn("Holiday API circuit breaker changed state",
+			"from", from.String(),
+			"to", to.String())
+	})
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// fetches url and hands the response body to decode, retrying transient failures;
+// fails fast with ErrCircuitOpen while the source is considered down
+func (f *httpFetcher) fetch(ctx context.Context, url string, decode func(io.Reader) error) error {
+	if err := f.breaker.allow(); err != nil {
+		f.logger.Warn("Skipping holiday API call",
+			"error", err,
+			"url", url)
+		return err
+	}
+
+	err := f.fetchWithRetries(ctx, url, decode)
+
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		f.breaker.record(true)
+	case ctx.Err() != nil:
+		// abandoned by the caller, which says nothing about the source
+		f.breaker.release()
+	case errors.As(err, &statusErr) && statusErr.StatusCode < 500 && statusErr.StatusCode != http.StatusTooManyRequests:
+		// the source answered; the request itself was wrong
+		f.breaker.record(true)
+	default:
+		f.breaker.record(false)
+	}
+	return err
+}
+
+// performs up to MaxAttempts requests, waiting between them as the retry policy and Retry-After dictate
+func (f *httpFetcher) fetchWithRetries(ctx context.Context, url string, decode func(io.Reader) error) error {
+	for attempt := 1; ; attempt++ {
+		retryable, err := f.get(ctx, url, decode)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= f.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay := f.retry.backoff(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			if statusErr.RetryAfter > f.retry.MaxDelay {
+				f.logger.Warn("Not waiting for Retry-After longer than the maximum delay",
+					"retry_after", statusErr.RetryAfter,
+					"max_delay", f.retry.MaxDelay,
+					"url", url)
+				return err
+			}
+			delay = statusErr.RetryAfter
+		}
+
+		f.logger.Warn("Retrying public holidays request",
+			"error", err,
+			"attempt", attempt,
+			"delay", delay,
+			"url", url)
+
+		if err := f.sleep(ctx, delay); err != nil {
+			return fmt.Errorf("failed to fetch public holidays: %w", err)
+		}
+	}
+}
+
+// waits for d on a timer, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// performs a single request; retryable reports whether the failure may be transient
+func (f *httpFetcher) get(ctx context.Context, url string, decode func(io.Reader) error) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		f.logger.Error("Failed to create request",
+			"error", err,
+			"url", url)
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		f.logger.Error("Failed to fetch public holidays",
+			"error", err,
+			"url", url)
+		return true, fmt.Errorf("failed to fetch public holidays: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		f.logger.Error("API returned non-OK status",
+			"status_code", resp.StatusCode,
+			"url", url)
+		statusErr := &StatusError{StatusCode: resp.StatusCode}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			statusErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, statusErr
+	}
+
+	if err := decode(resp.Body); err != nil {
+		f.logger.Error("Failed to decode response",
+			"error", err,
+			"url", url)
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return false, nil
+}
+
+// parses a Retry-After header given in seconds or as an HTTP date; zero means absent or invalid
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
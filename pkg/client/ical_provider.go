@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// longest event expanded into single days, guarding against malformed DTEND values
+const maxICalEventDays = 366
+
+// HolidayProvider for an iCalendar (.ics) feed given as URL or file. Every all-day VEVENT is a
+// holiday for the office, whatever country is asked for; recurrence rules are not expanded.
+type ICalProvider struct {
+	location location
+	logger   *slog.Logger
+}
+
+func NewICalProvider(path string, logger *slog.Logger, opts ...HolidayClientOption) *ICalProvider {
+	return &ICalProvider{
+		location: newLocation(path, logger, opts...),
+		logger:   logger,
+	}
+}
+
+func (p *ICalProvider) Name() string {
+	return "ical:" + p.location.path
+}
+
+func (p *ICalProvider) CircuitState() CircuitState {
+	return p.location.circuitState()
+}
+
+func (p *ICalProvider) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
+	var events []icalEvent
+	err := p.location.read(ctx, func(body io.Reader) error {
+		var err error
+		events, err = parseICalEvents(body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []Holiday
+	for _, event := range events {
+		for day := event.start; day.Before(event.end); day = day.AddDate(0, 0, 1) {
+			if day.Year() != year {
+				continue
+			}
+			holidays = append(holidays, Holiday{
+				Date:        day.Format("2006-01-02"),
+				LocalName:   event.summary,
+				Name:        event.summary,
+				CountryCode: countryCode,
+				Global:      true,
+			})
+		}
+	}
+	// overlapping events share their days, which the cache and the store keep as one entry per date
+	holidays = MergeByDate(holidays)
+
+	p.logger.Info("Loaded iCalendar holidays",
+		"location", p.location.path,
+		"year", year,
+		"count", len(holidays))
+	return holidays, nil
+}
+
+// an all-day VEVENT, covering the days start up to but excluding end
+type icalEvent struct {
+	summary    string
+	start, end time.Time
+}
+
+// reads the VEVENTs of an iCalendar document, unfolding continuation lines
+func parseICalEvents(r io.Reader) ([]icalEvent, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []icalEvent
+	var event *icalEvent
+	for _, line := range lines {
+		nameAndParams, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		name, _, _ := strings.Cut(nameAndParams, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				event = &icalEvent{}
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || event == nil {
+				continue
+			}
+			if event.start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", event.summary)
+			}
+			if !event.end.After(event.start) {
+				event.end = event.start.AddDate(0, 0, 1)
+			}
+			if event.end.Sub(event.start) > maxICalEventDays*24*time.Hour {
+				return nil, fmt.Errorf("event %q spans more than %d days", event.summary, maxICalEventDays)
+			}
+			events = append(events, *event)
+			event = nil
+		case "SUMMARY":
+			if event != nil {
+				event.summary = unescapeICalText(value)
+			}
+		case "DTSTART", "DTEND":
+			if event == nil {
+				continue
+			}
+			day, err := parseICalDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(name, "DTSTART") {
+				event.start = day
+			} else {
+				event.end = day
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// parses the date part of a DATE or DATE-TIME value such as 20251225 or 20251225T000000Z
+func parseICalDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q", value)
+	}
+	day, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q: %w", value, err)
+	}
+	return day, nil
+}
+
+var icalTextUnescaper = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICalText(value string) string {
+	return icalTextUnescaper.Replace(value)
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// source of public holidays
+type HolidayProvider interface {
+	// returns the holidays of a year for a country; a source that does not cover the country returns none
+	GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error)
+	// identifies the source, e.g. "nager:https://date.nager.at/api/v3"; recorded with stored holidays
+	Name() string
+}
+
+// implemented by providers that call a remote source through a circuit breaker
+type CircuitStater interface {
+	CircuitState() CircuitState
+}
+
+// builds a provider from a HOLIDAY_PROVIDERS value: comma-separated kind=location entries, where kind is
+// nager (API base URL), govuk (bank-holidays.json URL or file), ical (.ics URL or file) or file (YAML or
+// JSON file). Several entries are merged by a CompositeProvider.
+func NewProviderFromSpec(spec string, logger *slog.Logger, opts ...HolidayClientOption) (HolidayProvider, error) {
+	var providers []HolidayProvider
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, location, found := strings.Cut(entry, "=")
+		location = strings.TrimSpace(location)
+		if !found || location == "" {
+			return nil, fmt.Errorf("invalid holiday provider %q, expected kind=location", entry)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "nager":
+			providers = append(providers, NewHolidayClient(location, logger, opts...))
+		case "govuk":
+			providers = append(providers, NewGovUKProvider(location, logger, opts...))
+		case "ical":
+			providers = append(providers, NewICalProvider(location, logger, opts...))
+		case "file":
+			providers = append(providers, NewStaticFileProvider(location, logger))
+		default:
+			return nil, fmt.Errorf("unknown holiday provider kind %q, expected nager, govuk, ical or file", kind)
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no holiday provider configured")
+	case 1:
+		return providers[0], nil
+	default:
+		return NewCompositeProvider(providers...), nil
+	}
+}
+
+// reads a document that lives either at an http(s) URL or in a local file
+type location struct {
+	path    string
+	fetcher *httpFetcher // nil for local files
+}
+
+func newLocation(path string, logger *slog.Logger, opts ...HolidayClientOption) location {
+	loc := location{path: path}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		loc.fetcher = newHTTPFetcher(logger, opts...)
+	}
+	return loc
+}
+
+func (l location) read(ctx context.Context, decode func(io.Reader) error) error {
+	if l.fetcher != nil {
+		return l.fetcher.fetch(ctx, l.path, decode)
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to open holiday file: %w", err)
+	}
+	defer file.Close()
+
+	if err := decode(file); err != nil {
+		return fmt.Errorf("failed to decode holiday file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+func (l location) circuitState() CircuitState {
+	if l.fetcher == nil {
+		return CircuitClosed
+	}
+	return l.fetcher.breaker.State()
+}
+
+// merges the holidays of several providers; it fails when any of them fails,
+// so that an incomplete year is never mistaken for a complete one
+type CompositeProvider struct {
+	providers []HolidayProvider
+}
+
+func NewCompositeProvider(providers ...HolidayProvider) *CompositeProvider {
+	return &CompositeProvider{providers: providers}
+}
+
+func (p *CompositeProvider) Name() string {
+	names := make([]string, len(p.providers))
+	for i, provider := range p.providers {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// reports the least healthy state of the providers behind a circuit breaker
+func (p *CompositeProvider) CircuitState() CircuitState {
+	state := CircuitClosed
+	for _, provider := range p.providers {
+		if stater, ok := provider.(CircuitStater); ok {
+			if other := stater.CircuitState(); other.unhealthiness() > state.unhealthiness() {
+				state = other
+			}
+		}
+	}
+	return state
+}
+
+// returns the holidays of every provider, one entry per date: a date is global when any
+// provider says so, otherwise it applies to every county any provider lists
+func (p *CompositeProvider) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
+	byDate := make(map[string]*Holiday)
+	for _, provider := range p.providers {
+		holidays, err := provider.GetPublicHolidays(ctx, year, countryCode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", provider.Name(), err)
+		}
+
+		// a provider listing two holidays on one date names both, the first provider names a shared date
+		for _, holiday := range MergeByDate(holidays) {
+			merged, exists := byDate[holiday.Date]
+			if !exists {
+				holiday := holiday
+				byDate[holiday.Date] = &holiday
+				continue
+			}
+			mergeHoliday(merged, holiday)
+		}
+	}
+	return sortedByDate(byDate), nil
+}
+
+// folds the holidays of one source that share a date into a single entry per date, in date order;
+// distinct holidays on the same date keep both names, e.g. "St Andrew's Day / Local Holiday"
+func MergeByDate(holidays []Holiday) []Holiday {
+	byDate := make(map[string]*Holiday, len(holidays))
+	for _, holiday := range holidays {
+		merged, exists := byDate[holiday.Date]
+		if !exists {
+			holiday := holiday
+			byDate[holiday.Date] = &holiday
+			continue
+		}
+		mergeHoliday(merged, holiday)
+		merged.Name = joinHolidayNames(merged.Name, holiday.Name)
+		merged.LocalName = joinHolidayNames(merged.LocalName, holiday.LocalName)
+	}
+	return sortedByDate(byDate)
+}
+
+const holidayNameSeparator = " / "
+
+// appends name to a joined list of names unless it is already there
+func joinHolidayNames(names, name string) string {
+	switch {
+	case name == "" || slices.Contains(strings.Split(names, holidayNameSeparator), name):
+		return names
+	case names == "":
+		return name
+	}
+	return names + holidayNameSeparator + name
+}
+
+func sortedByDate(byDate map[string]*Holiday) []Holiday {
+	holidays := make([]Holiday, 0, len(byDate))
+	for _, holiday := range byDate {
+		holidays = append(holidays, *holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+	return holidays
+}
+
+// folds another source's entry for the same date into merged, keeping merged's names
+func mergeHoliday(merged *Holiday, other Holiday) {
+	if merged.Global || other.Global {
+		merged.Global = true
+		merged.Counties = nil
+	} else {
+		for _, county := range other.Counties {
+			if !slices.Contains(merged.Counties, county) {
+				merged.Counties = append(merged.Counties, county)
+			}
+		}
+	}
+	for _, holidayType := range other.Types {
+		if !slices.Contains(merged.Types, holidayType) {
+			merged.Types = append(merged.Types, holidayType)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HolidayProvider for a local YAML or JSON file, for deployments without internet access.
+// The file holds a list of holidays with the same fields as the Nager.Date API; an entry
+// without countryCode applies to every country, and one without counties is global.
+type StaticFileProvider struct {
+	location location
+	logger   *slog.Logger
+}
+
+func NewStaticFileProvider(path string, logger *slog.Logger) *StaticFileProvider {
+	return &StaticFileProvider{
+		location: location{path: path},
+		logger:   logger,
+	}
+}
+
+func (p *StaticFileProvider) Name() string {
+	return "file:" + p.location.path
+}
+
+func (p *StaticFileProvider) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
+	var entries []Holiday
+	err := p.location.read(ctx, func(body io.Reader) error {
+		switch strings.ToLower(filepath.Ext(p.location.path)) {
+		case ".yaml", ".yml":
+			return yaml.NewDecoder(body).Decode(&entries)
+		default:
+			return json.NewDecoder(body).Decode(&entries)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("%d-", year)
+	var holidays []Holiday
+	for _, holiday := range entries {
+		if _, err := time.Parse("2006-01-02", holiday.Date); err != nil {
+			return nil, fmt.Errorf("holiday %q in %s has invalid date %q", holiday.Name, p.location.path, holiday.Date)
+		}
+		if !strings.HasPrefix(holiday.Date, prefix) {
+			continue
+		}
+		if holiday.CountryCode != "" && !strings.EqualFold(holiday.CountryCode, countryCode) {
+			continue
+		}
+
+		holiday.CountryCode = countryCode
+		if holiday.LocalName == "" {
+			holiday.LocalName = holiday.Name
+		}
+		if len(holiday.Counties) == 0 {
+			holiday.Global = true
+		}
+		holidays = append(holidays, holiday)
+	}
+	// a file may list two holidays on one date, which the cache and the store keep as one entry
+	holidays = MergeByDate(holidays)
+
+	p.logger.Info("Loaded holidays from file",
+		"path", p.location.path,
+		"year", year,
+		"count", len(holidays))
+	return holidays, nil
+}
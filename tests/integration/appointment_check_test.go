@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppointmentCheck_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Monday 2030-03-04, 08:00 at the office
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now),
+		services.WithBookingWindow(services.BookingWindow{MaxDaysAhead: 30}))
+	appointmentService := services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), holidayService, logger,
+		services.WithPersonLimit(1))
+
+	router := http.NewServeMux()
+	routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := asAdmin(httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	booking := func(firstName, date, start string) string {
+		return fmt.Sprintf(`{"firstName":%q,"lastName":"Doe","visitDate":%q,"startTime":%q}`, firstName, date, start)
+	}
+
+	// returns whether the booking is bookable and the rules it breaks, in order
+	check := func(t *testing.T, body string) (bool, []string) {
+		w := request("POST", "/appointments/check", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var result apiModels.CheckAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result.Body))
+		var rules []string
+		for _, violation := range result.Body.Violations {
+			rules = append(rules, violation.Rule)
+		}
+		return result.Body.Bookable, rules
+	}
+
+	t.Run("ReportsEveryViolation", func(t *testing.T) {
+		// a Saturday beyond the horizon, after closing time, for someone without a first name
+		bookable, rules := check(t, booking("", "2030-04-06", "16:30"))
+		assert.False(t, bookable)
+		assert.Equal(t, []string{"name", "opening_hours", "weekday", "booking_window"}, rules)
+
+		w := request("POST", "/appointments", booking("", "2030-04-06", "16:30"))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 1, "a booking is rejected with the first violation only")
+		assert.Equal(t, "body.firstName", problem.Errors[0].Location)
+	})
+
+	t.Run("Bookable", func(t *testing.T) {
+		bookable, rules := check(t, booking("John", "2030-03-06", "09:00"))
+		assert.True(t, bookable)
+		assert.Empty(t, rules)
+
+		w := request("GET", "/appointments?from=2030-03-06&to=2030-03-06", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list apiModels.ListAppointmentsOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list.Body))
+		assert.Zero(t, list.Body.Total, "a dry run books nothing")
+	})
+
+	t.Run("PersonLimit", func(t *testing.T) {
+		w := request("POST", "/appointments", booking("John", "2030-03-06", "09:00"))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		bookable, rules := check(t, booking("john", "2030-03-07", "10:00"))
+		assert.False(t, bookable)
+		assert.Equal(t, []string{"person_limit"}, rules)
+
+		w = request("POST", "/appointments", booking("John", "2030-03-07", "10:00"))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "PERSON_LIMIT_REACHED", problem.Errors[0].Code)
+
+		w = request("POST", "/appointments", booking("Jane", "2030-03-07", "10:00"))
+		assert.Equal(t, http.StatusOK, w.Code, "the limit applies per person")
+	})
+
+	t.Run("AvailabilityReasons", func(t *testing.T) {
+		w := request("GET", "/availability?from=2030-04-06&to=2030-04-06", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var availability apiModels.GetAvailabilityOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &availability.Body))
+		require.Len(t, availability.Body.Days, 1)
+		assert.Equal(t, "weekend", availability.Body.Days[0].Reason)
+		assert.Equal(t, []string{"weekend", "too_far"}, availability.Body.Days[0].Reasons)
+	})
+}
@@ -3,6 +3,7 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -15,22 +16,41 @@ import (
 	"citynext/internal/api/routes"
 	"citynext/internal/database"
 	"citynext/internal/services"
+	"citynext/pkg/client"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// stand-in for the Nager.Date API so the suite does not depend on the network
+func newNagerStub(t *testing.T) *httptest.Server {
+	nager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(nager.Close)
+	return nager
+}
+
 func TestAppointmentAPI_Integration(t *testing.T) {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
+	nager := newNagerStub(t)
+
+	// Monday 2030-03-04, 08:00 at the office
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+
 	// Setup in-memory repository for testing
 	repo := database.NewMemoryAppointmentRepository(logger)
-	holidayService := services.NewHolidayService("https://date.nager.at/api/v3", logger)
-	appointmentService := services.NewAppointmentService(repo, holidayService, logger)
+	holidayService := services.NewHolidayService(client.NewHolidayClient(nager.URL, logger), logger,
+		services.WithClock(now))
+	appointmentService := services.NewAppointmentService(repo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()))
 	appointmentHandler := handlers.NewAppointmentHandler(appointmentService, logger)
 
 	router := http.NewServeMux()
-	routes.RegisterRoutes(router, appointmentHandler)
+	routes.RegisterRoutes(router, appointmentHandler, handlers.NewAdminAuth(adminToken, logger))
 
 	createDate := func(t time.Time) apiModels.Date {
 		return apiModels.Date{Time: t.UTC()}
@@ -49,7 +69,34 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 		return dates
 	}
 
-	weekdays := nextWeekdays(time.Now(), 3)
+	weekdays := nextWeekdays(now(), 9)
+	nineAM := apiModels.NewTimeOfDay(9, 0)
+
+	// books an appointment and returns the recorded response
+	createAppointment := func(firstName, lastName string, date apiModels.Date, start apiModels.TimeOfDay) *httptest.ResponseRecorder {
+		requestBody := apiModels.CreateAppointmentInput{}
+		requestBody.Body.FirstName = firstName
+		requestBody.Body.LastName = lastName
+		requestBody.Body.VisitDate = date
+		requestBody.Body.StartTime = start
+
+		bodyBytes, _ := json.Marshal(requestBody.Body)
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// moves an appointment to a new date and returns the recorded response
+	rescheduleAppointment := func(id uint, date apiModels.Date, start apiModels.TimeOfDay) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"visitDate":%q,"startTime":%q}`, date.String(), start.String())
+		req := asAdmin(httptest.NewRequest("PATCH", fmt.Sprintf("/appointments/%d", id), bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
 
 	t.Run("CreateAppointment_Success", func(t *testing.T) {
 		futureDate := weekdays[0]
@@ -57,6 +104,7 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 		requestBody.Body.FirstName = "John"
 		requestBody.Body.LastName = "Doe"
 		requestBody.Body.VisitDate = futureDate
+		requestBody.Body.StartTime = nineAM
 
 		bodyBytes, _ := json.Marshal(requestBody.Body)
 		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
@@ -76,12 +124,13 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 		assert.NotZero(t, response.Body.ID)
 	})
 
-	t.Run("CreateAppointment_DuplicateDate", func(t *testing.T) {
+	t.Run("CreateAppointment_DuplicateSlot", func(t *testing.T) {
 		futureDate := weekdays[1]
 		requestBody := apiModels.CreateAppointmentInput{}
 		requestBody.Body.FirstName = "Jane"
 		requestBody.Body.LastName = "Smith"
 		requestBody.Body.VisitDate = futureDate
+		requestBody.Body.StartTime = nineAM
 
 		bodyBytes, _ := json.Marshal(requestBody.Body)
 		// First request should succeed
@@ -91,12 +140,31 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 		router.ServeHTTP(w1, req1)
 		assert.Equal(t, http.StatusOK, w1.Code)
 
-		// Second request with same date should fail
+		// Second request for the same slot should fail
 		req2 := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
 		req2.Header.Set("Content-Type", "application/json")
 		w2 := httptest.NewRecorder()
 		router.ServeHTTP(w2, req2)
-		assert.Equal(t, http.StatusUnprocessableEntity, w2.Code)
+		assert.Equal(t, http.StatusConflict, w2.Code)
+
+		var problem struct {
+			Errors []struct {
+				Code     string `json:"code"`
+				Location string `json:"location"`
+			} `json:"errors"`
+			SuggestedDates []string `json:"suggestedDates"`
+		}
+		err := json.Unmarshal(w2.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, "DUPLICATE_BOOKING", problem.Errors[0].Code)
+			assert.Equal(t, "body.startTime", problem.Errors[0].Location)
+		}
+
+		// the date still has other free slots, so it is offered first
+		if assert.Len(t, problem.SuggestedDates, 3) {
+			assert.Equal(t, futureDate.String(), problem.SuggestedDates[0])
+		}
 	})
 
 	t.Run("CreateAppointment_InvalidInput", func(t *testing.T) {
@@ -117,11 +185,12 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 	})
 
 	t.Run("CreateAppointment_PastDate", func(t *testing.T) {
-		pastDate := createDate(time.Now().AddDate(0, 0, -1)) // yesterday
+		pastDate := createDate(now().AddDate(0, 0, -1)) // yesterday
 		requestBody := apiModels.CreateAppointmentInput{}
 		requestBody.Body.FirstName = "John"
 		requestBody.Body.LastName = "Doe"
 		requestBody.Body.VisitDate = pastDate
+		requestBody.Body.StartTime = nineAM
 
 		bodyBytes, _ := json.Marshal(requestBody.Body)
 		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
@@ -131,29 +200,418 @@ func TestAppointmentAPI_Integration(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+		var problem struct {
+			Errors []struct {
+				Code     string `json:"code"`
+				Location string `json:"location"`
+			} `json:"errors"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, "DATE_IN_PAST", problem.Errors[0].Code)
+			assert.Equal(t, "body.visitDate", problem.Errors[0].Location)
+		}
 	})
 
 	t.Run("CreateAppointment_WeekendDate", func(t *testing.T) {
 		// Find the next Saturday (weekday 6)
-		now := time.Now()
-		daysUntilSaturday := (6 - int(now.Weekday()) + 7) % 7
+		today := now()
+		daysUntilSaturday := (6 - int(today.Weekday()) + 7) % 7
 		if daysUntilSaturday == 0 {
 			daysUntilSaturday = 7 // If today is Saturday, use next Saturday
 		}
-		weekendDate := createDate(now.AddDate(0, 0, daysUntilSaturday))
+		weekendDate := createDate(today.AddDate(0, 0, daysUntilSaturday))
+		monday := createDate(today.AddDate(0, 0, daysUntilSaturday+2))
 
 		requestBody := apiModels.CreateAppointmentInput{}
 		requestBody.Body.FirstName = "John"
 		requestBody.Body.LastName = "Doe"
 		requestBody.Body.VisitDate = weekendDate
+		requestBody.Body.StartTime = nineAM
+
+		bodyBytes, _ := json.Marshal(requestBody.Body)
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var problem struct {
+			Detail         string   `json:"detail"`
+			SuggestedDates []string `json:"suggestedDates"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		assert.Equal(t, "The office is not open on this day of the week", problem.Detail)
+		if assert.Len(t, problem.SuggestedDates, 3) {
+			assert.Equal(t, monday.String(), problem.SuggestedDates[0])
+		}
+	})
+
+	t.Run("GetAppointment_Success", func(t *testing.T) {
+		requestBody := apiModels.CreateAppointmentInput{}
+		requestBody.Body.FirstName = "Alice"
+		requestBody.Body.LastName = "Walker"
+		requestBody.Body.VisitDate = weekdays[3]
+		requestBody.Body.StartTime = nineAM
 
 		bodyBytes, _ := json.Marshal(requestBody.Body)
 		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var created apiModels.CreateAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+
+		req2 := asAdmin(httptest.NewRequest("GET", fmt.Sprintf("/appointments/%d", created.Body.ID), nil))
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, req2)
+		assert.Equal(t, http.StatusOK, w2.Code)
+
+		var fetched apiModels.GetAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w2.Body.Bytes(), &fetched.Body))
+		assert.Equal(t, created.Body.ID, fetched.Body.ID)
+		assert.Equal(t, "Alice", fetched.Body.FirstName)
+		assert.Equal(t, weekdays[3].String(), fetched.Body.VisitDate.String())
+	})
+
+	t.Run("GetAppointment_NotFound", func(t *testing.T) {
+		req := asAdmin(httptest.NewRequest("GET", "/appointments/9999", nil))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ListAppointments_Filters", func(t *testing.T) {
+		requestBody := apiModels.CreateAppointmentInput{}
+		requestBody.Body.FirstName = "Bob"
+		requestBody.Body.LastName = "Walker"
+		requestBody.Body.VisitDate = weekdays[4]
+		requestBody.Body.StartTime = nineAM
+
+		bodyBytes, _ := json.Marshal(requestBody.Body)
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// both Walkers, first page only
+		req2 := asAdmin(httptest.NewRequest("GET", "/appointments?lastName=walker&pageSize=1", nil))
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, req2)
+		assert.Equal(t, http.StatusOK, w2.Code)
+
+		var page apiModels.ListAppointmentsOutput
+		assert.NoError(t, json.Unmarshal(w2.Body.Bytes(), &page.Body))
+		assert.Equal(t, int64(2), page.Body.Total)
+		assert.Len(t, page.Body.Items, 1)
+		assert.Equal(t, "Alice", page.Body.Items[0].FirstName)
+
+		// date range narrowed to Bob's visit
+		url := fmt.Sprintf("/appointments?lastName=Walker&from=%s&to=%s", weekdays[4].String(), weekdays[4].String())
+		req3 := asAdmin(httptest.NewRequest("GET", url, nil))
+		w3 := httptest.NewRecorder()
+		router.ServeHTTP(w3, req3)
+		assert.Equal(t, http.StatusOK, w3.Code)
+
+		var narrowed apiModels.ListAppointmentsOutput
+		assert.NoError(t, json.Unmarshal(w3.Body.Bytes(), &narrowed.Body))
+		assert.Equal(t, int64(1), narrowed.Body.Total)
+		assert.Equal(t, "Bob", narrowed.Body.Items[0].FirstName)
+	})
+
+	t.Run("ListAppointments_InvertedRange", func(t *testing.T) {
+		url := fmt.Sprintf("/appointments?from=%s&to=%s", weekdays[4].String(), weekdays[0].String())
+		req := asAdmin(httptest.NewRequest("GET", url, nil))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
 
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("CancelAppointment_FreesDate", func(t *testing.T) {
+		requestBody := apiModels.CreateAppointmentInput{}
+		requestBody.Body.FirstName = "Carol"
+		requestBody.Body.LastName = "King"
+		requestBody.Body.VisitDate = weekdays[5]
+		requestBody.Body.StartTime = nineAM
+
+		bodyBytes, _ := json.Marshal(requestBody.Body)
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
 
+		var created apiModels.CreateAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+
+		cancelBody := []byte(`{"cancelledBy":"front-desk","reason":"Citizen request"}`)
+		cancelURL := fmt.Sprintf("/appointments/%d", created.Body.ID)
+		req2 := asAdmin(httptest.NewRequest("DELETE", cancelURL, bytes.NewBuffer(cancelBody)))
+		req2.Header.Set("Content-Type", "application/json")
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, req2)
+		assert.Equal(t, http.StatusOK, w2.Code)
+
+		var cancelled apiModels.CancelAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w2.Body.Bytes(), &cancelled.Body))
+		assert.Equal(t, "cancelled", cancelled.Body.Status)
+		assert.Equal(t, "front-desk", cancelled.Body.CancelledBy)
+		assert.Equal(t, "Citizen request", cancelled.Body.CancellationReason)
+		assert.Equal(t, "2030-03-04T08:00:00Z", cancelled.Body.CancelledAt, "stamped by the office clock")
+
+		// cancelling twice is a conflict
+		req3 := asAdmin(httptest.NewRequest("DELETE", cancelURL, bytes.NewBuffer(cancelBody)))
+		req3.Header.Set("Content-Type", "application/json")
+		w3 := httptest.NewRecorder()
+		router.ServeHTTP(w3, req3)
+		assert.Equal(t, http.StatusConflict, w3.Code)
+
+		// the date can be booked again
+		req4 := httptest.NewRequest("POST", "/appointments", bytes.NewBuffer(bodyBytes))
+		req4.Header.Set("Content-Type", "application/json")
+		w4 := httptest.NewRecorder()
+		router.ServeHTTP(w4, req4)
+		assert.Equal(t, http.StatusOK, w4.Code)
+	})
+
+	t.Run("CancelAppointment_NotFound", func(t *testing.T) {
+		req := asAdmin(httptest.NewRequest("DELETE", "/appointments/9999", bytes.NewBufferString(`{"cancelledBy":"front-desk"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("RescheduleAppointment_Success", func(t *testing.T) {
+		w := createAppointment("Dan", "Brown", weekdays[6], nineAM)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var created apiModels.CreateAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+
+		w2 := rescheduleAppointment(created.Body.ID, weekdays[7], nineAM)
+		assert.Equal(t, http.StatusOK, w2.Code)
+
+		var moved apiModels.RescheduleAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w2.Body.Bytes(), &moved.Body))
+		assert.Equal(t, created.Body.ID, moved.Body.ID)
+		assert.Equal(t, weekdays[7].String(), moved.Body.VisitDate.String())
+
+		// the old date is free again
+		assert.Equal(t, http.StatusOK, createAppointment("Eve", "Adams", weekdays[6], nineAM).Code)
+	})
+
+	t.Run("RescheduleAppointment_KeepsOldDateWhenNewDateRejected", func(t *testing.T) {
+		w := createAppointment("Fay", "Green", weekdays[8], nineAM)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var created apiModels.CreateAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+
+		// taken by Dan's rescheduled appointment
+		assert.Equal(t, http.StatusConflict, rescheduleAppointment(created.Body.ID, weekdays[7], nineAM).Code)
+
+		// in the past
+		yesterday := createDate(now().AddDate(0, 0, -1))
+		assert.Equal(t, http.StatusUnprocessableEntity, rescheduleAppointment(created.Body.ID, yesterday, nineAM).Code)
+
+		req := asAdmin(httptest.NewRequest("GET", fmt.Sprintf("/appointments/%d", created.Body.ID), nil))
+		w2 := httptest.NewRecorder()
+		router.ServeHTTP(w2, req)
+
+		var fetched apiModels.GetAppointmentOutput
+		assert.NoError(t, json.Unmarshal(w2.Body.Bytes(), &fetched.Body))
+		assert.Equal(t, weekdays[8].String(), fetched.Body.VisitDate.String())
+	})
+
+	t.Run("RescheduleAppointment_NotFound", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, rescheduleAppointment(9999, weekdays[8], nineAM).Code)
+	})
+
+	t.Run("CreateAppointment_SameDateDifferentSlots", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, createAppointment("Gus", "Hall", weekdays[1], apiModels.NewTimeOfDay(9, 15)).Code)
+		assert.Equal(t, http.StatusOK, createAppointment("Hal", "Ives", weekdays[1], apiModels.NewTimeOfDay(16, 15)).Code)
+	})
+
+	t.Run("CreateAppointment_OutsideOpeningHours", func(t *testing.T) {
+		// before opening, after the last slot, and off the slot grid
+		assert.Equal(t, http.StatusUnprocessableEntity, createAppointment("Ian", "Jones", weekdays[1], apiModels.NewTimeOfDay(8, 45)).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, createAppointment("Ian", "Jones", weekdays[1], apiModels.NewTimeOfDay(16, 30)).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, createAppointment("Ian", "Jones", weekdays[1], apiModels.NewTimeOfDay(10, 5)).Code)
+	})
+
+	t.Run("StaffRoutes_AdminTokenRequired", func(t *testing.T) {
+		w := createAppointment("Jan", "King", weekdays[1], apiModels.NewTimeOfDay(11, 0))
+		require.Equal(t, http.StatusOK, w.Code, "booking stays public")
+		var created apiModels.CreateAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		path := fmt.Sprintf("/appointments/%d", created.Body.ID)
+
+		// IDs can be guessed, so none of these may work without the admin token
+		staff := []struct{ method, path, body string }{
+			{"GET", "/appointments", ""},
+			{"GET", path, ""},
+			{"PATCH", path, fmt.Sprintf(`{"visitDate":%q,"startTime":"11:00"}`, weekdays[2])},
+			{"DELETE", path, `{"cancelledBy":"someone"}`},
+		}
+		for _, route := range staff {
+			for name, header := range map[string]string{"Missing": "", "Wrong": "Bearer not-the-token"} {
+				req := httptest.NewRequest(route.method, route.path, bytes.NewBufferString(route.body))
+				req.Header.Set("Content-Type", "application/json")
+				if header != "" {
+					req.Header.Set("Authorization", header)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusUnauthorized, w.Code, route.method+" "+route.path+" "+name)
+				assert.Contains(t, w.Body.String(), `"code":"ADMIN_TOKEN_REQUIRED"`, route.method+" "+route.path+" "+name)
+			}
+		}
+
+		req := asAdmin(httptest.NewRequest("GET", path, nil))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched apiModels.GetAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched.Body))
+		assert.Equal(t, weekdays[1].String(), fetched.Body.VisitDate.String(), "the rejected reschedule changed nothing")
+		assert.Empty(t, fetched.Body.CancelledAt, "the rejected cancellation changed nothing")
+	})
+}
+
+func TestAppointmentAPI_DailyCapacity(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Monday 2030-03-04, 08:00 at the office, and a training day on the Wednesday
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	date := mustDate(t, "2030-03-06")
+
+	repo := database.NewMemoryAppointmentRepository(logger)
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now))
+	appointmentService := services.NewAppointmentService(repo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()),
+		services.WithDailyCapacity(services.DailyCapacity{
+			Default:   30,
+			Overrides: map[string]int{date.String(): 2},
+		}))
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentService, logger)
+
+	router := http.NewServeMux()
+	routes.RegisterRoutes(router, appointmentHandler, handlers.NewAdminAuth(adminToken, logger))
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := asAdmin(httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	book := func(date apiModels.Date, start apiModels.TimeOfDay) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":%q}`, date.String(), start.String())
+		return send("POST", "/appointments", body)
+	}
+
+	// returns the codes of a problem response and the dates it suggests instead
+	rejection := func(t *testing.T, w *httptest.ResponseRecorder) ([]string, []string) {
+		var problem struct {
+			Errors []struct {
+				Code string `json:"code"`
+			} `json:"errors"`
+			SuggestedDates []string `json:"suggestedDates"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		var codes []string
+		for _, e := range problem.Errors {
+			codes = append(codes, e.Code)
+		}
+		return codes, problem.SuggestedDates
+	}
+
+	assert.Equal(t, http.StatusOK, book(date, apiModels.NewTimeOfDay(9, 0)).Code)
+	assert.Equal(t, http.StatusOK, book(date, apiModels.NewTimeOfDay(9, 15)).Code)
+
+	t.Run("FullyBookedSuggestsDates", func(t *testing.T) {
+		w := book(date, apiModels.NewTimeOfDay(9, 30))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, "third booking exceeds the override")
+
+		codes, suggestions := rejection(t, w)
+		assert.Equal(t, []string{"DATE_FULLY_BOOKED"}, codes)
+		if assert.Len(t, suggestions, 3) {
+			assert.NotContains(t, suggestions, date.String(), "the full date is not offered")
+		}
+	})
+
+	t.Run("RescheduleSuggestsDates", func(t *testing.T) {
+		later := apiModels.Date{Time: date.AddDate(0, 0, 14)}
+		w := book(later, apiModels.NewTimeOfDay(10, 0))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var created apiModels.CreateAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+
+		w = send("PATCH", fmt.Sprintf("/appointments/%d", created.Body.ID),
+			fmt.Sprintf(`{"visitDate":%q,"startTime":"10:00"}`, date.String()))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+		codes, suggestions := rejection(t, w)
+		assert.Equal(t, []string{"DATE_FULLY_BOOKED"}, codes)
+		assert.Len(t, suggestions, 3)
+	})
+
+	t.Run("Availability", func(t *testing.T) {
+		to := apiModels.Date{Time: date.AddDate(0, 0, 7)}
+		req := httptest.NewRequest("GET", fmt.Sprintf("/availability?from=%s&to=%s", date, to), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Days []struct {
+				Date      string `json:"date"`
+				Bookable  bool   `json:"bookable"`
+				Reason    string `json:"reason"`
+				Remaining int    `json:"remaining"`
+			} `json:"days"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Days, 8)
+
+		assert.Equal(t, date.String(), response.Days[0].Date)
+		assert.False(t, response.Days[0].Bookable)
+		assert.Equal(t, "full", response.Days[0].Reason)
+
+		// any 8-day window contains a weekend
+		var weekends int
+		for _, day := range response.Days[1:] {
+			if day.Reason == "weekend" {
+				weekends++
+				continue
+			}
+			assert.True(t, day.Bookable, day.Date)
+			assert.Equal(t, 30, day.Remaining, day.Date)
+		}
+		assert.Equal(t, 2, weekends)
+	})
+
+	t.Run("Availability_InvertedRange", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/availability?from=2030-01-10&to=2030-01-01", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
 		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 	})
 }
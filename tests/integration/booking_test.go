@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingSelfService_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Monday 2030-03-04, 08:00 at the office, booking for the Wednesday
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	date := mustDate(t, "2030-03-06")
+
+	repo := database.NewMemoryAppointmentRepository(logger)
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now))
+	appointmentService := services.NewAppointmentService(repo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()))
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentService, logger)
+
+	router := http.NewServeMux()
+	routes.RegisterRoutes(router, appointmentHandler, handlers.NewAdminAuth(adminToken, logger))
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if token != "" {
+			req.Header.Set("X-Management-Token", token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/appointments", "", fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":"09:00"}`, date))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var created apiModels.CreateAppointmentOutput
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+	assert.Regexp(t, `^CN-[0-9A-Z]{4}-[0-9A-Z]{4}$`, created.Body.Reference)
+	assert.NotEmpty(t, created.Body.ManagementToken)
+
+	reference := created.Body.Reference
+	token := created.Body.ManagementToken
+
+	t.Run("Get", func(t *testing.T) {
+		w := request("GET", "/bookings/"+strings.ToLower(reference), token, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var fetched apiModels.GetBookingOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched.Body))
+		assert.Equal(t, created.Body.ID, fetched.Body.ID)
+		assert.Equal(t, reference, fetched.Body.Reference)
+
+		// the token is only handed out at creation
+		assert.NotContains(t, w.Body.String(), token)
+	})
+
+	t.Run("Get_WrongToken", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request("GET", "/bookings/"+reference, "not-the-token", "").Code)
+	})
+
+	t.Run("Get_MissingToken", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, request("GET", "/bookings/"+reference, "", "").Code)
+	})
+
+	t.Run("Get_UnknownReference", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request("GET", "/bookings/CN-0000-0000", token, "").Code)
+	})
+
+	t.Run("Reschedule", func(t *testing.T) {
+		w := request("PATCH", "/bookings/"+reference, token, fmt.Sprintf(`{"visitDate":%q,"startTime":"10:30"}`, date))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var moved apiModels.RescheduleBookingOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved.Body))
+		assert.Equal(t, "10:30", moved.Body.StartTime.String())
+	})
+
+	t.Run("Reschedule_WrongToken", func(t *testing.T) {
+		w := request("PATCH", "/bookings/"+reference, "not-the-token", fmt.Sprintf(`{"visitDate":%q,"startTime":"11:00"}`, date))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		w := request("DELETE", "/bookings/"+reference, token, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var cancelled apiModels.CancelBookingOutput
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cancelled.Body))
+		assert.Equal(t, "cancelled", cancelled.Body.Status)
+		assert.Equal(t, services.CancelledByCitizen, cancelled.Body.CancelledBy)
+
+		assert.Equal(t, http.StatusConflict, request("DELETE", "/bookings/"+reference, token, `{"reason":"again"}`).Code)
+	})
+}
+
+func TestAppointmentRepository_ReferenceCollision(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	date := mustDate(t, "2030-03-04")
+	booking := func(reference string, start apiModels.TimeOfDay) *dbModels.Appointment {
+		return &dbModels.Appointment{Reference: reference, FirstName: "John", LastName: "Doe", VisitDate: date, StartTime: start}
+	}
+
+	for name, newRepo := range concurrencyRepositories {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo(t, logger)
+			require.NoError(t, repo.CreateWithinCapacity(context.Background(), booking("CN-AAAA-AAAA", apiModels.NewTimeOfDay(9, 0)), 30, database.PersonLimit{}))
+
+			err := repo.CreateWithinCapacity(context.Background(), booking("CN-AAAA-AAAA", apiModels.NewTimeOfDay(9, 15)), 30, database.PersonLimit{})
+			assert.ErrorIs(t, err, database.ErrReferenceTaken, "a free slot under a taken reference is not a double booking")
+			assert.NotErrorIs(t, err, database.ErrDuplicateAppointment)
+
+			err = repo.Create(context.Background(), booking("CN-AAAA-AAAA", apiModels.NewTimeOfDay(9, 30)))
+			assert.ErrorIs(t, err, database.ErrReferenceTaken)
+
+			err = repo.CreateWithinCapacity(context.Background(), booking("CN-BBBB-BBBB", apiModels.NewTimeOfDay(9, 0)), 30, database.PersonLimit{})
+			assert.ErrorIs(t, err, database.ErrDuplicateAppointment)
+		})
+	}
+}
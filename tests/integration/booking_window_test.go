@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingWindow_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Monday 2030-03-04, 08:00 at the office
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now),
+		services.WithBookingWindow(services.BookingWindow{MinNotice: 48 * time.Hour, MaxDaysAhead: 30}))
+	appointmentService := services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), holidayService, logger)
+
+	router := http.NewServeMux()
+	routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := asAdmin(httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// returns the code and location of a problem response's single error
+	problemError := func(t *testing.T, w *httptest.ResponseRecorder) (string, string) {
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 1)
+		return problem.Errors[0].Code, problem.Errors[0].Location
+	}
+
+	booking := func(date, start string) string {
+		return fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":%q}`, date, start)
+	}
+	moveTo := func(date, start string) string {
+		return fmt.Sprintf(`{"visitDate":%q,"startTime":%q}`, date, start)
+	}
+
+	w := request("POST", "/appointments", booking("2030-03-06", "09:00"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var created apiModels.CreateAppointmentOutput
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+	path := fmt.Sprintf("/appointments/%d", created.Body.ID)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		code     string
+		location string
+	}{
+		{"CreateWithoutNotice", "POST", "/appointments", booking("2030-03-05", "10:00"), "NOTICE_TOO_SHORT", "body.startTime"},
+		{"CreateBeyondHorizon", "POST", "/appointments", booking("2030-04-04", "10:00"), "DATE_TOO_FAR", "body.visitDate"},
+		{"RescheduleWithoutNotice", "PATCH", path, moveTo("2030-03-05", "14:00"), "NOTICE_TOO_SHORT", "body.startTime"},
+		{"RescheduleBeyondHorizon", "PATCH", path, moveTo("2030-04-04", "10:00"), "DATE_TOO_FAR", "body.visitDate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.method, tt.path, tt.body)
+			require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+			code, location := problemError(t, w)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.location, location)
+		})
+	}
+
+	t.Run("Availability", func(t *testing.T) {
+		w := request("GET", "/availability?from=2030-04-02&to=2030-04-04", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var availability apiModels.GetAvailabilityOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &availability.Body))
+		require.Len(t, availability.Body.Days, 3)
+		assert.True(t, availability.Body.Days[1].Bookable, "the last day of the horizon")
+		assert.Equal(t, "too_far", availability.Body.Days[2].Reason)
+	})
+}
@@ -0,0 +1,257 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendar_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// a holiday for England, one for Scotland only and two nationwide ones
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[
+			{"date":"2030-04-22","localName":"Easter Monday","name":"Easter Monday","countryCode":"GB","global":false,"counties":["GB-ENG","GB-WLS","GB-NIR"],"types":["Public"]},
+			{"date":"2030-12-02","localName":"Saint Andrew's Day","name":"Saint Andrew's Day","countryCode":"GB","global":false,"counties":["GB-SCT"],"types":["Public"]},
+			{"date":"2030-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true,"types":["Public"]},
+			{"date":"2030-12-26","localName":"Boxing Day","name":"St. Stephen's Day","countryCode":"GB","global":true,"types":["Public"]}
+		]`)
+	}))
+	t.Cleanup(provider.Close)
+
+	closureRepo := database.NewMemoryClosureRepository(logger)
+	openingRepo := database.NewMemoryExtraOpeningRepository(logger)
+
+	// Monday 2030-12-02 at the office
+	now := func() time.Time { return time.Date(2030, 12, 2, 10, 0, 0, 0, time.UTC) }
+	holidayService := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger), logger,
+		services.WithClock(now),
+		services.WithClosures(closureRepo),
+		services.WithExtraOpenings(openingRepo))
+	calendarSource, ok := holidayService.(services.CalendarSource)
+	require.True(t, ok)
+
+	router := http.NewServeMux()
+	api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(
+		services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), holidayService, logger,
+			services.WithOfficeClock(now, services.DefaultOfficeLocation())), logger), handlers.NewAdminAuth(adminToken, logger))
+	routes.RegisterCalendarRoutes(api, handlers.NewCalendarHandler(calendarSource, logger))
+
+	request := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	ctx := context.Background()
+	require.NoError(t, closureRepo.Create(ctx, &dbModels.Closure{StartDate: mustDate(t, "2030-12-24"), EndDate: mustDate(t, "2030-12-24"), Reason: "Christmas Eve", HalfDay: true}))
+	require.NoError(t, openingRepo.Create(ctx, &dbModels.ExtraOpening{StartDate: mustDate(t, "2030-12-21"), EndDate: mustDate(t, "2030-12-21"), Reason: "Saturday surgery",
+		Opens: apiModels.NewTimeOfDay(9, 0), Closes: apiModels.NewTimeOfDay(12, 0), Capacity: 5}))
+
+	t.Run("Holidays", func(t *testing.T) {
+		w := request("/holidays?year=2030", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+		etag := w.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		var body struct {
+			Year     int                     `json:"year"`
+			Holidays []apiModels.HolidayBody `json:"holidays"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, 2030, body.Year)
+		require.Len(t, body.Holidays, 3, "Saint Andrew's Day is not observed in England")
+		assert.Equal(t, "2030-04-22", body.Holidays[0].Date.String())
+		assert.Equal(t, []string{"GB-ENG", "GB-WLS", "GB-NIR"}, body.Holidays[0].Counties)
+		assert.Equal(t, []string{"Public"}, body.Holidays[0].Types)
+		assert.Equal(t, "St. Stephen's Day", body.Holidays[2].Name)
+		assert.Equal(t, "Boxing Day", body.Holidays[2].LocalName)
+		assert.True(t, body.Holidays[2].Global)
+
+		w = request("/holidays?year=2030", etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+		w = request("/holidays?year=2030", `"outdated", W/`+etag)
+		assert.Equal(t, http.StatusNotModified, w.Code, "any listed ETag matches, weak or not")
+
+		w = request("/holidays?year=2031", etag)
+		assert.Equal(t, http.StatusOK, w.Code, "another year has another ETag")
+
+		w = request("/holidays", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		// only years around today are fetched, however many a client asks for
+		for _, year := range []string{"2028", "2032", "2200"} {
+			w = request("/holidays?year="+year, "")
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, year)
+			assert.Contains(t, w.Body.String(), `"code":"YEAR_OUT_OF_RANGE"`, year)
+		}
+		w = request("/holidays?year=2029", "")
+		assert.Equal(t, http.StatusOK, w.Code, "last year can still be listed")
+	})
+
+	t.Run("Calendar", func(t *testing.T) {
+		// Friday 2030-12-20 to Sunday 2030-12-29
+		w := request("/calendar?from=2030-12-20&to=2030-12-29", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+		etag := w.Header().Get("ETag")
+
+		var body struct {
+			Entries []apiModels.CalendarEntryBody `json:"entries"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		type entry struct{ date, reason, name string }
+		var entries []entry
+		for _, e := range body.Entries {
+			entries = append(entries, entry{e.Date.String(), e.Reason, e.Name})
+		}
+		assert.Equal(t, []entry{
+			{"2030-12-22", "weekend", ""},
+			{"2030-12-24", "closed", "Christmas Eve"},
+			{"2030-12-25", "holiday", "Christmas Day"},
+			{"2030-12-26", "holiday", "St. Stephen's Day"},
+			{"2030-12-28", "weekend", ""},
+			{"2030-12-29", "weekend", ""},
+		}, entries, "the Saturday with an extra opening is not shut")
+		assert.True(t, body.Entries[1].HalfDay)
+		if assert.NotNil(t, body.Entries[2].Holiday) {
+			assert.Equal(t, []string{"Public"}, body.Entries[2].Holiday.Types)
+		}
+
+		w = request("/calendar?from=2030-12-20&to=2030-12-29", etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+
+		// a new closure changes the calendar, and so its ETag
+		require.NoError(t, closureRepo.Create(ctx, &dbModels.Closure{StartDate: mustDate(t, "2030-12-27"), EndDate: mustDate(t, "2030-12-27"), Reason: "Stocktake"}))
+		w = request("/calendar?from=2030-12-20&to=2030-12-29", etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("WorkingDays", func(t *testing.T) {
+		// Monday 2030-12-23; Christmas and Boxing Day are holidays, and the Saturday extra opening does not count
+		w := request("/calendar/working-days?from=2030-12-23&days=3&to=2030-12-31", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var body struct {
+			From               string `json:"from"`
+			IsWorkingDay       bool   `json:"isWorkingDay"`
+			NextWorkingDay     string `json:"nextWorkingDay"`
+			Days               int    `json:"days"`
+			Date               string `json:"date"`
+			WorkingDaysBetween *int   `json:"workingDaysBetween"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.IsWorkingDay)
+		assert.Equal(t, "2030-12-24", body.NextWorkingDay)
+		assert.Equal(t, "2030-12-30", body.Date)
+		if assert.NotNil(t, body.WorkingDaysBetween) {
+			assert.Equal(t, 4, *body.WorkingDaysBetween)
+		}
+
+		w = request("/calendar/working-days?from=2030-12-25&days=-1", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		body.WorkingDaysBetween = nil
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.False(t, body.IsWorkingDay)
+		assert.Equal(t, "2030-12-27", body.NextWorkingDay)
+		assert.Equal(t, "2030-12-24", body.Date)
+		assert.Nil(t, body.WorkingDaysBetween, "only counted when 'to' is given")
+
+		w = request("/calendar/working-days?days=1000", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		w = request("/calendar/working-days?from=2030-01-01&to=2032-01-01", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "DATE_RANGE_TOO_LONG")
+
+		// no holidays are looked up for years far from today
+		for _, from := range []string{"0002-01-01", "9999-12-31", "2032-01-01"} {
+			w = request("/calendar/working-days?from="+from, "")
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, from)
+			assert.Contains(t, w.Body.String(), `"code":"DATE_OUT_OF_RANGE"`, from)
+		}
+	})
+
+	t.Run("NoWorkingDay", func(t *testing.T) {
+		// an office that never opens has no working day to move to
+		closed := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger), logger,
+			services.WithClock(now),
+			services.WithWeeklySchedule(services.WeeklySchedule{}))
+		router := http.NewServeMux()
+		api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(
+			services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), closed, logger), logger), handlers.NewAdminAuth(adminToken, logger))
+		routes.RegisterCalendarRoutes(api, handlers.NewCalendarHandler(closed.(services.CalendarSource), logger))
+
+		req := httptest.NewRequest("GET", "/calendar/working-days?from=2030-12-23&days=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"NO_WORKING_DAY"`)
+	})
+
+	t.Run("InvalidRanges", func(t *testing.T) {
+		w := request("/calendar?from=2030-12-29&to=2030-12-20", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_DATE_RANGE")
+
+		w = request("/calendar?from=2030-01-01&to=2031-01-02", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "DATE_RANGE_TOO_LONG")
+
+		w = request("/calendar?from=2031-12-20&to=2032-01-10", "")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"YEAR_OUT_OF_RANGE","message":"year is outside the years holidays are listed for","location":"query.to"`)
+	})
+
+	t.Run("HolidaysUnavailable", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(down.Close)
+
+		// even the open policy must not publish a calendar without its holidays
+		unavailable := services.NewHolidayService(client.NewHolidayClient(down.URL, logger, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1})), logger,
+			services.WithClock(now),
+			services.WithHolidayFailPolicy(services.HolidayFailOpen))
+		router := http.NewServeMux()
+		api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(
+			services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), unavailable, logger), logger), handlers.NewAdminAuth(adminToken, logger))
+		routes.RegisterCalendarRoutes(api, handlers.NewCalendarHandler(unavailable.(services.CalendarSource), logger))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/calendar?from=2030-12-20&to=2030-12-29", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "HOLIDAY_DATA_UNAVAILABLE")
+	})
+}
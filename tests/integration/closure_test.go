@@ -0,0 +1,212 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClosures_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	appointmentRepo := database.NewSQLiteAppointmentRepository(db, logger)
+	closureRepo := database.NewSQLiteClosureRepository(db, logger)
+	hours := services.DefaultOpeningHours()
+
+	// Monday 2030-03-04, 08:00 at the office
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now),
+		services.WithClosures(closureRepo))
+	appointmentService := services.NewAppointmentService(appointmentRepo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()))
+	closureService := services.NewClosureService(closureRepo, appointmentRepo, hours, services.DefaultHalfDayClosingTime(), logger)
+
+	router := http.NewServeMux()
+	api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+	routes.RegisterClosureRoutes(api, handlers.NewClosureHandler(closureService, logger), handlers.NewAdminAuth(adminToken, logger))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// two consecutive weekdays after the Monday the tests run on
+	first := mustDate(t, "2030-03-06")
+	second := mustDate(t, "2030-03-07")
+
+	booking := func(date apiModels.Date, start string) string {
+		return fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":%q}`, date, start)
+	}
+
+	// booked before the closures exist
+	w := request("POST", "/appointments", booking(first, "10:00"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request("POST", "/appointments", booking(second, "09:00"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request("POST", "/appointments", booking(second, "14:00"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var closureID uint
+
+	t.Run("CreateReportsAffectedAppointments", func(t *testing.T) {
+		w := request("POST", "/closures", fmt.Sprintf(`{"startDate":%q,"reason":"Staff training"}`, first))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var created apiModels.CreateClosureOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		closureID = created.Body.ID
+		assert.Equal(t, first, created.Body.EndDate, "a single-day closure ends on its start date")
+		assert.False(t, created.Body.HalfDay)
+		require.Len(t, created.Body.AffectedAppointments, 1)
+		assert.Equal(t, "10:00", created.Body.AffectedAppointments[0].StartTime.String())
+	})
+
+	t.Run("BookingOnClosedDateRejected", func(t *testing.T) {
+		w := request("POST", "/appointments", booking(first, "11:00"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"OFFICE_CLOSED"`)
+		assert.Contains(t, w.Body.String(), `"location":"body.visitDate"`)
+		assert.Contains(t, w.Body.String(), `"suggestedDates"`)
+	})
+
+	t.Run("HalfDayClosure", func(t *testing.T) {
+		w := request("POST", "/closures", fmt.Sprintf(`{"startDate":%q,"endDate":%q,"reason":"Election count","halfDay":true}`, second, second))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var created apiModels.CreateClosureOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		require.Len(t, created.Body.AffectedAppointments, 1, "only afternoon appointments are affected")
+		assert.Equal(t, "14:00", created.Body.AffectedAppointments[0].StartTime.String())
+
+		w = request("POST", "/appointments", booking(second, "11:45"))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = request("POST", "/appointments", booking(second, "12:00"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"OFFICE_CLOSED"`)
+		assert.Contains(t, w.Body.String(), `"location":"body.startTime"`)
+	})
+
+	t.Run("Availability", func(t *testing.T) {
+		w := request("GET", fmt.Sprintf("/availability?from=%s&to=%s", first, second), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var days apiModels.GetAvailabilityOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &days.Body))
+		require.Len(t, days.Body.Days, 2)
+
+		assert.False(t, days.Body.Days[0].Bookable)
+		assert.Equal(t, "closed", days.Body.Days[0].Reason)
+		assert.Equal(t, "Staff training", days.Body.Days[0].Closure)
+
+		// 12 morning slots, one taken by 09:00, one by 11:45 and one still held by the 14:00 booking
+		assert.True(t, days.Body.Days[1].Bookable)
+		assert.True(t, days.Body.Days[1].HalfDay)
+		assert.Equal(t, "Election count", days.Body.Days[1].Closure)
+		assert.Equal(t, 9, days.Body.Days[1].Remaining)
+	})
+
+	t.Run("ListAndGet", func(t *testing.T) {
+		w := request("GET", fmt.Sprintf("/closures?from=%s&to=%s", first, first), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list apiModels.ListClosuresOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list.Body))
+		require.Len(t, list.Body.Items, 1)
+		assert.Equal(t, closureID, list.Body.Items[0].ID)
+
+		w = request("GET", fmt.Sprintf("/closures/%d", closureID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"reason":"Staff training"`)
+
+		w = request("GET", "/closures/9999", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"CLOSURE_NOT_FOUND"`)
+	})
+
+	t.Run("AdminTokenRequired", func(t *testing.T) {
+		body := fmt.Sprintf(`{"startDate":%q,"reason":"Staff training"}`, first)
+		for name, header := range map[string]string{"Missing": "", "Wrong": "Bearer not-the-token", "NotBearer": "Basic " + adminToken} {
+			req := httptest.NewRequest("POST", "/closures", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"), name)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"), name)
+			assert.Contains(t, w.Body.String(), `"code":"ADMIN_TOKEN_REQUIRED"`, name)
+		}
+
+		req := httptest.NewRequest("GET", "/closures", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "listing closures stays public")
+
+		operation := api.OpenAPI().Paths["/closures"].Post
+		assert.Equal(t, []map[string][]string{{handlers.AdminSecurityScheme: {}}}, operation.Security)
+		assert.NotNil(t, operation.Responses["401"])
+	})
+
+	t.Run("InvalidClosures", func(t *testing.T) {
+		w := request("POST", "/closures", fmt.Sprintf(`{"startDate":%q,"endDate":%q,"reason":"Refurbishment"}`, second, first))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"INVALID_DATE_RANGE"`)
+
+		w = request("POST", "/closures", fmt.Sprintf(`{"startDate":%q,"reason":"   "}`, first))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"location":"body.reason"`)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		w := request("PUT", fmt.Sprintf("/closures/%d", closureID), fmt.Sprintf(`{"startDate":%q,"reason":"Staff training","halfDay":true}`, first))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated apiModels.UpdateClosureOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated.Body))
+		assert.True(t, updated.Body.HalfDay)
+		assert.Empty(t, updated.Body.AffectedAppointments, "the 10:00 booking is before the half-day closing time")
+
+		w = request("POST", "/appointments", booking(first, "11:00"))
+		assert.Equal(t, http.StatusOK, w.Code, "morning slots reopen on a half-day closure")
+
+		w = request("DELETE", fmt.Sprintf("/closures/%d", closureID), "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = request("POST", "/appointments", booking(first, "15:00"))
+		assert.Equal(t, http.StatusOK, w.Code, "deleting the closure reopens the date")
+
+		w = request("DELETE", fmt.Sprintf("/closures/%d", closureID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
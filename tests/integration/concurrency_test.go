@@ -0,0 +1,258 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// number of goroutines competing for the same slot
+const concurrentBookings = 20
+
+// builds a fresh repository of each kind for every test
+var concurrencyRepositories = map[string]func(t *testing.T, logger *slog.Logger) database.AppointmentRepository{
+	"Memory": func(t *testing.T, logger *slog.Logger) database.AppointmentRepository {
+		return database.NewMemoryAppointmentRepository(logger)
+	},
+	"SQLite": func(t *testing.T, logger *slog.Logger) database.AppointmentRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { database.CloseConnection(db) })
+		return database.NewSQLiteAppointmentRepository(db, logger)
+	},
+}
+
+// runs fn from n goroutines released at the same moment and returns their errors
+func runConcurrently(n int, fn func(i int) error) []error {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// asserts that exactly one call succeeded and every other one was rejected as a duplicate
+func assertSingleWinner(t *testing.T, errs []error) {
+	var succeeded int
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, database.ErrDuplicateAppointment)
+	}
+	assert.Equal(t, 1, succeeded, "exactly one booking should win the slot")
+}
+
+func TestAppointmentRepository_Concurrency(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	date := apiModels.Date{Time: time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)}
+	nineAM := apiModels.NewTimeOfDay(9, 0)
+
+	newAppointment := func(i int, date apiModels.Date, start apiModels.TimeOfDay) *dbModels.Appointment {
+		return &dbModels.Appointment{
+			FirstName: fmt.Sprintf("Citizen%d", i),
+			LastName:  "Doe",
+			VisitDate: date,
+			StartTime: start,
+		}
+	}
+
+	for name, newRepo := range concurrencyRepositories {
+		t.Run(name, func(t *testing.T) {
+
+			t.Run("Create", func(t *testing.T) {
+				repo := newRepo(t, logger)
+				errs := runConcurrently(concurrentBookings, func(i int) error {
+					return repo.Create(context.Background(), newAppointment(i, date, nineAM))
+				})
+				assertSingleWinner(t, errs)
+			})
+
+			t.Run("CreateWithinCapacity", func(t *testing.T) {
+				repo := newRepo(t, logger)
+				errs := runConcurrently(concurrentBookings, func(i int) error {
+					return repo.CreateWithinCapacity(context.Background(), newAppointment(i, date, nineAM), 30, database.PersonLimit{})
+				})
+				assertSingleWinner(t, errs)
+
+				counts, err := repo.CountByDateRange(context.Background(), date, date)
+				require.NoError(t, err)
+				assert.Equal(t, 1, counts[date.String()])
+			})
+
+			t.Run("PersonLimit", func(t *testing.T) {
+				repo := newRepo(t, logger)
+				limit := database.PersonLimit{Max: 2, From: date}
+
+				// the same person, however the name is cased, competing for a slot each
+				errs := runConcurrently(concurrentBookings, func(i int) error {
+					appointment := newAppointment(0, date, nineAM.Add(time.Duration(i)*15*time.Minute))
+					if i%2 == 1 {
+						appointment.FirstName, appointment.LastName = "CITIZEN0", "doe"
+					}
+					return repo.CreateWithinCapacity(context.Background(), appointment, 100, limit)
+				})
+				var succeeded int
+				for _, err := range errs {
+					if err == nil {
+						succeeded++
+						continue
+					}
+					assert.ErrorIs(t, err, database.ErrPersonLimitReached)
+				}
+				assert.Equal(t, limit.Max, succeeded, "no more bookings than the limit should win")
+
+				// someone else is not held back, and appointments before From do not count
+				afterRace := nineAM.Add(concurrentBookings * 15 * time.Minute)
+				require.NoError(t, repo.CreateWithinCapacity(context.Background(), newAppointment(1, date, afterRace), 100, limit))
+				later := database.PersonLimit{Max: 2, From: apiModels.Date{Time: date.AddDate(0, 0, 1)}}
+				require.NoError(t, repo.CreateWithinCapacity(context.Background(), newAppointment(0, later.From, nineAM), 100, later))
+			})
+
+			t.Run("Reschedule", func(t *testing.T) {
+				repo := newRepo(t, logger)
+
+				// one appointment per slot from 09:15 onwards, all moving to 09:00 at once
+				ids := make([]uint, concurrentBookings)
+				for i := range ids {
+					appointment := newAppointment(i, date, nineAM.Add(time.Duration(i+1)*15*time.Minute))
+					require.NoError(t, repo.CreateWithinCapacity(context.Background(), appointment, 100, database.PersonLimit{}))
+					ids[i] = appointment.ID
+				}
+
+				errs := runConcurrently(concurrentBookings, func(i int) error {
+					_, err := repo.Reschedule(context.Background(), ids[i], date, nineAM, 100, false)
+					return err
+				})
+				assertSingleWinner(t, errs)
+			})
+		})
+	}
+}
+
+func TestAppointmentAPI_ConcurrentBookings(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Monday 2030-03-04, 08:00 at the office, booking for the Wednesday
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	date := mustDate(t, "2030-03-06")
+
+	repo := concurrencyRepositories["SQLite"](t, logger)
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger,
+		services.WithClock(now))
+	appointmentService := services.NewAppointmentService(repo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()))
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentService, logger)
+
+	router := http.NewServeMux()
+	routes.RegisterRoutes(router, appointmentHandler, handlers.NewAdminAuth(adminToken, logger))
+
+	codes := make([]int, concurrentBookings)
+	runConcurrently(concurrentBookings, func(i int) error {
+		body := fmt.Sprintf(`{"firstName":"Citizen%d","lastName":"Doe","visitDate":%q,"startTime":"09:00"}`, i, date.String())
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes[i] = w.Code
+		return nil
+	})
+
+	var created, conflicts int
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	assert.Equal(t, 1, created)
+	assert.Equal(t, concurrentBookings-1, conflicts)
+}
+
+func TestExtraOpeningRepository_Concurrency(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	repositories := map[string]func(t *testing.T) database.ExtraOpeningRepository{
+		"Memory": func(t *testing.T) database.ExtraOpeningRepository {
+			return database.NewMemoryExtraOpeningRepository(logger)
+		},
+		"SQLite": func(t *testing.T) database.ExtraOpeningRepository {
+			db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { database.CloseConnection(db) })
+			return database.NewSQLiteExtraOpeningRepository(db, logger)
+		},
+	}
+
+	for name, newRepo := range repositories {
+		t.Run(name+"/OverlappingOpenings", func(t *testing.T) {
+			repo := newRepo(t)
+			saturday := mustDate(t, "2030-03-09")
+
+			// every opening covers the Saturday, starting on a different day before it
+			errs := runConcurrently(concurrentBookings, func(i int) error {
+				return repo.Create(context.Background(), &dbModels.ExtraOpening{
+					StartDate: apiModels.Date{Time: saturday.AddDate(0, 0, -i)},
+					EndDate:   saturday,
+					Reason:    fmt.Sprintf("Opening %d", i),
+					Opens:     apiModels.NewTimeOfDay(9, 0),
+					Closes:    apiModels.NewTimeOfDay(12, 0),
+					Capacity:  5,
+				})
+			})
+
+			var created int
+			for _, err := range errs {
+				if err == nil {
+					created++
+					continue
+				}
+				assert.ErrorIs(t, err, database.ErrExtraOpeningOverlap)
+			}
+			assert.Equal(t, 1, created, "exactly one opening should claim the date")
+
+			openings, err := repo.ListOverlapping(context.Background(), saturday, saturday)
+			require.NoError(t, err)
+			assert.Len(t, openings, 1)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraOpenings_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	// Monday 2030-03-04, 08:00 at the office; the Saturday after it, and a bank holiday on the following Monday
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	saturday := mustDate(t, "2030-03-09")
+	bankHoliday := mustDate(t, "2030-03-11")
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"date":%q,"localName":"Bank Holiday","name":"Bank Holiday","countryCode":"GB","global":true,"types":["Public"]}]`, bankHoliday)
+	}))
+	t.Cleanup(provider.Close)
+
+	appointmentRepo := database.NewSQLiteAppointmentRepository(db, logger)
+	openingRepo := database.NewSQLiteExtraOpeningRepository(db, logger)
+	hours := services.DefaultOpeningHours()
+
+	holidayService := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger), logger,
+		services.WithClock(now),
+		services.WithExtraOpenings(openingRepo))
+	appointmentService := services.NewAppointmentService(appointmentRepo, holidayService, logger,
+		services.WithOfficeClock(now, services.DefaultOfficeLocation()))
+
+	router := http.NewServeMux()
+	api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+	routes.RegisterExtraOpeningRoutes(api, handlers.NewExtraOpeningHandler(services.NewExtraOpeningService(openingRepo, hours, logger), logger), handlers.NewAdminAuth(adminToken, logger))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	booking := func(date apiModels.Date, start string) string {
+		return fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":%q}`, date, start)
+	}
+
+	w := request("POST", "/appointments", booking(saturday, "10:00"))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DATE_IS_WEEKEND"`)
+	w = request("POST", "/appointments", booking(bankHoliday, "10:00"))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"DATE_IS_HOLIDAY"`)
+
+	var surgeryID uint
+
+	t.Run("SaturdaySurgery", func(t *testing.T) {
+		w := request("POST", "/extra-openings", fmt.Sprintf(`{"startDate":%q,"reason":"Saturday surgery","opens":"10:00","closes":"12:00","capacity":2}`, saturday))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var created apiModels.CreateExtraOpeningOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		surgeryID = created.Body.ID
+		assert.Equal(t, saturday, created.Body.EndDate)
+
+		w = request("POST", "/appointments", booking(saturday, "09:00"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"OUTSIDE_OPENING_HOURS"`, "the extra opening has its own hours")
+
+		w = request("POST", "/appointments", booking(saturday, "10:00"))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = request("GET", fmt.Sprintf("/availability?from=%s&to=%s", saturday, saturday), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var days apiModels.GetAvailabilityOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &days.Body))
+		require.Len(t, days.Body.Days, 1)
+		assert.True(t, days.Body.Days[0].Bookable)
+		assert.Equal(t, "Saturday surgery", days.Body.Days[0].ExtraOpening)
+		assert.Equal(t, 1, days.Body.Days[0].Remaining, "the extra opening has its own capacity")
+
+		w = request("POST", "/appointments", booking(saturday, "10:15"))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = request("POST", "/appointments", booking(saturday, "10:30"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"DATE_FULLY_BOOKED"`)
+	})
+
+	t.Run("BankHolidayOpening", func(t *testing.T) {
+		w := request("POST", "/extra-openings", fmt.Sprintf(`{"startDate":%q,"reason":"Backlog clearance","opens":"09:00","closes":"16:30","capacity":30}`, bankHoliday))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = request("POST", "/appointments", booking(bankHoliday, "15:00"))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("OverlapRejected", func(t *testing.T) {
+		w := request("POST", "/extra-openings", fmt.Sprintf(`{"startDate":%q,"endDate":%q,"reason":"Weekend","opens":"09:00","closes":"12:00","capacity":5}`, saturday, saturday.AddDate(0, 0, 1).Format("2006-01-02")))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"EXTRA_OPENING_OVERLAP"`)
+
+		// replacing an extra opening does not conflict with itself
+		w = request("PUT", fmt.Sprintf("/extra-openings/%d", surgeryID), fmt.Sprintf(`{"startDate":%q,"reason":"Saturday surgery","opens":"10:00","closes":"13:00","capacity":3}`, saturday))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"closes":"13:00"`)
+	})
+
+	t.Run("AdminTokenRequired", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/extra-openings/%d", surgeryID), nil)
+		req.Header.Set("Authorization", "Bearer not-the-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"ADMIN_TOKEN_REQUIRED"`)
+
+		req = httptest.NewRequest("GET", fmt.Sprintf("/extra-openings/%d", surgeryID), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "the opening is still there, and reading it needs no token")
+	})
+
+	t.Run("InvalidHours", func(t *testing.T) {
+		w := request("POST", "/extra-openings", fmt.Sprintf(`{"startDate":%q,"reason":"Sunday","opens":"12:00","closes":"10:00","capacity":5}`, saturday.AddDate(0, 0, 1).Format("2006-01-02")))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"location":"body.closes"`)
+	})
+
+	t.Run("DeleteRestoresWeekendRule", func(t *testing.T) {
+		w := request("DELETE", fmt.Sprintf("/extra-openings/%d", surgeryID), "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = request("POST", "/appointments", booking(saturday, "11:00"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"DATE_IS_WEEKEND"`)
+
+		w = request("GET", fmt.Sprintf("/extra-openings/%d", surgeryID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"EXTRA_OPENING_NOT_FOUND"`)
+	})
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	outage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(outage.Close)
+
+	holidayService := services.NewHolidayService(client.NewHolidayClient(outage.URL, logger,
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}),
+		client.WithBreakerConfig(client.BreakerConfig{FailureThreshold: 1, Cooldown: time.Hour})), logger)
+
+	router := http.NewServeMux()
+	api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(
+		services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), holidayService, logger), logger), handlers.NewAdminAuth(adminToken, logger))
+	routes.RegisterHealthRoutes(api, handlers.NewHealthHandler(holidayService.(services.ProviderStater), logger))
+
+	health := func() apiModels.GetHealthOutput {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var output apiModels.GetHealthOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &output.Body))
+		return output
+	}
+
+	healthy := health()
+	assert.Equal(t, "ok", healthy.Body.Status)
+	assert.Equal(t, "closed", healthy.Body.HolidayProvider)
+
+	_, err := holidayService.IsPublicHoliday(context.Background(), mustDate(t, "2030-12-25"))
+	require.Error(t, err)
+
+	degraded := health()
+	assert.Equal(t, "degraded", degraded.Body.Status)
+	assert.Equal(t, "open", degraded.Body.HolidayProvider)
+}
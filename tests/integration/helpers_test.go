@@ -0,0 +1,28 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// admin token the tests of staff endpoints authenticate with
+const adminToken = "test-admin-token"
+
+// authenticates a request to a staff endpoint with the admin token
+func asAdmin(req *http.Request) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	return req
+}
+
+// parses a YYYY-MM-DD date, failing the test when it is not one
+func mustDate(t testing.TB, s string) apiModels.Date {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("invalid test date %q: %v", s, err)
+	}
+	return apiModels.Date{Time: d}
+}
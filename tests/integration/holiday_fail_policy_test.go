@@ -0,0 +1,220 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"citynext/internal/api/handlers"
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayFailPolicy_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	// Monday 2030-03-04, 08:00 at the office, booking for the Wednesday
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	date := mustDate(t, "2030-03-06")
+
+	// declares the visit date a holiday, but only once it is back up
+	var down atomic.Bool
+	down.Store(true)
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"date":%q,"localName":"Surprise Holiday","name":"Surprise Holiday","countryCode":"GB","global":true,"types":["Public"]}]`, date)
+	}))
+	t.Cleanup(provider.Close)
+
+	// a single attempt per lookup keeps the outage cheap
+	noRetries := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1})
+
+	newRouter := func(repo database.AppointmentRepository, holidayService services.HolidayServiceInterface) *http.ServeMux {
+		appointmentService := services.NewAppointmentService(repo, holidayService, logger,
+			services.WithOfficeClock(now, services.DefaultOfficeLocation()))
+		router := http.NewServeMux()
+		routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+		return router
+	}
+
+	request := func(router *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+		req := asAdmin(httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	booking := func(start string) string {
+		return fmt.Sprintf(`{"firstName":"John","lastName":"Doe","visitDate":%q,"startTime":%q}`, date, start)
+	}
+	availability := fmt.Sprintf("/availability?from=%s&to=%s", date, date)
+
+	t.Run("Closed", func(t *testing.T) {
+		holidayService := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger, noRetries), logger,
+			services.WithClock(now),
+			services.WithHolidayFailPolicy(services.HolidayFailClosed))
+		router := newRouter(database.NewMemoryAppointmentRepository(logger), holidayService)
+
+		w := request(router, "POST", "/appointments", booking("09:00"))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"HOLIDAY_DATA_UNAVAILABLE"`)
+
+		w = request(router, "GET", availability, "")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"HOLIDAY_DATA_UNAVAILABLE"`)
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		repo := database.NewSQLiteAppointmentRepository(db, logger)
+		holidayService := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger, noRetries), logger,
+			services.WithClock(now),
+			services.WithHolidayFailPolicy(services.HolidayFailOpen))
+		router := newRouter(repo, holidayService)
+
+		w := request(router, "POST", "/appointments", booking("09:00"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var created apiModels.CreateAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		assert.True(t, created.Body.HolidayUnverified)
+
+		w = request(router, "GET", availability, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var days apiModels.GetAvailabilityOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &days.Body))
+		require.Len(t, days.Body.Days, 1)
+		assert.True(t, days.Body.Days[0].Bookable)
+		assert.True(t, days.Body.Days[0].HolidayUnverified)
+
+		revalidator := services.NewHolidayRevalidator(repo, holidayService, time.Hour, logger)
+
+		t.Run("RevalidationWaitsForProvider", func(t *testing.T) {
+			report, err := revalidator.RevalidateOnce(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 0, report.Checked)
+			assert.Equal(t, 1, report.Remaining)
+		})
+
+		t.Run("RevalidationReportsHoliday", func(t *testing.T) {
+			down.Store(false)
+			t.Cleanup(func() { down.Store(true) })
+
+			report, err := revalidator.RevalidateOnce(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, report.Checked)
+			assert.Equal(t, 0, report.Remaining)
+			require.Len(t, report.OnHoliday, 1)
+			assert.Equal(t, created.Body.ID, report.OnHoliday[0].ID)
+			assert.True(t, report.OnHoliday[0].HolidayConflict)
+
+			flagged, err := repo.ListHolidayUnverified(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, flagged)
+
+			w := request(router, "GET", fmt.Sprintf("/appointments/%d", created.Body.ID), "")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.NotContains(t, w.Body.String(), "holidayUnverified")
+
+			var fetched apiModels.GetAppointmentOutput
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched.Body))
+			assert.True(t, fetched.Body.HolidayConflict, "the conflict outlives the revalidation pass")
+		})
+	})
+
+	t.Run("OpenWithExtraOpening", func(t *testing.T) {
+		repo := database.NewMemoryAppointmentRepository(logger)
+		openings := database.NewMemoryExtraOpeningRepository(logger)
+		holidayService := services.NewHolidayService(client.NewHolidayClient(provider.URL, logger, noRetries), logger,
+			services.WithClock(now),
+			services.WithExtraOpenings(openings),
+			services.WithHolidayFailPolicy(services.HolidayFailOpen))
+		router := newRouter(repo, holidayService)
+
+		w := request(router, "POST", "/appointments", booking("09:00"))
+		require.Equal(t, http.StatusOK, w.Code)
+		var created apiModels.CreateAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created.Body))
+		require.True(t, created.Body.HolidayUnverified)
+
+		// staff open the office on the date before the holiday turns up
+		require.NoError(t, openings.Create(context.Background(), &dbModels.ExtraOpening{
+			StartDate: date,
+			EndDate:   date,
+			Reason:    "Surprise holiday surgery",
+			Opens:     apiModels.NewTimeOfDay(9, 0),
+			Closes:    apiModels.NewTimeOfDay(12, 0),
+			Capacity:  5,
+		}))
+		down.Store(false)
+		t.Cleanup(func() { down.Store(true) })
+
+		report, err := services.NewHolidayRevalidator(repo, holidayService, time.Hour, logger).RevalidateOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Checked)
+		assert.Empty(t, report.OnHoliday, "the extra opening lifts the holiday")
+
+		w = request(router, "GET", fmt.Sprintf("/appointments/%d", created.Body.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched apiModels.GetAppointmentOutput
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched.Body))
+		assert.False(t, fetched.Body.HolidayUnverified)
+		assert.False(t, fetched.Body.HolidayConflict)
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		// the last successful fetch is far older than the cache TTL
+		store := database.NewMemoryHolidayRepository(logger)
+		require.NoError(t, store.ReplaceYear(context.Background(), "GB", date.Year(), []dbModels.Holiday{{
+			CountryCode: "GB",
+			Year:        date.Year(),
+			Date:        date,
+			LocalName:   "Surprise Holiday",
+			Name:        "Surprise Holiday",
+			Global:      true,
+			FetchedAt:   now().AddDate(0, -2, 0),
+		}}))
+
+		stale := newRouter(database.NewMemoryAppointmentRepository(logger), services.NewHolidayService(client.NewHolidayClient(provider.URL, logger, noRetries), logger,
+			services.WithClock(now),
+			services.WithHolidayStore(store),
+			services.WithHolidayFailPolicy(services.HolidayFailStale)))
+		w := request(stale, "POST", "/appointments", booking("09:00"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"DATE_IS_HOLIDAY"`)
+
+		closed := newRouter(database.NewMemoryAppointmentRepository(logger), services.NewHolidayService(client.NewHolidayClient(provider.URL, logger, noRetries), logger,
+			services.WithClock(now),
+			services.WithHolidayStore(store),
+			services.WithHolidayFailPolicy(services.HolidayFailClosed)))
+		w = request(closed, "POST", "/appointments", booking("09:00"))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
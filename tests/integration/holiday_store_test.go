@@ -0,0 +1,212 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayStore_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+	holidayRepo := database.NewSQLiteHolidayRepository(db, logger)
+
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+
+	// serves Christmas Day and a Scottish bank holiday for every requested year
+	var upstreamCalls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+		parts := strings.Split(r.URL.Path, "/")
+		year := parts[len(parts)-2]
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[
+			{"date":"%[1]s-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true,"types":["Public"]},
+			{"date":"%[1]s-08-05","localName":"Summer Bank Holiday","name":"Summer Bank Holiday","countryCode":"GB","global":false,"counties":["GB-SCT"],"types":["Bank"]}
+		]`, year)
+	}))
+	t.Cleanup(upstream.Close)
+
+	// always fails, as during an outage
+	var outageCalls int32
+	outage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&outageCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(outage.Close)
+
+	// started before anything is stored, so it has nothing preloaded
+	lateService := services.NewHolidayService(client.NewHolidayClient(outage.URL, logger, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1})), logger,
+		services.WithClock(now), services.WithHolidayStore(holidayRepo))
+
+	t.Run("FetchedHolidaysAreStored", func(t *testing.T) {
+		service := services.NewHolidayService(client.NewHolidayClient(upstream.URL, logger), logger, services.WithClock(now), services.WithHolidayStore(holidayRepo))
+
+		isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, "2030-12-25"))
+		require.NoError(t, err)
+		assert.True(t, isHoliday)
+
+		stored, err := holidayRepo.ListByYear(context.Background(), "GB", 2030)
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+
+		assert.Equal(t, "2030-08-05", stored[0].Date.String())
+		assert.Equal(t, "GB-SCT", stored[0].Counties)
+		assert.False(t, stored[0].Global)
+		assert.Equal(t, "2030-12-25", stored[1].Date.String())
+		assert.Equal(t, "Christmas Day", stored[1].Name)
+		for _, holiday := range stored {
+			assert.Equal(t, "nager:"+upstream.URL, holiday.Source)
+			assert.True(t, now().Equal(holiday.FetchedAt), "stored with the time it was fetched")
+		}
+	})
+
+	t.Run("StoredHolidaysAreLoadedAtStartup", func(t *testing.T) {
+		service := services.NewHolidayService(client.NewHolidayClient(outage.URL, logger), logger, services.WithClock(now), services.WithHolidayStore(holidayRepo))
+		before := atomic.LoadInt32(&outageCalls)
+
+		checks, err := service.CheckDates(context.Background(), mustDate(t, "2030-12-23"), mustDate(t, "2030-12-27"))
+		require.NoError(t, err)
+		assert.Equal(t, services.ErrDateIsHoliday, checks[2].Err)
+		assert.Equal(t, "Christmas Day", checks[2].Holiday.Name)
+		assert.Equal(t, before, atomic.LoadInt32(&outageCalls), "no upstream call for a stored year")
+	})
+
+	t.Run("StoredRegionalHolidaysFollowOfficeRegion", func(t *testing.T) {
+		scotland, err := services.ParseOfficeRegion("GB-SCT")
+		require.NoError(t, err)
+
+		english := services.NewHolidayService(client.NewHolidayClient(outage.URL, logger), logger, services.WithClock(now), services.WithHolidayStore(holidayRepo))
+		scottish := services.NewHolidayService(client.NewHolidayClient(outage.URL, logger), logger, services.WithClock(now), services.WithHolidayStore(holidayRepo), services.WithOfficeRegion(scotland))
+
+		isHoliday, err := english.IsPublicHoliday(context.Background(), mustDate(t, "2030-08-05"))
+		require.NoError(t, err)
+		assert.False(t, isHoliday)
+
+		isHoliday, err = scottish.IsPublicHoliday(context.Background(), mustDate(t, "2030-08-05"))
+		require.NoError(t, err)
+		assert.True(t, isHoliday)
+	})
+
+	t.Run("StoreIsUsedWhenUpstreamFails", func(t *testing.T) {
+		before := atomic.LoadInt32(&outageCalls)
+
+		isHoliday, err := lateService.IsPublicHoliday(context.Background(), mustDate(t, "2030-12-25"))
+		require.NoError(t, err)
+		assert.True(t, isHoliday)
+		assert.Equal(t, before+1, atomic.LoadInt32(&outageCalls), "the upstream is still tried first")
+
+		isHoliday, err = lateService.IsPublicHoliday(context.Background(), mustDate(t, "2030-12-24"))
+		require.NoError(t, err)
+		assert.False(t, isHoliday)
+	})
+
+	t.Run("UnstoredYearFailsWhenUpstreamFails", func(t *testing.T) {
+		_, err := lateService.IsPublicHoliday(context.Background(), mustDate(t, "2035-12-25"))
+		assert.Error(t, err)
+	})
+
+	t.Run("HolidaysSharingADateAreStoredOnce", func(t *testing.T) {
+		// Nager lists regional holidays separately, so one date can come back twice
+		shared := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[
+				{"date":"2036-03-17","localName":"St Patrick's Day","name":"St Patrick's Day","countryCode":"GB","global":false,"counties":["GB-NIR"],"types":["Public"]},
+				{"date":"2036-03-17","localName":"Staff Day","name":"Staff Day","countryCode":"GB","global":false,"counties":["GB-SCT"],"types":["Bank"]}
+			]`)
+		}))
+		t.Cleanup(shared.Close)
+
+		scotland, err := services.ParseOfficeRegion("GB-SCT")
+		require.NoError(t, err)
+		service := services.NewHolidayService(client.NewHolidayClient(shared.URL, logger), logger,
+			services.WithClock(now), services.WithHolidayStore(holidayRepo), services.WithOfficeRegion(scotland))
+
+		isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, "2036-03-17"))
+		require.NoError(t, err)
+		assert.True(t, isHoliday, "the Scottish entry is not lost to the Northern Irish one")
+
+		stored, err := holidayRepo.ListByYear(context.Background(), "GB", 2036)
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, "St Patrick's Day / Staff Day", stored[0].Name)
+		assert.Equal(t, "GB-NIR,GB-SCT", stored[0].Counties)
+		assert.Equal(t, "Public,Bank", stored[0].Types)
+	})
+
+	t.Run("StaleStoredHolidaysAreFetchedAgain", func(t *testing.T) {
+		before := atomic.LoadInt32(&upstreamCalls)
+		later := func() time.Time { return now().Add(services.DefaultHolidayCacheTTL + time.Hour) }
+		service := services.NewHolidayService(client.NewHolidayClient(upstream.URL, logger), logger,
+			services.WithHolidayStore(holidayRepo), services.WithClock(later))
+
+		isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, "2030-12-25"))
+		require.NoError(t, err)
+		assert.True(t, isHoliday)
+		assert.Equal(t, before+1, atomic.LoadInt32(&upstreamCalls), "a stored year older than the TTL is fetched again")
+	})
+}
+
+func TestHolidayStore_SharedDateFromFile(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+	holidayRepo := database.NewSQLiteHolidayRepository(db, logger)
+
+	// a national and a local holiday on the same date, as an office's own holiday file can list them
+	path := filepath.Join(t.TempDir(), "holidays.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- date: "2030-11-30"
+  name: St Andrew's Day
+  counties: [GB-SCT]
+- date: "2030-11-30"
+  name: Staff Day
+- date: "2030-12-25"
+  name: Christmas Day
+`), 0o600))
+
+	provider, err := client.NewProviderFromSpec("file="+path, logger)
+	require.NoError(t, err)
+	service := services.NewHolidayService(provider, logger, services.WithHolidayStore(holidayRepo))
+
+	isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, "2030-11-30"))
+	require.NoError(t, err)
+	assert.True(t, isHoliday)
+
+	stored, err := holidayRepo.ListByYear(context.Background(), "GB", 2030)
+	require.NoError(t, err)
+	require.Len(t, stored, 2, "the shared date is stored once, so the unique index holds")
+	assert.Equal(t, "St Andrew's Day / Staff Day", stored[0].Name)
+	assert.True(t, stored[0].Global)
+
+	// a restart finds the merged entry in the store
+	// listed from within 2030, as only years around today are
+	now := func() time.Time { return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC) }
+	restarted := services.NewHolidayService(provider, logger, services.WithClock(now), services.WithHolidayStore(holidayRepo))
+	holidays, err := restarted.(services.CalendarSource).HolidaysInYear(context.Background(), 2030)
+	require.NoError(t, err)
+	require.Len(t, holidays, 2)
+	assert.Equal(t, "St Andrew's Day / Staff Day", holidays[0].Name)
+}
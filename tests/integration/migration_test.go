@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	"citynext/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacyStartTimes_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "citynext.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	// rows as an earlier schema left them: one active appointment per date, at the start_time default
+	legacy := `INSERT INTO appointments (first_name, last_name, visit_date, created_at, updated_at, cancelled_at) VALUES (?, ?, ?, ?, ?, ?)`
+	now := time.Date(2030, 1, 2, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Exec(legacy, "John", "Doe", "2030-03-06", now, now, nil).Error)
+	require.NoError(t, db.Exec(legacy, "Jane", "Doe", "2030-03-07", now, now, nil).Error)
+	require.NoError(t, db.Exec(legacy, "Old", "Booking", "2030-03-07", now, now, now).Error)
+	// booked after time slots arrived, on the slot the legacy row would otherwise get
+	require.NoError(t, db.Exec(`INSERT INTO appointments (first_name, last_name, visit_date, start_time, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		"Sam", "Smith", "2030-03-07", "09:00", now, now).Error)
+
+	hours := services.DefaultOpeningHours()
+	require.NoError(t, database.MigrateLegacyStartTimes(db, func(apiModels.Date) []apiModels.TimeOfDay { return hours.Slots() }))
+
+	repo := database.NewSQLiteAppointmentRepository(db, logger)
+	appointments, _, err := repo.List(context.Background(), database.AppointmentFilter{IncludeCancelled: true})
+	require.NoError(t, err)
+
+	starts := map[string]string{}
+	for _, appointment := range appointments {
+		assert.True(t, hours.IsSlotStart(appointment.StartTime), "%s has no valid slot", appointment.FirstName)
+		starts[appointment.FirstName] = appointment.StartTime.String()
+	}
+	assert.Equal(t, map[string]string{"John": "09:00", "Jane": "09:15", "Old": "09:00", "Sam": "09:00"}, starts)
+}
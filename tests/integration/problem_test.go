@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"citynext/internal/api/handlers"
+	"citynext/internal/api/routes"
+	"citynext/internal/database"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemResponses_Integration(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newError := reflect.ValueOf(huma.NewError).Pointer()
+
+	holidayService := services.NewHolidayService(client.NewHolidayClient(newNagerStub(t).URL, logger), logger)
+	appointmentService := services.NewAppointmentService(database.NewMemoryAppointmentRepository(logger), holidayService, logger)
+	router := http.NewServeMux()
+	api := routes.RegisterRoutes(router, handlers.NewAppointmentHandler(appointmentService, logger), handlers.NewAdminAuth(adminToken, logger))
+
+	assert.Equal(t, newError, reflect.ValueOf(huma.NewError).Pointer(), "registering routes leaves Huma's globals alone")
+
+	t.Run("RequestValidation", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/appointments", bytes.NewBufferString(`{"firstName":"John"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, "Unprocessable Entity", problem.Title)
+		require.NotEmpty(t, problem.Errors)
+		for _, detail := range problem.Errors {
+			assert.Equal(t, handlers.CodeInvalidRequest, detail.Code)
+		}
+	})
+
+	t.Run("DocumentedAsProblem", func(t *testing.T) {
+		response := api.OpenAPI().Paths["/appointments"].Post.Responses["default"]
+		require.NotNil(t, response)
+		assert.Equal(t, "#/components/schemas/Problem", response.Content["application/problem+json"].Schema.Ref)
+	})
+}
@@ -2,18 +2,23 @@ package unit
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	apiModels "citynext/internal/api/models"
 	"citynext/internal/database"
 	dbModels "citynext/internal/database/models"
+	"citynext/internal/errcode"
 	"citynext/internal/services"
+	"citynext/pkg/client"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // mock implementation of AppointmentRepository
@@ -26,19 +31,85 @@ func (m *MockAppointmentRepository) Create(ctx context.Context, appointment *dbM
 	return args.Error(0)
 }
 
-func (m *MockAppointmentRepository) GetByDate(ctx context.Context, date apiModels.Date) (*dbModels.Appointment, error) {
-	args := m.Called(ctx, date)
+func (m *MockAppointmentRepository) CreateWithinCapacity(ctx context.Context, appointment *dbModels.Appointment, capacity int, limit database.PersonLimit) error {
+	args := m.Called(ctx, appointment, capacity, limit)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) GetByID(ctx context.Context, id uint) (*dbModels.Appointment, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dbModels.Appointment), args.Error(1)
 }
 
-func (m *MockAppointmentRepository) ExistsByDate(ctx context.Context, date apiModels.Date) (bool, error) {
-	args := m.Called(ctx, date)
+func (m *MockAppointmentRepository) GetByReference(ctx context.Context, reference string) (*dbModels.Appointment, error) {
+	args := m.Called(ctx, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dbModels.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) GetBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (*dbModels.Appointment, error) {
+	args := m.Called(ctx, date, start)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dbModels.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) ExistsBySlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) (bool, error) {
+	args := m.Called(ctx, date, start)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAppointmentRepository) List(ctx context.Context, filter database.AppointmentFilter) ([]dbModels.Appointment, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]dbModels.Appointment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAppointmentRepository) CountByDateRange(ctx context.Context, from, to apiModels.Date) (map[string]int, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) Cancel(ctx context.Context, id uint, cancelledBy, reason string, cancelledAt time.Time) (*dbModels.Appointment, error) {
+	args := m.Called(ctx, id, cancelledBy, reason, cancelledAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dbModels.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) Reschedule(ctx context.Context, id uint, newDate apiModels.Date, newStart apiModels.TimeOfDay, capacity int, holidayUnverified bool) (*dbModels.Appointment, error) {
+	args := m.Called(ctx, id, newDate, newStart, capacity, holidayUnverified)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dbModels.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) ListHolidayUnverified(ctx context.Context) ([]dbModels.Appointment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dbModels.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) MarkHolidayVerified(ctx context.Context, id uint, onHoliday bool) error {
+	args := m.Called(ctx, id, onHoliday)
+	return args.Error(0)
+}
+
 // mock implementation of HolidayServiceInterface
 type MockHolidayService struct {
 	mock.Mock
@@ -54,12 +125,72 @@ func (m *MockHolidayService) ValidateDate(ctx context.Context, date apiModels.Da
 	return args.Error(0)
 }
 
+func (m *MockHolidayService) ValidateSlot(ctx context.Context, date apiModels.Date, start apiModels.TimeOfDay) error {
+	args := m.Called(ctx, date, start)
+	return args.Error(0)
+}
+
+func (m *MockHolidayService) CheckDates(ctx context.Context, from, to apiModels.Date) ([]services.DateCheck, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]services.DateCheck), args.Error(1)
+}
+
+func (m *MockHolidayService) OpeningHours() services.OpeningHours {
+	args := m.Called()
+	return args.Get(0).(services.OpeningHours)
+}
+
+func (m *MockHolidayService) CheckRules(ctx context.Context, date apiModels.Date, start *apiModels.TimeOfDay, mode services.RuleMode) (*services.RuleResult, error) {
+	args := m.Called(ctx, date, start, mode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.RuleResult), args.Error(1)
+}
+
+func (m *MockHolidayService) ExtraOpeningOn(ctx context.Context, date apiModels.Date) (*dbModels.ExtraOpening, error) {
+	args := m.Called(ctx, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dbModels.ExtraOpening), args.Error(1)
+}
+
+// a holiday service without extra openings unless a test sets some up
+func newMockHolidayService() *MockHolidayService {
+	m := new(MockHolidayService)
+	m.On("ExtraOpeningOn", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return m
+}
+
+// the outcome of the calendar rules for a slot that breaks the rule err stands for, or none when err is nil;
+// ErrHolidayUnverified stands for a slot accepted without a holiday check
+func calendarResult(err error) *services.RuleResult {
+	switch {
+	case err == nil:
+		return &services.RuleResult{}
+	case errors.Is(err, services.ErrHolidayUnverified):
+		return &services.RuleResult{HolidayUnverified: true}
+	}
+	return &services.RuleResult{Violations: []services.Violation{{Rule: "calendar", Err: errcode.From(err)}}}
+}
+
+// Monday 2030-03-04, 08:00 at the office; the appointment service tests run at this time
+func fixedNow() time.Time {
+	return time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC)
+}
+
+var fixedOfficeClock = services.WithOfficeClock(fixedNow, services.DefaultOfficeLocation())
+
 func TestAppointmentService_CreateAppointment(t *testing.T) {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
 	createDate := func(daysFromNow int) apiModels.Date {
-		return apiModels.Date{Time: time.Now().AddDate(0, 0, daysFromNow).UTC()}
+		return apiModels.Date{Time: fixedNow().AddDate(0, 0, daysFromNow).UTC()}
 	}
 
 	tests := []struct {
@@ -75,17 +206,19 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				FirstName: "John",
 				LastName:  "Doe",
 				VisitDate: createDate(7), // 7 days from now
+				StartTime: apiModels.NewTimeOfDay(9, 30),
 			},
 			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
-				holiday.On("ValidateDate", mock.Anything, mock.Anything).Return(nil)
-				repo.On("ExistsByDate", mock.Anything, mock.Anything).Return(false, nil)
-				repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+				repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+				repo.On("CreateWithinCapacity", mock.Anything, mock.Anything, 30, mock.Anything).Return(nil)
 			},
 			expectedError: nil,
 			expectedResult: &dbModels.Appointment{
 				FirstName: "John",
 				LastName:  "Doe",
 				VisitDate: createDate(7),
+				StartTime: apiModels.NewTimeOfDay(9, 30),
 			},
 		},
 		{
@@ -118,7 +251,7 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				VisitDate: createDate(-1), // yesterday
 			},
 			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
-				holiday.On("ValidateDate", mock.Anything, mock.Anything).Return(services.ErrDateInPast)
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(services.ErrDateInPast), nil)
 			},
 			expectedError:  services.ErrDateInPast,
 			expectedResult: nil,
@@ -131,7 +264,7 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				VisitDate: createDate(7),
 			},
 			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
-				holiday.On("ValidateDate", mock.Anything, mock.Anything).Return(services.ErrDateIsHoliday)
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(services.ErrDateIsHoliday), nil)
 			},
 			expectedError:  services.ErrDateIsHoliday,
 			expectedResult: nil,
@@ -144,9 +277,23 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				VisitDate: createDate(7),
 			},
 			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
-				holiday.On("ValidateDate", mock.Anything, mock.Anything).Return(services.ErrDateIsWeekend)
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(services.ErrClosedWeekday), nil)
+			},
+			expectedError:  services.ErrClosedWeekday,
+			expectedResult: nil,
+		},
+		{
+			name: "Outside Opening Hours",
+			request: &services.CreateAppointmentRequest{
+				FirstName: "John",
+				LastName:  "Doe",
+				VisitDate: createDate(7),
+				StartTime: apiModels.NewTimeOfDay(7, 0),
+			},
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(services.ErrOutsideOpeningHours), nil)
 			},
-			expectedError:  services.ErrDateIsWeekend,
+			expectedError:  services.ErrOutsideOpeningHours,
 			expectedResult: nil,
 		},
 		{
@@ -157,28 +304,45 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				VisitDate: createDate(7),
 			},
 			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
-				holiday.On("ValidateDate", mock.Anything, mock.Anything).Return(nil)
-				repo.On("ExistsByDate", mock.Anything, mock.Anything).Return(true, nil)
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+				repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+				repo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(database.ErrDuplicateAppointment)
 			},
 			expectedError:  database.ErrDuplicateAppointment,
 			expectedResult: nil,
 		},
+		{
+			name: "Date Fully Booked",
+			request: &services.CreateAppointmentRequest{
+				FirstName: "John",
+				LastName:  "Doe",
+				VisitDate: createDate(7),
+				StartTime: apiModels.NewTimeOfDay(9, 30),
+			},
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				holiday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+				repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+				repo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(database.ErrDateFullyBooked)
+			},
+			expectedError:  database.ErrDateFullyBooked,
+			expectedResult: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup mocks
 			mockRepo := new(MockAppointmentRepository)
-			mockHoliday := new(MockHolidayService)
+			mockHoliday := newMockHolidayService()
 			tt.setupMocks(mockRepo, mockHoliday)
 
-			service := services.NewAppointmentService(mockRepo, mockHoliday, logger)
+			service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock)
 
 			result, err := service.CreateAppointment(context.Background(), tt.request)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
@@ -186,6 +350,11 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 				assert.Equal(t, tt.expectedResult.FirstName, result.FirstName)
 				assert.Equal(t, tt.expectedResult.LastName, result.LastName)
 				assert.Equal(t, tt.expectedResult.VisitDate.String(), result.VisitDate.String())
+				assert.Equal(t, tt.expectedResult.StartTime, result.StartTime)
+				assert.Regexp(t, `^CN-[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}$`, result.Reference)
+				assert.NotEmpty(t, result.ManagementToken)
+				assert.NotEmpty(t, result.ManagementTokenHash)
+				assert.NotEqual(t, result.ManagementToken, result.ManagementTokenHash, "only a hash of the token is stored")
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -193,3 +362,671 @@ func TestAppointmentService_CreateAppointment(t *testing.T) {
 		})
 	}
 }
+
+func TestAppointmentService_GetAppointment(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("Found", func(t *testing.T) {
+		mockRepo := new(MockAppointmentRepository)
+		mockRepo.On("GetByID", mock.Anything, uint(7)).Return(&dbModels.Appointment{ID: 7, FirstName: "John", LastName: "Doe"}, nil)
+
+		service := services.NewAppointmentService(mockRepo, newMockHolidayService(), logger, fixedOfficeClock)
+
+		result, err := service.GetAppointment(context.Background(), 7)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := new(MockAppointmentRepository)
+		mockRepo.On("GetByID", mock.Anything, uint(8)).Return(nil, database.ErrAppointmentNotFound)
+
+		service := services.NewAppointmentService(mockRepo, newMockHolidayService(), logger, fixedOfficeClock)
+
+		result, err := service.GetAppointment(context.Background(), 8)
+		assert.Equal(t, database.ErrAppointmentNotFound, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAppointmentService_ListAppointments(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("Translates Page To Offset", func(t *testing.T) {
+		mockRepo := new(MockAppointmentRepository)
+		expectedFilter := database.AppointmentFilter{
+			From:     mustDate(t, "2025-08-01"),
+			To:       mustDate(t, "2025-08-31"),
+			LastName: "Doe",
+			Limit:    10,
+			Offset:   20,
+		}
+		mockRepo.On("List", mock.Anything, expectedFilter).Return([]dbModels.Appointment{{ID: 1}}, int64(21), nil)
+
+		service := services.NewAppointmentService(mockRepo, newMockHolidayService(), logger, fixedOfficeClock)
+
+		result, total, err := service.ListAppointments(context.Background(), &services.ListAppointmentsRequest{
+			From:     mustDate(t, "2025-08-01"),
+			To:       mustDate(t, "2025-08-31"),
+			LastName: " Doe ",
+			Page:     3,
+			PageSize: 10,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, int64(21), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Inverted Date Range", func(t *testing.T) {
+		mockRepo := new(MockAppointmentRepository)
+		service := services.NewAppointmentService(mockRepo, newMockHolidayService(), logger, fixedOfficeClock)
+
+		_, _, err := service.ListAppointments(context.Background(), &services.ListAppointmentsRequest{
+			From:     mustDate(t, "2025-08-31"),
+			To:       mustDate(t, "2025-08-01"),
+			Page:     1,
+			PageSize: 10,
+		})
+		assert.Equal(t, services.ErrInvalidDateRange, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAppointmentService_CancelAppointment(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name          string
+		request       *services.CancelAppointmentRequest
+		setupMocks    func(*MockAppointmentRepository)
+		expectedError error
+	}{
+		{
+			name:    "Success",
+			request: &services.CancelAppointmentRequest{ID: 1, CancelledBy: " front-desk ", Reason: "Citizen request"},
+			setupMocks: func(repo *MockAppointmentRepository) {
+				cancelledAt := fixedNow()
+				// stamped by the office clock
+				repo.On("Cancel", mock.Anything, uint(1), "front-desk", "Citizen request", mock.MatchedBy(cancelledAt.Equal)).
+					Return(&dbModels.Appointment{ID: 1, CancelledAt: &cancelledAt, CancelledBy: "front-desk"}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Missing Cancelled By",
+			request:       &services.CancelAppointmentRequest{ID: 1, CancelledBy: "  "},
+			setupMocks:    func(repo *MockAppointmentRepository) {},
+			expectedError: services.ErrInvalidInput,
+		},
+		{
+			name:    "Already Cancelled",
+			request: &services.CancelAppointmentRequest{ID: 2, CancelledBy: "front-desk"},
+			setupMocks: func(repo *MockAppointmentRepository) {
+				repo.On("Cancel", mock.Anything, uint(2), "front-desk", "", mock.Anything).Return(nil, database.ErrAlreadyCancelled)
+			},
+			expectedError: database.ErrAlreadyCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAppointmentRepository)
+			tt.setupMocks(mockRepo)
+
+			service := services.NewAppointmentService(mockRepo, newMockHolidayService(), logger, fixedOfficeClock)
+
+			result, err := service.CancelAppointment(context.Background(), tt.request)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, result.IsCancelled())
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAppointmentService_RescheduleAppointment(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	oldDate := apiModels.Date{Time: fixedNow().AddDate(0, 0, 7).UTC()}
+	newDate := apiModels.Date{Time: fixedNow().AddDate(0, 0, 14).UTC()}
+	newStart := apiModels.NewTimeOfDay(14, 0)
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockAppointmentRepository, *MockHolidayService)
+		expectedError error
+	}{
+		{
+			name: "Success",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				repo.On("GetByID", mock.Anything, uint(1)).Return(&dbModels.Appointment{ID: 1, VisitDate: oldDate}, nil)
+				holiday.On("CheckRules", mock.Anything, newDate, &newStart, mock.Anything).Return(calendarResult(nil), nil)
+				repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+				repo.On("Reschedule", mock.Anything, uint(1), newDate, newStart, mock.Anything, false).Return(&dbModels.Appointment{ID: 1, VisitDate: newDate, StartTime: newStart}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Not Found",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				repo.On("GetByID", mock.Anything, uint(1)).Return(nil, database.ErrAppointmentNotFound)
+			},
+			expectedError: database.ErrAppointmentNotFound,
+		},
+		{
+			name: "Cancelled",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				cancelledAt := fixedNow()
+				repo.On("GetByID", mock.Anything, uint(1)).Return(&dbModels.Appointment{ID: 1, VisitDate: oldDate, CancelledAt: &cancelledAt}, nil)
+			},
+			expectedError: database.ErrAlreadyCancelled,
+		},
+		{
+			name: "Past Appointment",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				pastDate := apiModels.Date{Time: fixedNow().AddDate(0, 0, -1).UTC()}
+				repo.On("GetByID", mock.Anything, uint(1)).Return(&dbModels.Appointment{ID: 1, VisitDate: pastDate}, nil)
+			},
+			expectedError: services.ErrAppointmentInPast,
+		},
+		{
+			name: "New Date Invalid Keeps Old Slot",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				repo.On("GetByID", mock.Anything, uint(1)).Return(&dbModels.Appointment{ID: 1, VisitDate: oldDate}, nil)
+				holiday.On("CheckRules", mock.Anything, newDate, &newStart, mock.Anything).Return(calendarResult(services.ErrDateIsHoliday), nil)
+			},
+			expectedError: services.ErrDateIsHoliday,
+		},
+		{
+			name: "New Date Taken",
+			setupMocks: func(repo *MockAppointmentRepository, holiday *MockHolidayService) {
+				repo.On("GetByID", mock.Anything, uint(1)).Return(&dbModels.Appointment{ID: 1, VisitDate: oldDate}, nil)
+				holiday.On("CheckRules", mock.Anything, newDate, &newStart, mock.Anything).Return(calendarResult(nil), nil)
+				repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+				repo.On("Reschedule", mock.Anything, uint(1), newDate, newStart, mock.Anything, false).Return(nil, database.ErrDuplicateAppointment)
+			},
+			expectedError: database.ErrDuplicateAppointment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAppointmentRepository)
+			mockHoliday := newMockHolidayService()
+			tt.setupMocks(mockRepo, mockHoliday)
+
+			service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock)
+
+			result, err := service.RescheduleAppointment(context.Background(), &services.RescheduleAppointmentRequest{
+				ID:        1,
+				VisitDate: newDate,
+				StartTime: newStart,
+			})
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, newDate.String(), result.VisitDate.String())
+				assert.Equal(t, newStart, result.StartTime)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockHoliday.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOpeningHours(t *testing.T) {
+
+	hours := services.OpeningHours{
+		Opens:      apiModels.NewTimeOfDay(9, 0),
+		Closes:     apiModels.NewTimeOfDay(16, 30),
+		SlotLength: 15 * time.Minute,
+	}
+
+	assert.NoError(t, hours.Validate())
+
+	slots := hours.Slots()
+	assert.Len(t, slots, 30)
+	assert.Equal(t, "09:00", slots[0].String())
+	assert.Equal(t, "16:15", slots[len(slots)-1].String())
+
+	assert.True(t, hours.IsSlotStart(apiModels.NewTimeOfDay(9, 0)))
+	assert.True(t, hours.IsSlotStart(apiModels.NewTimeOfDay(16, 15)))
+	assert.False(t, hours.IsSlotStart(apiModels.NewTimeOfDay(8, 45)), "before opening")
+	assert.False(t, hours.IsSlotStart(apiModels.NewTimeOfDay(16, 30)), "slot would end after closing")
+	assert.False(t, hours.IsSlotStart(apiModels.NewTimeOfDay(9, 10)), "not on a slot boundary")
+
+	invalid := services.OpeningHours{
+		Opens:      apiModels.NewTimeOfDay(9, 0),
+		Closes:     apiModels.NewTimeOfDay(9, 10),
+		SlotLength: 15 * time.Minute,
+	}
+	assert.Error(t, invalid.Validate())
+}
+
+func TestAppointmentService_CreateAppointment_CapacityOverride(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	trainingDay := apiModels.Date{Time: fixedNow().AddDate(0, 0, 7).UTC()}
+	overrides, err := services.ParseCapacityOverrides(trainingDay.String() + "=2")
+	assert.NoError(t, err)
+
+	mockRepo := new(MockAppointmentRepository)
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+	mockRepo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, 2, mock.Anything).Return(nil)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock,
+		services.WithDailyCapacity(services.DailyCapacity{Default: 10, Overrides: overrides}))
+
+	_, err = service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+		FirstName: "John",
+		LastName:  "Doe",
+		VisitDate: trainingDay,
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockHoliday.AssertExpectations(t)
+}
+
+func TestAppointmentService_CreateAppointment_ReferenceCollision(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	request := &services.CreateAppointmentRequest{
+		FirstName: "John",
+		LastName:  "Doe",
+		VisitDate: apiModels.Date{Time: fixedNow().AddDate(0, 0, 7).UTC()},
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	}
+
+	newService := func(repo *MockAppointmentRepository) *services.AppointmentService {
+		mockHoliday := newMockHolidayService()
+		mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+		repo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+		return services.NewAppointmentService(repo, mockHoliday, logger, fixedOfficeClock)
+	}
+
+	t.Run("RetriesWithNewReference", func(t *testing.T) {
+		var references []string
+		record := func(args mock.Arguments) {
+			references = append(references, args.Get(1).(*dbModels.Appointment).Reference)
+		}
+
+		mockRepo := new(MockAppointmentRepository)
+		mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(database.ErrReferenceTaken).Run(record).Once()
+		mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(record).Once()
+
+		appointment, err := newService(mockRepo).CreateAppointment(context.Background(), request)
+		require.NoError(t, err)
+
+		require.Len(t, references, 2)
+		assert.NotEqual(t, references[0], references[1])
+		assert.Equal(t, references[1], appointment.Reference)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		mockRepo := new(MockAppointmentRepository)
+		mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(database.ErrReferenceTaken).Times(3)
+
+		_, err := newService(mockRepo).CreateAppointment(context.Background(), request)
+		assert.ErrorIs(t, err, database.ErrReferenceTaken)
+		assert.NotErrorIs(t, err, database.ErrDuplicateAppointment)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAppointmentService_PersonLimitCountsFromOfficeToday(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// still Monday in UTC, but already Tuesday at an office in Auckland
+	now := func() time.Time { return time.Date(2030, 3, 4, 20, 0, 0, 0, time.UTC) }
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	require.NoError(t, err)
+
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+	mockRepo := new(MockAppointmentRepository)
+	mockRepo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(filter database.AppointmentFilter) bool {
+		return filter.From.String() == "2030-03-05"
+	})).Return([]dbModels.Appointment{}, int64(0), nil)
+	// the repository counts from the same day when it checks the limit again
+	mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, database.PersonLimit{
+		Max:  1,
+		From: mustDate(t, "2030-03-05"),
+	}).Return(nil)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger,
+		services.WithOfficeClock(now, auckland),
+		services.WithPersonLimit(1))
+
+	_, err = service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+		FirstName: "John",
+		LastName:  "Doe",
+		VisitDate: mustDate(t, "2030-03-11"),
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAppointmentService_PersonLimitCheckedAgainOnInsert(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+	mockRepo := new(MockAppointmentRepository)
+	mockRepo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	// a concurrent booking is not counted yet when the rules run, only inside the insert's transaction
+	mockRepo.On("List", mock.Anything, mock.Anything).Return([]dbModels.Appointment{}, int64(0), nil)
+	mockRepo.On("CreateWithinCapacity", mock.Anything, mock.MatchedBy(func(a *dbModels.Appointment) bool {
+		return a.FirstName == "John" && a.LastName == "Doe"
+	}), mock.Anything, database.PersonLimit{Max: 2, From: mustDate(t, "2030-03-04")}).Return(database.ErrPersonLimitReached)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock,
+		services.WithPersonLimit(2))
+
+	// names are trimmed, so the repository matches them against the stored ones
+	_, err := service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+		FirstName: " John",
+		LastName:  "Doe  ",
+		VisitDate: mustDate(t, "2030-03-11"),
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	})
+	assert.ErrorIs(t, err, database.ErrPersonLimitReached)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAppointmentService_PersonLimitEnforcedWithoutRule(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+	mockRepo := new(MockAppointmentRepository)
+	mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything,
+		database.PersonLimit{Max: 2, From: mustDate(t, "2030-03-04")}).Return(nil)
+
+	// no booking rule counts the person's appointments, the repository still does
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock,
+		services.WithPersonLimit(2),
+		services.WithBookingRules(services.Pipeline{}))
+
+	_, err := service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+		FirstName: "John",
+		LastName:  "Doe",
+		VisitDate: mustDate(t, "2030-03-11"),
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAppointmentService_CreateAppointment_HolidayUnverified(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	visitDate := apiModels.Date{Time: fixedNow().AddDate(0, 0, 7).UTC()}
+
+	mockRepo := new(MockAppointmentRepository)
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckRules", mock.Anything, visitDate, mock.Anything, mock.Anything).Return(calendarResult(services.ErrHolidayUnverified), nil)
+	mockRepo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	mockRepo.On("CreateWithinCapacity", mock.Anything, mock.MatchedBy(func(a *dbModels.Appointment) bool {
+		return a.HolidayUnverified
+	}), mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock)
+
+	result, err := service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+		FirstName: "John",
+		LastName:  "Doe",
+		VisitDate: visitDate,
+		StartTime: apiModels.NewTimeOfDay(9, 30),
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.HolidayUnverified)
+
+	mockRepo.AssertExpectations(t)
+	mockHoliday.AssertExpectations(t)
+}
+
+func TestParseHolidayFailPolicy(t *testing.T) {
+
+	policy, err := services.ParseHolidayFailPolicy(" Open ")
+	assert.NoError(t, err)
+	assert.Equal(t, services.HolidayFailOpen, policy)
+
+	for _, valid := range []string{"closed", "open", "stale"} {
+		_, err := services.ParseHolidayFailPolicy(valid)
+		assert.NoError(t, err, valid)
+	}
+
+	_, err = services.ParseHolidayFailPolicy("")
+	assert.Error(t, err)
+
+	_, err = services.ParseHolidayFailPolicy("ignore")
+	assert.Error(t, err)
+}
+
+func TestParseCapacityOverrides(t *testing.T) {
+
+	overrides, err := services.ParseCapacityOverrides(" 2025-03-14=2, 2025-04-01=0 ")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"2025-03-14": 2, "2025-04-01": 0}, overrides)
+
+	_, err = services.ParseCapacityOverrides("2025-03-14")
+	assert.Error(t, err)
+
+	_, err = services.ParseCapacityOverrides("2025-03-14=-1")
+	assert.Error(t, err)
+
+	_, err = services.ParseCapacityOverrides("14/03/2025=2")
+	assert.Error(t, err)
+}
+
+func TestDailyCapacity_Validate(t *testing.T) {
+
+	assert.NoError(t, services.DailyCapacity{Default: 1}.Validate())
+	assert.NoError(t, services.DefaultDailyCapacity().Validate())
+	assert.Error(t, services.DailyCapacity{Default: 0}.Validate(), "every date would be unbookable")
+	assert.Error(t, services.DailyCapacity{Default: -5}.Validate())
+}
+
+func TestAppointmentService_GetAvailability(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	from, to := mustDate(t, "2030-12-23"), mustDate(t, "2030-12-28")
+	christmas := &client.Holiday{Date: "2030-12-25", Name: "Christmas Day"}
+
+	mockRepo := new(MockAppointmentRepository)
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckDates", mock.Anything, from, to).Return([]services.DateCheck{
+		{Date: mustDate(t, "2030-12-23")},
+		{Date: mustDate(t, "2030-12-24")},
+		{Date: mustDate(t, "2030-12-25"), Err: services.ErrDateIsHoliday, Holiday: christmas},
+		{Date: mustDate(t, "2030-12-26"), Err: services.ErrDateInPast},
+		{Date: mustDate(t, "2030-12-27")},
+		// a copy pointing elsewhere is still the same error
+		{Date: mustDate(t, "2030-12-28"), Err: services.ErrClosedWeekday.At("query.from")},
+	}, nil)
+	mockHoliday.On("OpeningHours").Return(services.DefaultOpeningHours())
+	mockRepo.On("CountByDateRange", mock.Anything, from, to).Return(map[string]int{
+		"2030-12-23": 1,
+		"2030-12-24": 2,
+	}, nil)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock,
+		services.WithDailyCapacity(services.DailyCapacity{
+			Default:   2,
+			Overrides: map[string]int{"2030-12-27": 50}, // more than there are slots
+		}))
+
+	days, err := service.GetAvailability(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.Len(t, days, 6)
+
+	assert.True(t, days[0].Bookable)
+	assert.Equal(t, 1, days[0].Remaining)
+
+	assert.False(t, days[1].Bookable)
+	assert.Equal(t, services.ReasonFull, days[1].Reason)
+
+	assert.Equal(t, services.ReasonHoliday, days[2].Reason)
+	assert.Equal(t, "Christmas Day", days[2].HolidayName)
+
+	assert.Equal(t, services.ReasonPast, days[3].Reason)
+
+	assert.True(t, days[4].Bookable)
+	assert.Equal(t, 30, days[4].Remaining, "capped by the number of slots")
+
+	assert.Equal(t, services.ReasonWeekend, days[5].Reason)
+
+	mockRepo.AssertExpectations(t)
+	mockHoliday.AssertExpectations(t)
+
+	t.Run("Inverted Range", func(t *testing.T) {
+		_, err := service.GetAvailability(context.Background(), to, from)
+		assert.Equal(t, services.ErrInvalidDateRange, err)
+	})
+
+	t.Run("Range Too Long", func(t *testing.T) {
+		_, err := service.GetAvailability(context.Background(), from, apiModels.Date{Time: from.AddDate(1, 0, 0)})
+		assert.Equal(t, services.ErrDateRangeTooLong, err)
+	})
+}
+
+func TestAppointmentService_SuggestDates(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Saturday 2030-12-21 to Friday 2031-01-20
+	from := mustDate(t, "2030-12-21")
+	to := mustDate(t, "2031-01-20")
+
+	var checks []services.DateCheck
+	for d := from; !d.After(to.Time); d = (apiModels.Date{Time: d.AddDate(0, 0, 1)}) {
+		check := services.DateCheck{Date: d}
+		switch d.Weekday() {
+		case time.Saturday, time.Sunday:
+			check.Err = services.ErrClosedWeekday
+		}
+		if d.String() == "2030-12-25" {
+			check.Err = services.ErrDateIsHoliday
+			check.Holiday = &client.Holiday{Date: "2030-12-25", Name: "Christmas Day"}
+		}
+		checks = append(checks, check)
+	}
+
+	mockRepo := new(MockAppointmentRepository)
+	mockHoliday := newMockHolidayService()
+	mockHoliday.On("CheckDates", mock.Anything, from, to).Return(checks, nil)
+	mockHoliday.On("OpeningHours").Return(services.DefaultOpeningHours())
+	mockRepo.On("CountByDateRange", mock.Anything, from, to).Return(map[string]int{
+		"2030-12-24": 30, // fully booked
+	}, nil)
+
+	service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock)
+
+	suggestions, err := service.SuggestDates(context.Background(), from, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, suggestions, 3) {
+		assert.Equal(t, "2030-12-23", suggestions[0].String())
+		assert.Equal(t, "2030-12-26", suggestions[1].String())
+		assert.Equal(t, "2030-12-27", suggestions[2].String())
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockHoliday.AssertExpectations(t)
+}
+
+func TestAppointmentService_SelfService(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	visitDate := apiModels.Date{Time: fixedNow().AddDate(0, 0, 7).UTC()}
+
+	// book through the service to obtain a reference and its token
+	book := func(t *testing.T) (*services.AppointmentService, *MockAppointmentRepository, *dbModels.Appointment) {
+		mockRepo := new(MockAppointmentRepository)
+		mockHoliday := newMockHolidayService()
+		mockHoliday.On("CheckRules", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(calendarResult(nil), nil)
+		mockRepo.On("CountByDateRange", mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+		mockRepo.On("CreateWithinCapacity", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		service := services.NewAppointmentService(mockRepo, mockHoliday, logger, fixedOfficeClock)
+		booked, err := service.CreateAppointment(context.Background(), &services.CreateAppointmentRequest{
+			FirstName: "John",
+			LastName:  "Doe",
+			VisitDate: visitDate,
+			StartTime: apiModels.NewTimeOfDay(9, 30),
+		})
+		assert.NoError(t, err)
+		booked.ID = 7
+
+		mockRepo.On("GetByReference", mock.Anything, booked.Reference).Return(booked, nil)
+		mockRepo.On("GetByReference", mock.Anything, mock.Anything).Return(nil, database.ErrAppointmentNotFound)
+		return service, mockRepo, booked
+	}
+
+	t.Run("Get With Valid Token", func(t *testing.T) {
+		service, _, booked := book(t)
+
+		// references are matched case-insensitively
+		result, err := service.GetAppointmentByReference(context.Background(), " "+strings.ToLower(booked.Reference), booked.ManagementToken)
+		assert.NoError(t, err)
+		assert.Equal(t, booked.ID, result.ID)
+	})
+
+	t.Run("Wrong Token Looks Like Unknown Reference", func(t *testing.T) {
+		service, _, booked := book(t)
+
+		_, wrongTokenErr := service.GetAppointmentByReference(context.Background(), booked.Reference, "not-the-token")
+		_, unknownErr := service.GetAppointmentByReference(context.Background(), "CN-0000-0000", booked.ManagementToken)
+
+		assert.ErrorIs(t, wrongTokenErr, database.ErrAppointmentNotFound)
+		assert.Equal(t, unknownErr, wrongTokenErr)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		service, mockRepo, booked := book(t)
+		mockRepo.On("Cancel", mock.Anything, booked.ID, services.CancelledByCitizen, "Cannot make it", mock.Anything).Return(booked, nil)
+
+		_, err := service.CancelAppointmentByReference(context.Background(), booked.Reference, booked.ManagementToken, "Cannot make it")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cancel With Wrong Token", func(t *testing.T) {
+		service, mockRepo, booked := book(t)
+
+		_, err := service.CancelAppointmentByReference(context.Background(), booked.Reference, "not-the-token", "")
+		assert.ErrorIs(t, err, database.ErrAppointmentNotFound)
+		mockRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"citynext/pkg/businessday"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessCalendar(t *testing.T) {
+
+	// Christmas and Boxing Day 2030 fall on Wednesday and Thursday
+	holidays := map[string]bool{"2030-12-25": true, "2030-12-26": true}
+	calendar := businessday.New(businessday.Weekdays(), func(ctx context.Context, date time.Time) (bool, error) {
+		return holidays[date.Format("2006-01-02")], nil
+	})
+	ctx := context.Background()
+
+	t.Run("IsWorkingDay", func(t *testing.T) {
+		for day, expected := range map[string]bool{
+			"2030-12-24": true,
+			"2030-12-25": false, // holiday
+			"2030-12-28": false, // Saturday
+		} {
+			working, err := calendar.IsWorkingDay(ctx, mustDate(t, day).Time)
+			require.NoError(t, err)
+			assert.Equal(t, expected, working, day)
+		}
+
+		// only the calendar date counts, not the time of day or zone
+		working, err := calendar.IsWorkingDay(ctx, time.Date(2030, 12, 25, 23, 30, 0, 0, time.FixedZone("BST", 3600)))
+		require.NoError(t, err)
+		assert.False(t, working)
+	})
+
+	t.Run("NextWorkingDay", func(t *testing.T) {
+		next, err := calendar.NextWorkingDay(ctx, mustDate(t, "2030-12-24").Time)
+		require.NoError(t, err)
+		assert.Equal(t, mustDate(t, "2030-12-27").Time, next)
+
+		next, err = calendar.NextWorkingDay(ctx, mustDate(t, "2030-12-27").Time)
+		require.NoError(t, err)
+		assert.Equal(t, mustDate(t, "2030-12-30").Time, next, "the weekend is skipped")
+	})
+
+	t.Run("AddWorkingDays", func(t *testing.T) {
+		tests := []struct {
+			from     string
+			days     int
+			expected string
+		}{
+			{"2030-12-23", 0, "2030-12-23"},
+			{"2030-12-28", 0, "2030-12-28"}, // a non-working day is kept as it is
+			{"2030-12-23", 2, "2030-12-27"},
+			{"2030-12-23", 5, "2031-01-01"},
+			{"2030-12-27", -1, "2030-12-24"},
+			{"2030-12-30", -2, "2030-12-24"},
+		}
+		for _, tt := range tests {
+			got, err := calendar.AddWorkingDays(ctx, mustDate(t, tt.from).Time, tt.days)
+			require.NoError(t, err)
+			assert.Equal(t, mustDate(t, tt.expected).Time, got, "%s %+d", tt.from, tt.days)
+		}
+	})
+
+	t.Run("WorkingDaysBetween", func(t *testing.T) {
+		between, err := calendar.WorkingDaysBetween(ctx, mustDate(t, "2030-12-23").Time, mustDate(t, "2030-12-31").Time)
+		require.NoError(t, err)
+		assert.Equal(t, 4, between, "24th, 27th, 30th and 31st")
+
+		between, err = calendar.WorkingDaysBetween(ctx, mustDate(t, "2030-12-31").Time, mustDate(t, "2030-12-23").Time)
+		require.NoError(t, err)
+		assert.Equal(t, -4, between)
+
+		back, err := calendar.AddWorkingDays(ctx, mustDate(t, "2030-12-31").Time, between)
+		require.NoError(t, err)
+		assert.Equal(t, mustDate(t, "2030-12-23").Time, back, "adding the count lands back on a working day")
+
+		between, err = calendar.WorkingDaysBetween(ctx, mustDate(t, "2030-12-24").Time, mustDate(t, "2030-12-24").Time)
+		require.NoError(t, err)
+		assert.Zero(t, between)
+	})
+
+	t.Run("HolidayLookupFails", func(t *testing.T) {
+		outage := errors.New("unreachable")
+		failing := businessday.New(businessday.Weekdays(), func(ctx context.Context, date time.Time) (bool, error) {
+			return false, outage
+		})
+		_, err := failing.AddWorkingDays(ctx, mustDate(t, "2030-12-23").Time, 1)
+		assert.ErrorIs(t, err, outage)
+
+		// days of the week that are not worked need no lookup
+		working, err := failing.IsWorkingDay(ctx, mustDate(t, "2030-12-28").Time)
+		assert.NoError(t, err)
+		assert.False(t, working)
+	})
+
+	t.Run("NoWorkingDays", func(t *testing.T) {
+		_, err := businessday.New(nil, nil).NextWorkingDay(ctx, mustDate(t, "2030-12-23").Time)
+		assert.ErrorIs(t, err, businessday.ErrNoWorkingDay)
+	})
+}
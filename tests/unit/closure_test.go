@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayService_Closures(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	closures := database.NewMemoryClosureRepository(logger)
+	ctx := context.Background()
+	// 2030-11-04 is a Monday
+	require.NoError(t, closures.Create(ctx, &dbModels.Closure{StartDate: mustDate(t, "2030-11-04"), EndDate: mustDate(t, "2030-11-05"), Reason: "Refurbishment"}))
+	require.NoError(t, closures.Create(ctx, &dbModels.Closure{StartDate: mustDate(t, "2030-11-06"), EndDate: mustDate(t, "2030-11-06"), Reason: "Election count", HalfDay: true}))
+
+	var calls int32
+	service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithClosures(closures))
+
+	assert.ErrorIs(t, service.ValidateDate(ctx, mustDate(t, "2030-11-05")), services.ErrOfficeClosed)
+	assert.NoError(t, service.ValidateDate(ctx, mustDate(t, "2030-11-06")), "a half-day closure leaves the date open")
+	assert.NoError(t, service.ValidateSlot(ctx, mustDate(t, "2030-11-06"), apiModels.NewTimeOfDay(11, 45)))
+	assert.Equal(t, services.ErrOfficeClosedForSlot, service.ValidateSlot(ctx, mustDate(t, "2030-11-06"), apiModels.NewTimeOfDay(12, 0)))
+
+	checks, err := service.CheckDates(ctx, mustDate(t, "2030-11-04"), mustDate(t, "2030-11-07"))
+	require.NoError(t, err)
+	require.Len(t, checks, 4)
+	assert.Equal(t, services.ErrOfficeClosed, checks[0].Err)
+	assert.Equal(t, "Refurbishment", checks[1].Closure.Reason)
+	assert.NoError(t, checks[2].Err)
+	assert.True(t, checks[2].Closure.HalfDay)
+	assert.NoError(t, checks[3].Err)
+	assert.Nil(t, checks[3].Closure)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "the year is fetched once, closed dates need no holiday lookup")
+}
+
+func TestOpeningHours_SlotsUntil(t *testing.T) {
+	hours := services.DefaultOpeningHours()
+
+	morning := hours.SlotsUntil(services.DefaultHalfDayClosingTime())
+	assert.Len(t, morning, 12)
+	assert.Equal(t, "11:45", morning[len(morning)-1].String())
+	assert.Empty(t, hours.SlotsUntil(apiModels.NewTimeOfDay(9, 10)))
+	assert.Equal(t, hours.Slots(), hours.SlotsUntil(apiModels.NewTimeOfDay(18, 0)))
+}
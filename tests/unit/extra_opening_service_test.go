@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	"citynext/internal/errcode"
+	"citynext/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraOpeningService(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newService := func() *services.ExtraOpeningService {
+		return services.NewExtraOpeningService(database.NewMemoryExtraOpeningRepository(logger), services.DefaultOpeningHours(), logger)
+	}
+	// a Saturday morning surgery
+	request := func(start, end string) *services.ExtraOpeningRequest {
+		req := &services.ExtraOpeningRequest{
+			StartDate: mustDate(t, start),
+			Reason:    "Saturday surgery",
+			Opens:     apiModels.NewTimeOfDay(9, 0),
+			Closes:    apiModels.NewTimeOfDay(12, 0),
+			Capacity:  5,
+		}
+		if end != "" {
+			req.EndDate = mustDate(t, end)
+		}
+		return req
+	}
+
+	t.Run("CreateFillsEndDate", func(t *testing.T) {
+		service := newService()
+
+		req := request("2030-03-09", "")
+		req.Reason = "  Saturday surgery  "
+		opening, err := service.CreateExtraOpening(ctx, req)
+		require.NoError(t, err)
+		assert.NotZero(t, opening.ID)
+		assert.Equal(t, "2030-03-09", opening.EndDate.String(), "a single day ends where it starts")
+		assert.Equal(t, "Saturday surgery", opening.Reason)
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		service := newService()
+
+		missingReason := request("2030-03-09", "")
+		missingReason.Reason = "   "
+		reversedHours := request("2030-03-09", "")
+		reversedHours.Opens, reversedHours.Closes = reversedHours.Closes, reversedHours.Opens
+		noCapacity := request("2030-03-09", "")
+		noCapacity.Capacity = 0
+
+		for name, tc := range map[string]struct {
+			req      *services.ExtraOpeningRequest
+			err      error
+			location string
+		}{
+			"MissingReason":   {missingReason, services.ErrInvalidInput, "body.reason"},
+			"ReversedHours":   {reversedHours, services.ErrInvalidInput, "body.closes"},
+			"NoCapacity":      {noCapacity, services.ErrInvalidInput, "body.capacity"},
+			"EndBeforeStart":  {request("2030-03-09", "2030-03-08"), services.ErrInvalidDateRange, ""},
+			"LongerThanAYear": {request("2030-03-09", "2031-03-10"), services.ErrDateRangeTooLong, ""},
+		} {
+			_, err := service.CreateExtraOpening(ctx, tc.req)
+			require.ErrorIs(t, err, tc.err, name)
+			if tc.location != "" {
+				assert.Equal(t, tc.location, errcode.From(err).Location, name)
+			}
+		}
+
+		openings, err := service.ListExtraOpenings(ctx, apiModels.Date{}, apiModels.Date{})
+		require.NoError(t, err)
+		assert.Empty(t, openings, "nothing invalid is stored")
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		service := newService()
+
+		weekend, err := service.CreateExtraOpening(ctx, request("2030-03-09", "2030-03-10"))
+		require.NoError(t, err)
+		next, err := service.CreateExtraOpening(ctx, request("2030-03-16", ""))
+		require.NoError(t, err)
+
+		_, err = service.CreateExtraOpening(ctx, request("2030-03-10", "2030-03-11"))
+		assert.ErrorIs(t, err, database.ErrExtraOpeningOverlap)
+
+		// moving an opening onto its own dates is fine, onto another one's is not
+		updated, err := service.UpdateExtraOpening(ctx, weekend.ID, request("2030-03-09", ""))
+		require.NoError(t, err)
+		assert.Equal(t, "2030-03-09", updated.EndDate.String())
+		_, err = service.UpdateExtraOpening(ctx, next.ID, request("2030-03-09", ""))
+		assert.ErrorIs(t, err, database.ErrExtraOpeningOverlap)
+
+		// the day freed by the update can be taken now
+		_, err = service.CreateExtraOpening(ctx, request("2030-03-10", ""))
+		assert.NoError(t, err)
+	})
+
+	t.Run("ListDeleteAndNotFound", func(t *testing.T) {
+		service := newService()
+
+		first, err := service.CreateExtraOpening(ctx, request("2030-03-16", ""))
+		require.NoError(t, err)
+		_, err = service.CreateExtraOpening(ctx, request("2030-03-09", ""))
+		require.NoError(t, err)
+
+		openings, err := service.ListExtraOpenings(ctx, mustDate(t, "2030-03-01"), mustDate(t, "2030-03-31"))
+		require.NoError(t, err)
+		require.Len(t, openings, 2)
+		assert.Equal(t, "2030-03-09", openings[0].StartDate.String(), "ordered by start date")
+
+		_, err = service.ListExtraOpenings(ctx, mustDate(t, "2030-03-31"), mustDate(t, "2030-03-01"))
+		assert.ErrorIs(t, err, services.ErrInvalidDateRange)
+
+		require.NoError(t, service.DeleteExtraOpening(ctx, first.ID))
+		assert.ErrorIs(t, service.DeleteExtraOpening(ctx, first.ID), database.ErrExtraOpeningNotFound)
+		_, err = service.GetExtraOpening(ctx, first.ID)
+		assert.ErrorIs(t, err, database.ErrExtraOpeningNotFound)
+		_, err = service.UpdateExtraOpening(ctx, first.ID, request("2030-03-23", ""))
+		assert.ErrorIs(t, err, database.ErrExtraOpeningNotFound)
+	})
+}
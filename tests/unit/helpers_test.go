@@ -0,0 +1,18 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+)
+
+// parses a YYYY-MM-DD date, failing the test when it is not one
+func mustDate(t testing.TB, s string) apiModels.Date {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("invalid test date %q: %v", s, err)
+	}
+	return apiModels.Date{Time: d}
+}
@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stand-in for the Nager.Date API that answers the first len(statuses) calls with the
+// given statuses and every later call with one holiday
+func newFlakyNagerStub(t *testing.T, calls *int32, statuses ...int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(calls, 1))
+		if call <= len(statuses) {
+			if statuses[call-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"date":"2030-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}]`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHolidayClient_Retries(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fastRetries := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second})
+
+	t.Run("ServerErrorsAreRetried", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 500, 502).URL, logger, fastRetries)
+
+		holidays, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+		require.NoError(t, err)
+		assert.Len(t, holidays, 1)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 503, 503, 503, 503).URL, logger, fastRetries)
+
+		_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+		var statusErr *client.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("ClientErrorsAreNotRetried", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 404).URL, logger, fastRetries)
+
+		_, err := c.GetPublicHolidays(context.Background(), 2030, "XX")
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, client.CircuitClosed, c.CircuitState(), "a rejected request does not count against the API")
+	})
+
+	t.Run("RetryAfterIsHonoured", func(t *testing.T) {
+		var calls int32
+		var waits []time.Duration
+		recordWaits := client.WithSleeper(func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		})
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 429).URL, logger, fastRetries, recordWaits)
+
+		_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{time.Second}, waits, "the server's Retry-After replaces the backoff")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("LongRetryAfterIsNotAwaited", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 429).URL, logger,
+			client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 100 * time.Millisecond}))
+
+		_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CancelledContextStopsRetries", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 500, 500, 500).URL, logger,
+			client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.GetPublicHolidays(ctx, 2030, "GB")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+
+	assert.NoError(t, client.DefaultRetryPolicy().Validate())
+	assert.NoError(t, client.RetryPolicy{MaxAttempts: 1}.Validate())
+
+	for name, policy := range map[string]client.RetryPolicy{
+		"NoAttempts":        {MaxAttempts: 0, BaseDelay: time.Second, MaxDelay: time.Second},
+		"NegativeBaseDelay": {MaxAttempts: 3, BaseDelay: -time.Second, MaxDelay: time.Second},
+		"NegativeMaxDelay":  {MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: -time.Second},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, policy.Validate())
+		})
+	}
+
+	t.Run("NegativeMaxDelayDoesNotPanic", func(t *testing.T) {
+		var calls int32
+		c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 500).URL, slog.New(slog.NewTextHandler(io.Discard, nil)),
+			client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: -time.Second}))
+
+		assert.NotPanics(t, func() {
+			_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+			assert.NoError(t, err)
+		})
+	})
+}
+
+func TestBreakerConfig_Validate(t *testing.T) {
+
+	assert.NoError(t, client.DefaultBreakerConfig().Validate())
+	assert.Error(t, client.BreakerConfig{FailureThreshold: 0, Cooldown: time.Second}.Validate())
+	assert.Error(t, client.BreakerConfig{FailureThreshold: 5, Cooldown: 0}.Validate())
+	assert.Error(t, client.BreakerConfig{FailureThreshold: 5, Cooldown: -time.Second}.Validate())
+}
+
+func TestHolidayClient_CircuitBreaker(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var calls int32
+	c := client.NewHolidayClient(newFlakyNagerStub(t, &calls, 500, 500, 500).URL, logger,
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}),
+		client.WithBreakerConfig(client.BreakerConfig{FailureThreshold: 2, Cooldown: 50 * time.Millisecond}))
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, client.CircuitOpen, c.CircuitState())
+
+	// fails fast without calling the API
+	_, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+	assert.ErrorIs(t, err, client.ErrCircuitOpen)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// a failed probe opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, client.CircuitHalfOpen, c.CircuitState())
+	_, err = c.GetPublicHolidays(context.Background(), 2030, "GB")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, client.ErrCircuitOpen)
+	assert.Equal(t, client.CircuitOpen, c.CircuitState())
+
+	// a successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	holidays, err := c.GetPublicHolidays(context.Background(), 2030, "GB")
+	require.NoError(t, err)
+	assert.Len(t, holidays, 1)
+	assert.Equal(t, client.CircuitClosed, c.CircuitState())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,322 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const govUKBankHolidays = `{
+	"england-and-wales": {"division": "england-and-wales", "events": [
+		{"title": "Easter Monday", "date": "2030-04-22", "notes": "", "bunting": true},
+		{"title": "Christmas Day", "date": "2030-12-25", "notes": "", "bunting": true},
+		{"title": "Christmas Day", "date": "2031-12-25", "notes": "", "bunting": true}
+	]},
+	"scotland": {"division": "scotland", "events": [
+		{"title": "St Andrew’s Day", "date": "2030-12-02", "notes": "", "bunting": true},
+		{"title": "Christmas Day", "date": "2030-12-25", "notes": "", "bunting": true}
+	]},
+	"northern-ireland": {"division": "northern-ireland", "events": [
+		{"title": "Easter Monday", "date": "2030-04-22", "notes": "", "bunting": true},
+		{"title": "Christmas Day", "date": "2030-12-25", "notes": "", "bunting": true}
+	]}
+}`
+
+// writes content to a file in a fresh temporary directory and returns its path
+func writeTempFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// indexes holidays by date
+func holidaysByDate(holidays []client.Holiday) map[string]client.Holiday {
+	byDate := make(map[string]client.Holiday, len(holidays))
+	for _, holiday := range holidays {
+		byDate[holiday.Date] = holiday
+	}
+	return byDate
+}
+
+func TestGovUKProvider(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, govUKBankHolidays)
+	}))
+	t.Cleanup(server.Close)
+
+	for name, location := range map[string]string{
+		"URL":  server.URL + "/bank-holidays.json",
+		"File": writeTempFile(t, "bank-holidays.json", govUKBankHolidays),
+	} {
+		t.Run(name, func(t *testing.T) {
+			provider := client.NewGovUKProvider(location, logger)
+			assert.Equal(t, "govuk:"+location, provider.Name())
+
+			holidays, err := provider.GetPublicHolidays(context.Background(), 2030, "GB")
+			require.NoError(t, err)
+			require.Len(t, holidays, 3, "one entry per date, only for the requested year")
+
+			byDate := holidaysByDate(holidays)
+			assert.True(t, byDate["2030-12-25"].Global)
+			assert.Empty(t, byDate["2030-12-25"].Counties)
+			assert.False(t, byDate["2030-04-22"].Global)
+			assert.ElementsMatch(t, []string{"GB-ENG", "GB-WLS", "GB-NIR"}, byDate["2030-04-22"].Counties)
+			assert.Equal(t, []string{"GB-SCT"}, byDate["2030-12-02"].Counties)
+			assert.Equal(t, "St Andrew’s Day", byDate["2030-12-02"].Name)
+		})
+	}
+
+	t.Run("OtherCountry", func(t *testing.T) {
+		holidays, err := client.NewGovUKProvider(server.URL, logger).GetPublicHolidays(context.Background(), 2030, "IE")
+		assert.NoError(t, err)
+		assert.Empty(t, holidays)
+	})
+}
+
+func TestICalProvider(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	path := writeTempFile(t, "closures.ics", "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"DTSTART;VALUE=DATE:20301225\r\n"+
+		"DTEND;VALUE=DATE:20301227\r\n"+
+		"SUMMARY:Christmas\\, closed\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"DTSTART:20300506T000000Z\r\n"+
+		"SUMMARY:Early May\r\n"+
+		"  bank holiday\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"DTSTART;VALUE=DATE:20311231\r\n"+
+		"DTEND;VALUE=DATE:20320102\r\n"+
+		"SUMMARY:New Year\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	provider := client.NewICalProvider(path, logger)
+
+	holidays, err := provider.GetPublicHolidays(context.Background(), 2030, "GB")
+	require.NoError(t, err)
+	require.Len(t, holidays, 3)
+	assert.Equal(t, "2030-05-06", holidays[0].Date)
+	assert.Equal(t, "Early May bank holiday", holidays[0].Name)
+	assert.Equal(t, "2030-12-25", holidays[1].Date)
+	assert.Equal(t, "2030-12-26", holidays[2].Date, "DTEND is exclusive")
+	assert.Equal(t, "Christmas, closed", holidays[2].Name)
+	for _, holiday := range holidays {
+		assert.True(t, holiday.Global)
+		assert.Equal(t, "GB", holiday.CountryCode)
+	}
+
+	// an event crossing the new year counts towards both years
+	holidays, err = provider.GetPublicHolidays(context.Background(), 2032, "GB")
+	require.NoError(t, err)
+	require.Len(t, holidays, 1)
+	assert.Equal(t, "2032-01-01", holidays[0].Date)
+
+	// overlapping events share a day
+	overlapping := client.NewICalProvider(writeTempFile(t, "overlapping.ics", "BEGIN:VCALENDAR\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"DTSTART;VALUE=DATE:20301224\r\n"+
+		"DTEND;VALUE=DATE:20301227\r\n"+
+		"SUMMARY:Christmas closure\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"DTSTART;VALUE=DATE:20301226\r\n"+
+		"SUMMARY:Boxing Day\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n"), logger)
+	holidays, err = overlapping.GetPublicHolidays(context.Background(), 2030, "GB")
+	require.NoError(t, err)
+	require.Len(t, holidays, 3)
+	assert.Equal(t, "2030-12-26", holidays[2].Date)
+	assert.Equal(t, "Christmas closure / Boxing Day", holidays[2].Name)
+
+	_, err = client.NewICalProvider(writeTempFile(t, "broken.ics", "BEGIN:VEVENT\nSUMMARY:No date\nEND:VEVENT\n"), logger).
+		GetPublicHolidays(context.Background(), 2030, "GB")
+	assert.Error(t, err)
+}
+
+func TestStaticFileProvider(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	files := map[string]string{
+		"holidays.yaml": `
+- date: "2030-12-25"
+  name: Christmas Day
+- date: "2030-11-30"
+  name: St Andrew's Day
+  countryCode: GB
+  counties: [GB-SCT]
+- date: "2030-03-17"
+  name: St Patrick's Day
+  countryCode: IE
+`,
+		"holidays.json": `[
+			{"date": "2030-12-25", "name": "Christmas Day"},
+			{"date": "2030-11-30", "name": "St Andrew's Day", "countryCode": "GB", "counties": ["GB-SCT"]},
+			{"date": "2030-03-17", "name": "St Patrick's Day", "countryCode": "IE"}
+		]`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			holidays, err := client.NewStaticFileProvider(writeTempFile(t, name, content), logger).
+				GetPublicHolidays(context.Background(), 2030, "GB")
+			require.NoError(t, err)
+			require.Len(t, holidays, 2, "holidays of other countries are skipped")
+
+			byDate := holidaysByDate(holidays)
+			assert.True(t, byDate["2030-12-25"].Global)
+			assert.Equal(t, "Christmas Day", byDate["2030-12-25"].LocalName)
+			assert.Equal(t, "GB", byDate["2030-12-25"].CountryCode)
+			assert.False(t, byDate["2030-11-30"].Global)
+			assert.Equal(t, []string{"GB-SCT"}, byDate["2030-11-30"].Counties)
+		})
+	}
+
+	t.Run("SharedDate", func(t *testing.T) {
+		holidays, err := client.NewStaticFileProvider(writeTempFile(t, "holidays.yaml", `
+- date: "2030-11-30"
+  name: St Andrew's Day
+  counties: [GB-SCT]
+- date: "2030-11-30"
+  name: Staff Day
+`), logger).GetPublicHolidays(context.Background(), 2030, "GB")
+		require.NoError(t, err)
+		require.Len(t, holidays, 1, "one entry per date")
+		assert.Equal(t, "St Andrew's Day / Staff Day", holidays[0].Name)
+		assert.True(t, holidays[0].Global)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		_, err := client.NewStaticFileProvider(writeTempFile(t, "holidays.json", `[{"date": "25/12/2030", "name": "Christmas Day"}]`), logger).
+			GetPublicHolidays(context.Background(), 2030, "GB")
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := client.NewStaticFileProvider(filepath.Join(t.TempDir(), "missing.yaml"), logger).
+			GetPublicHolidays(context.Background(), 2030, "GB")
+		assert.Error(t, err)
+	})
+}
+
+// provider returning fixed holidays or a fixed error
+type fixedProvider struct {
+	name     string
+	holidays []client.Holiday
+	err      error
+}
+
+func (p fixedProvider) Name() string { return p.name }
+
+func (p fixedProvider) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]client.Holiday, error) {
+	return p.holidays, p.err
+}
+
+func TestCompositeProvider(t *testing.T) {
+
+	national := fixedProvider{name: "national", holidays: []client.Holiday{
+		{Date: "2030-04-22", Name: "Easter Monday", Counties: []string{"GB-ENG", "GB-WLS"}, Types: []string{"Public"}},
+		{Date: "2030-12-25", Name: "Christmas Day", Global: true, Types: []string{"Public"}},
+	}}
+	local := fixedProvider{name: "local", holidays: []client.Holiday{
+		{Date: "2030-04-22", Name: "Easter Monday (NI)", Counties: []string{"GB-NIR"}, Types: []string{"Bank"}},
+		{Date: "2030-07-01", Name: "Staff training", Global: true},
+	}}
+
+	composite := client.NewCompositeProvider(national, local)
+	assert.Equal(t, "national,local", composite.Name())
+
+	holidays, err := composite.GetPublicHolidays(context.Background(), 2030, "GB")
+	require.NoError(t, err)
+	require.Len(t, holidays, 3)
+
+	assert.Equal(t, []string{"2030-04-22", "2030-07-01", "2030-12-25"}, []string{holidays[0].Date, holidays[1].Date, holidays[2].Date})
+	assert.Equal(t, "Easter Monday", holidays[0].Name, "the first provider names a shared date")
+	assert.Equal(t, []string{"GB-ENG", "GB-WLS", "GB-NIR"}, holidays[0].Counties)
+	assert.Equal(t, []string{"Public", "Bank"}, holidays[0].Types)
+
+	t.Run("FailsWhenAnyProviderFails", func(t *testing.T) {
+		outage := errors.New("unreachable")
+		_, err := client.NewCompositeProvider(national, fixedProvider{name: "down", err: outage}).
+			GetPublicHolidays(context.Background(), 2030, "GB")
+		assert.ErrorIs(t, err, outage)
+	})
+
+	t.Run("ReportsLeastHealthyCircuit", func(t *testing.T) {
+		closed := breakerProvider{fixedProvider: national, state: client.CircuitClosed}
+		open := breakerProvider{fixedProvider: national, state: client.CircuitOpen}
+		halfOpen := breakerProvider{fixedProvider: national, state: client.CircuitHalfOpen}
+
+		assert.Equal(t, client.CircuitClosed, client.NewCompositeProvider(closed, local).CircuitState())
+		assert.Equal(t, client.CircuitHalfOpen, client.NewCompositeProvider(closed, halfOpen).CircuitState())
+		assert.Equal(t, client.CircuitOpen, client.NewCompositeProvider(open, halfOpen).CircuitState(), "an open breaker outranks a half-open one")
+		assert.Equal(t, client.CircuitOpen, client.NewCompositeProvider(halfOpen, open, closed).CircuitState())
+	})
+}
+
+// fixed provider behind a circuit breaker stuck in one state
+type breakerProvider struct {
+	fixedProvider
+	state client.CircuitState
+}
+
+func (p breakerProvider) CircuitState() client.CircuitState { return p.state }
+
+func TestNewProviderFromSpec(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	provider, err := client.NewProviderFromSpec("nager=https://date.nager.at/api/v3", logger)
+	require.NoError(t, err)
+	assert.IsType(t, &client.HolidayClient{}, provider)
+	assert.Equal(t, "nager:https://date.nager.at/api/v3", provider.Name())
+
+	provider, err = client.NewProviderFromSpec(" govuk=https://www.gov.uk/bank-holidays.json, ical=/etc/citynext/closures.ics ,file=/etc/citynext/holidays.yaml", logger)
+	require.NoError(t, err)
+	assert.IsType(t, &client.CompositeProvider{}, provider)
+	assert.Equal(t, "govuk:https://www.gov.uk/bank-holidays.json,ical:/etc/citynext/closures.ics,file:/etc/citynext/holidays.yaml", provider.Name())
+
+	for _, invalid := range []string{"", "nager", "nager=", "outlook=https://example.com"} {
+		_, err := client.NewProviderFromSpec(invalid, logger)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMergeByDate(t *testing.T) {
+
+	merged := client.MergeByDate([]client.Holiday{
+		{Date: "2030-12-25", Name: "Christmas Day", Global: true, Types: []string{"Public"}},
+		{Date: "2030-03-17", Name: "St Patrick's Day", Counties: []string{"GB-NIR"}, Types: []string{"Public"}},
+		{Date: "2030-03-17", Name: "Staff Day", Counties: []string{"GB-SCT"}, Types: []string{"Bank"}},
+		{Date: "2030-03-17", Name: "St Patrick's Day", Counties: []string{"GB-NIR"}},
+	})
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, "2030-03-17", merged[0].Date, "entries come back in date order")
+	assert.Equal(t, "St Patrick's Day / Staff Day", merged[0].Name, "a name listed twice is kept once")
+	assert.Equal(t, []string{"GB-NIR", "GB-SCT"}, merged[0].Counties)
+	assert.Equal(t, []string{"Public", "Bank"}, merged[0].Types)
+	assert.Equal(t, "Christmas Day", merged[1].Name)
+}
@@ -0,0 +1,221 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayRefresher_RefreshOnce(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// serves Christmas Day for every year, plus a one-off holiday in 2030 once it is published
+	var published, failing atomic.Bool
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		parts := strings.Split(r.URL.Path, "/")
+		year := parts[len(parts)-2]
+
+		w.Header().Set("Content-Type", "application/json")
+		holidays := fmt.Sprintf(`{"date":"%s-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}`, year)
+		if year == "2030" && published.Load() {
+			holidays += `,{"date":"2030-05-08","localName":"Coronation","name":"Coronation","countryCode":"GB","global":true}`
+		}
+		fmt.Fprintf(w, "[%s]", holidays)
+	}))
+	t.Cleanup(server.Close)
+
+	noRetries := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1})
+	now := func() time.Time { return time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC) }
+	holidayService := services.NewHolidayService(client.NewHolidayClient(server.URL, logger, noRetries), logger,
+		services.WithClock(now))
+	yearRefresher, ok := holidayService.(services.YearRefresher)
+	require.True(t, ok)
+
+	repo := database.NewMemoryAppointmentRepository(logger)
+	book := func(day int, start apiModels.TimeOfDay) *dbModels.Appointment {
+		appointment := &dbModels.Appointment{
+			FirstName: "Jane",
+			LastName:  "Doe",
+			VisitDate: apiModels.Date{Time: time.Date(2030, 5, day, 0, 0, 0, 0, time.UTC)},
+			StartTime: start,
+		}
+		require.NoError(t, repo.Create(context.Background(), appointment))
+		return appointment
+	}
+	onCoronation := book(8, apiModels.NewTimeOfDay(9, 0))
+	cancelled := book(8, apiModels.NewTimeOfDay(9, 15))
+	_, err := repo.Cancel(context.Background(), cancelled.ID, "citizen", "", time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	book(9, apiModels.NewTimeOfDay(9, 0))
+
+	refresher := services.NewHolidayRefresher(repo, yearRefresher, time.Hour, logger)
+
+	// the first pass prefetches this year and next, so nothing is new yet
+	report, err := refresher.RefreshOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{2030, 2031}, report.Years)
+	assert.Empty(t, report.Added)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	isHoliday, err := holidayService.IsPublicHoliday(context.Background(), apiModels.Date{Time: time.Date(2031, 12, 25, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	assert.True(t, isHoliday)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "next year was prefetched")
+
+	t.Run("NewHolidayConflicts", func(t *testing.T) {
+		published.Store(true)
+
+		report, err := refresher.RefreshOnce(context.Background())
+		require.NoError(t, err)
+		if assert.Len(t, report.Added, 1) {
+			assert.Equal(t, "Coronation", report.Added[0].Name)
+		}
+		if assert.Len(t, report.Conflicts, 1, "cancelled appointments and other dates do not conflict") {
+			assert.Equal(t, onCoronation.ID, report.Conflicts[0].ID)
+		}
+
+		isHoliday, err := holidayService.IsPublicHoliday(context.Background(), onCoronation.VisitDate)
+		require.NoError(t, err)
+		assert.True(t, isHoliday, "the refreshed year replaces the cached one")
+
+		marked, err := repo.GetByID(context.Background(), onCoronation.ID)
+		require.NoError(t, err)
+		assert.True(t, marked.HolidayConflict)
+
+		report, err = refresher.RefreshOnce(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, report.Added)
+		assert.Empty(t, report.Conflicts, "a marked conflict is not reported again")
+	})
+
+	t.Run("FailedFetchKeepsCache", func(t *testing.T) {
+		failing.Store(true)
+
+		report, err := refresher.RefreshOnce(context.Background())
+		assert.Error(t, err)
+		assert.Empty(t, report.Years)
+
+		isHoliday, err := holidayService.IsPublicHoliday(context.Background(), onCoronation.VisitDate)
+		require.NoError(t, err)
+		assert.True(t, isHoliday)
+	})
+}
+
+func TestHolidayRefresher_ConflictsWithoutEarlierCopy(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Christmas Day and the summer bank holiday, plus a one-off holiday in 2030 once it is published
+	var published atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		year := parts[len(parts)-2]
+
+		w.Header().Set("Content-Type", "application/json")
+		holidays := fmt.Sprintf(`{"date":"%[1]s-08-26","localName":"Summer Bank Holiday","name":"Summer Bank Holiday","countryCode":"GB","global":true},`+
+			`{"date":"%[1]s-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}`, year)
+		if year == "2030" && published.Load() {
+			holidays += `,{"date":"2030-05-08","localName":"Coronation","name":"Coronation","countryCode":"GB","global":true}`
+		}
+		fmt.Fprintf(w, "[%s]", holidays)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	now := func() time.Time { return time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	book := func(repo database.AppointmentRepository, date string) *dbModels.Appointment {
+		appointment := &dbModels.Appointment{
+			FirstName: "Jane",
+			LastName:  "Doe",
+			VisitDate: mustDate(t, date),
+			StartTime: apiModels.NewTimeOfDay(9, 0),
+		}
+		require.NoError(t, repo.Create(ctx, appointment))
+		return appointment
+	}
+
+	t.Run("FirstRefresh", func(t *testing.T) {
+		repo := database.NewMemoryAppointmentRepository(logger)
+		onChristmas := book(repo, "2030-12-25")
+		book(repo, "2030-08-26")
+
+		// a Saturday surgery style extra opening lifts the bank holiday
+		openings := database.NewMemoryExtraOpeningRepository(logger)
+		require.NoError(t, openings.Create(ctx, &dbModels.ExtraOpening{
+			StartDate: mustDate(t, "2030-08-26"),
+			EndDate:   mustDate(t, "2030-08-26"),
+			Reason:    "Backlog",
+			Opens:     apiModels.NewTimeOfDay(9, 0),
+			Closes:    apiModels.NewTimeOfDay(12, 0),
+			Capacity:  5,
+		}))
+
+		// nothing cached and no store: the year has never been seen
+		holidayService := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger,
+			services.WithClock(now),
+			services.WithExtraOpenings(openings))
+		refresher := services.NewHolidayRefresher(repo, holidayService.(services.YearRefresher), time.Hour, logger)
+
+		report, err := refresher.RefreshOnce(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, report.Added, "nothing is newly published without an earlier copy")
+		if assert.Len(t, report.Conflicts, 1, "the bank holiday is lifted by the extra opening") {
+			assert.Equal(t, onChristmas.ID, report.Conflicts[0].ID)
+			assert.True(t, report.Conflicts[0].HolidayConflict)
+		}
+	})
+
+	t.Run("ComparedWithStoredCopy", func(t *testing.T) {
+		published.Store(false)
+		store := database.NewMemoryHolidayRepository(logger)
+		holidayService := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger,
+			services.WithClock(now),
+			services.WithHolidayStore(store))
+
+		// another instance sharing the store fetched the year after this one started
+		other := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger,
+			services.WithClock(now),
+			services.WithHolidayStore(store))
+		_, err := other.IsPublicHoliday(ctx, mustDate(t, "2030-12-25"))
+		require.NoError(t, err)
+
+		published.Store(true)
+		repo := database.NewMemoryAppointmentRepository(logger)
+		onCoronation := book(repo, "2030-05-08")
+		refresher := services.NewHolidayRefresher(repo, holidayService.(services.YearRefresher), time.Hour, logger)
+
+		report, err := refresher.RefreshOnce(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, report.Added, 1) {
+			assert.Equal(t, "Coronation", report.Added[0].Name)
+		}
+		if assert.Len(t, report.Conflicts, 1) {
+			assert.Equal(t, onCoronation.ID, report.Conflicts[0].ID)
+		}
+	})
+}
@@ -0,0 +1,391 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stand-in for the Nager.Date API that serves Christmas Day for every requested year
+func newCountingNagerStub(t *testing.T, calls *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		// path is /PublicHolidays/{year}/{countryCode}
+		parts := strings.Split(r.URL.Path, "/")
+		year := parts[len(parts)-2]
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"date":"%s-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}]`, year)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHolidayService_CheckDates(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var calls int32
+	server := newCountingNagerStub(t, &calls)
+	service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger)
+
+	// 2030-12-23 is a Monday; the range spans a year boundary
+	checks, err := service.CheckDates(context.Background(), mustDate(t, "2030-12-23"), mustDate(t, "2031-01-06"))
+	assert.NoError(t, err)
+	assert.Len(t, checks, 15)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "one upstream call per year in the range")
+
+	byDate := make(map[string]services.DateCheck)
+	for _, check := range checks {
+		byDate[check.Date.Format("2006-01-02")] = check
+	}
+
+	assert.NoError(t, byDate["2030-12-23"].Err)
+	assert.Equal(t, services.ErrDateIsHoliday, byDate["2030-12-25"].Err)
+	if assert.NotNil(t, byDate["2030-12-25"].Holiday) {
+		assert.Equal(t, "Christmas Day", byDate["2030-12-25"].Holiday.Name)
+	}
+	assert.Equal(t, services.ErrClosedWeekday, byDate["2030-12-28"].Err)
+	assert.Equal(t, services.ErrClosedWeekday, byDate["2031-01-05"].Err)
+	assert.NoError(t, byDate["2031-01-06"].Err)
+
+	t.Run("Cached Years Are Not Refetched", func(t *testing.T) {
+		_, err := service.CheckDates(context.Background(), mustDate(t, "2030-12-01"), mustDate(t, "2030-12-31"))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Past Dates", func(t *testing.T) {
+		now := func() time.Time { return time.Date(2030, 3, 5, 12, 0, 0, 0, time.UTC) }
+		service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger, services.WithClock(now))
+		checks, err := service.CheckDates(context.Background(), mustDate(t, "2030-03-04"), mustDate(t, "2030-03-05"))
+		assert.NoError(t, err)
+		if assert.Len(t, checks, 2) {
+			assert.Equal(t, services.ErrDateInPast, checks[0].Err)
+			assert.NoError(t, checks[1].Err, "today is not in the past")
+		}
+	})
+}
+
+func TestHolidayService_IsPublicHoliday_CachesWholeYear(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var calls int32
+	server := newCountingNagerStub(t, &calls)
+	service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger)
+
+	for _, day := range []string{"2030-03-04", "2030-03-05", "2030-12-25", "2030-07-01"} {
+		isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, day))
+		assert.NoError(t, err)
+		assert.Equal(t, day == "2030-12-25", isHoliday, day)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "ordinary working days are answered from the cache")
+
+	_, err := service.IsPublicHoliday(context.Background(), mustDate(t, "2031-03-04"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "a new year is fetched once")
+}
+
+func TestHolidayService_ConcurrentMissesShareOneFetch(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// holds the first upstream request until every caller has missed the cache
+	var calls int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(server.Close)
+
+	service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger)
+	visitDate := apiModels.Date{Time: time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			isHoliday, err := service.IsPublicHoliday(context.Background(), visitDate)
+			assert.NoError(t, err)
+			assert.False(t, isHoliday)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func BenchmarkHolidayService_IsPublicHoliday(b *testing.B) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"date":"2030-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}]`))
+	}))
+	b.Cleanup(server.Close)
+
+	service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			// every date of 2030, so almost all lookups are for ordinary working days
+			visitDate := apiModels.Date{Time: start.AddDate(0, 0, i%365)}
+			if _, err := service.IsPublicHoliday(context.Background(), visitDate); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+	b.StopTimer()
+
+	upstream := atomic.LoadInt32(&calls)
+	b.ReportMetric(float64(upstream), "upstream-calls/year")
+	if upstream != 1 {
+		b.Fatalf("expected 1 upstream call for the year, got %d", upstream)
+	}
+}
+
+func TestHolidayService_IsPublicHoliday_RefetchesExpiredYear(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	var calls int32
+	service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithHolidayCacheTTL(time.Hour),
+		services.WithClock(func() time.Time { return now }))
+
+	christmas := apiModels.Date{Time: time.Date(2031, 12, 25, 0, 0, 0, 0, time.UTC)}
+	for _, elapsed := range []time.Duration{0, 59 * time.Minute, time.Hour} {
+		now = now.Add(elapsed)
+		isHoliday, err := service.IsPublicHoliday(context.Background(), christmas)
+		assert.NoError(t, err)
+		assert.True(t, isHoliday)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "a year is fetched again only once it has expired")
+}
+
+func TestHolidayService_RegionalHolidays(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// a nationwide holiday, one for Scotland only and one for everywhere but Scotland, as Nager reports them
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[
+			{"date":"2030-04-22","localName":"Easter Monday","name":"Easter Monday","countryCode":"GB","global":false,"counties":["GB-ENG","GB-WLS","GB-NIR"]},
+			{"date":"2030-12-02","localName":"Saint Andrew's Day","name":"Saint Andrew's Day","countryCode":"GB","global":false,"counties":["GB-SCT"]},
+			{"date":"2030-12-25","localName":"Christmas Day","name":"Christmas Day","countryCode":"GB","global":true}
+		]`)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		region   string
+		holidays map[string]bool
+	}{
+		{"GB-ENG", map[string]bool{"2030-04-22": true, "2030-12-02": false, "2030-12-25": true}},
+		{"GB-SCT", map[string]bool{"2030-04-22": false, "2030-12-02": true, "2030-12-25": true}},
+		{"GB", map[string]bool{"2030-04-22": false, "2030-12-02": false, "2030-12-25": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			region, err := services.ParseOfficeRegion(tt.region)
+			assert.NoError(t, err)
+			service := services.NewHolidayService(client.NewHolidayClient(server.URL, logger), logger, services.WithOfficeRegion(region))
+
+			for day, expected := range tt.holidays {
+				isHoliday, err := service.IsPublicHoliday(context.Background(), mustDate(t, day))
+				assert.NoError(t, err)
+				assert.Equal(t, expected, isHoliday, day)
+			}
+		})
+	}
+}
+
+func TestHolidayService_OfficeTimeZone(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	london, err := services.ParseOfficeTimeZone("Europe/London")
+	assert.NoError(t, err)
+
+	// 23:30 UTC on Monday 2030-07-01 is already 00:30 on Tuesday during British Summer Time
+	now := time.Date(2030, 7, 1, 23, 30, 0, 0, time.UTC)
+	var calls int32
+	service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithOfficeLocation(london),
+		services.WithClock(func() time.Time { return now }))
+
+	assert.Equal(t, services.ErrDateInPast, service.ValidateDate(context.Background(), mustDate(t, "2030-07-01")),
+		"yesterday at the office, even though it is still that date in UTC")
+	assert.NoError(t, service.ValidateDate(context.Background(), mustDate(t, "2030-07-02")))
+
+	// 08:30 UTC is 09:30 at the office
+	now = time.Date(2030, 7, 2, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, services.ErrDateInPast, service.ValidateSlot(context.Background(), mustDate(t, "2030-07-02"), apiModels.NewTimeOfDay(9, 15)))
+	assert.NoError(t, service.ValidateSlot(context.Background(), mustDate(t, "2030-07-02"), apiModels.NewTimeOfDay(9, 45)))
+
+	// the same instant in UTC leaves the 09:15 slot open
+	utc := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithOfficeLocation(time.UTC),
+		services.WithClock(func() time.Time { return now }))
+	assert.NoError(t, utc.ValidateSlot(context.Background(), mustDate(t, "2030-07-02"), apiModels.NewTimeOfDay(9, 15)))
+}
+
+func TestParseOfficeTimeZone(t *testing.T) {
+
+	location, err := services.ParseOfficeTimeZone(" Europe/London ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/London", location.String())
+
+	for _, invalid := range []string{"", "Europe/Londinium", "BST+1"} {
+		_, err := services.ParseOfficeTimeZone(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseOfficeRegion(t *testing.T) {
+
+	region, err := services.ParseOfficeRegion(" gb-sct ")
+	assert.NoError(t, err)
+	assert.Equal(t, services.OfficeRegion{CountryCode: "GB", Subdivision: "GB-SCT"}, region)
+
+	region, err = services.ParseOfficeRegion("GB")
+	assert.NoError(t, err)
+	assert.Equal(t, services.OfficeRegion{CountryCode: "GB"}, region)
+
+	for _, invalid := range []string{"", "GBR", "GB-", "GB-SCOT", "GB_SCT"} {
+		_, err := services.ParseOfficeRegion(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestHolidayService_MinimumLeadTime(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Friday 2030-12-20; three working days ahead skips the weekend and Christmas Day
+	now := func() time.Time { return time.Date(2030, 12, 20, 10, 0, 0, 0, time.UTC) }
+	var calls int32
+	service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithClock(now),
+		services.WithBookingWindow(services.BookingWindow{MinWorkingDays: 3}))
+	ctx := context.Background()
+
+	earliest, err := service.(*services.HolidayService).EarliestBookableDate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "2030-12-26", earliest.String())
+
+	assert.Equal(t, services.ErrDateTooSoon, service.ValidateDate(ctx, mustDate(t, "2030-12-24")))
+	assert.NoError(t, service.ValidateDate(ctx, mustDate(t, "2030-12-26")))
+
+	checks, err := service.CheckDates(ctx, mustDate(t, "2030-12-19"), mustDate(t, "2030-12-26"))
+	assert.NoError(t, err)
+	if assert.Len(t, checks, 8) {
+		assert.Equal(t, services.ErrDateInPast, checks[0].Err)
+		assert.Equal(t, services.ErrDateTooSoon, checks[1].Err, "today is within the lead time")
+		assert.Equal(t, services.ErrClosedWeekday, checks[2].Err, "closed weekdays keep their own reason")
+		assert.Equal(t, services.ErrDateTooSoon, checks[4].Err)
+		assert.Equal(t, services.ErrDateTooSoon, checks[5].Err)
+		assert.Equal(t, services.ErrDateTooSoon, checks[6].Err, "the lead time is checked before holidays")
+		assert.NoError(t, checks[7].Err)
+	}
+}
+
+func TestHolidayService_BookingWindow(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ctx := context.Background()
+
+	t.Run("MaxDaysAhead", func(t *testing.T) {
+		// Friday 2030-03-01; 90 days ahead is Thursday 2030-05-30
+		now := func() time.Time { return time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC) }
+		var calls int32
+		service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+			services.WithClock(now),
+			services.WithBookingWindow(services.BookingWindow{MaxDaysAhead: 90}))
+
+		assert.NoError(t, service.ValidateDate(ctx, mustDate(t, "2030-05-30")))
+		assert.Equal(t, services.ErrDateTooFar, service.ValidateDate(ctx, mustDate(t, "2030-05-31")))
+		assert.Equal(t, services.ErrDateTooFar, service.ValidateDate(ctx, mustDate(t, "2099-01-05")))
+
+		checks, err := service.CheckDates(ctx, mustDate(t, "2030-05-29"), mustDate(t, "2031-01-03"))
+		assert.NoError(t, err)
+		assert.NoError(t, checks[0].Err)
+		assert.Equal(t, services.ErrDateTooFar, checks[len(checks)-1].Err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "holidays are never fetched beyond the horizon")
+	})
+
+	t.Run("SameDayCutoff", func(t *testing.T) {
+		// 11:30 UTC is 12:30 at the office on Tuesday 2030-07-02
+		now := func() time.Time { return time.Date(2030, 7, 2, 11, 30, 0, 0, time.UTC) }
+		newService := func(cutoff apiModels.TimeOfDay) services.HolidayServiceInterface {
+			var calls int32
+			return services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+				services.WithClock(now),
+				services.WithBookingWindow(services.BookingWindow{SameDayCutoff: &cutoff}))
+		}
+
+		service := newService(apiModels.NewTimeOfDay(12, 30))
+		assert.Equal(t, services.ErrSameDayCutoff, service.ValidateDate(ctx, mustDate(t, "2030-07-02")), "the cut-off is inclusive")
+		assert.NoError(t, service.ValidateDate(ctx, mustDate(t, "2030-07-03")))
+
+		service = newService(apiModels.NewTimeOfDay(13, 0))
+		assert.NoError(t, service.ValidateSlot(ctx, mustDate(t, "2030-07-02"), apiModels.NewTimeOfDay(14, 0)))
+	})
+
+	t.Run("MinNotice", func(t *testing.T) {
+		now := func() time.Time { return time.Date(2030, 7, 2, 11, 30, 0, 0, time.UTC) }
+		var calls int32
+		service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+			services.WithClock(now),
+			services.WithBookingWindow(services.BookingWindow{MinNotice: 24 * time.Hour}))
+
+		assert.Equal(t, services.ErrNoticeTooShort, service.ValidateSlot(ctx, mustDate(t, "2030-07-03"), apiModels.NewTimeOfDay(12, 15)))
+		assert.NoError(t, service.ValidateSlot(ctx, mustDate(t, "2030-07-03"), apiModels.NewTimeOfDay(12, 30)))
+		assert.NoError(t, service.ValidateDate(ctx, mustDate(t, "2030-07-02")), "the notice applies to slots, not dates")
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.NoError(t, services.BookingWindow{}.Validate())
+		assert.Error(t, services.BookingWindow{MinNotice: -time.Hour}.Validate())
+		assert.Error(t, services.BookingWindow{MinWorkingDays: -1}.Validate())
+		assert.Error(t, services.BookingWindow{MaxDaysAhead: -1}.Validate())
+	})
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"citynext/internal/api/handlers"
+	"citynext/internal/database"
+	"citynext/internal/services"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProblem(t *testing.T) {
+
+	t.Run("Domain Error", func(t *testing.T) {
+		problem := handlers.NewProblem(http.StatusConflict, "An appointment already exists for this slot", database.ErrDuplicateAppointment).(*handlers.Problem)
+
+		assert.Equal(t, http.StatusConflict, problem.GetStatus())
+		assert.Equal(t, "Conflict", problem.Title)
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, "DUPLICATE_BOOKING", problem.Errors[0].Code)
+			assert.Equal(t, "body.startTime", problem.Errors[0].Location)
+		}
+	})
+
+	t.Run("Field Specific Domain Error", func(t *testing.T) {
+		err := services.ErrInvalidInput.At("body.firstName")
+		assert.ErrorIs(t, err, services.ErrInvalidInput)
+
+		problem := handlers.NewProblem(http.StatusUnprocessableEntity, "Invalid input data", err).(*handlers.Problem)
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, "INVALID_INPUT", problem.Errors[0].Code)
+			assert.Equal(t, "body.firstName", problem.Errors[0].Location)
+		}
+	})
+
+	t.Run("Request Validation Error", func(t *testing.T) {
+		detail := &huma.ErrorDetail{Message: "expected required property firstName to be present", Location: "body"}
+		problem := handlers.NewProblem(http.StatusUnprocessableEntity, "validation failed", detail).(*handlers.Problem)
+
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, handlers.CodeInvalidRequest, problem.Errors[0].Code)
+			assert.Equal(t, detail.Message, problem.Errors[0].Message)
+			assert.Equal(t, "body", problem.Errors[0].Location)
+		}
+	})
+
+	t.Run("Internal Error Is Not Leaked", func(t *testing.T) {
+		problem := handlers.NewProblem(http.StatusInternalServerError, "Internal server error", errors.New("database is locked")).(*handlers.Problem)
+
+		if assert.Len(t, problem.Errors, 1) {
+			assert.Equal(t, handlers.CodeInternalError, problem.Errors[0].Code)
+			assert.Empty(t, problem.Errors[0].Message)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/database"
+	dbModels "citynext/internal/database/models"
+	"citynext/internal/errcode"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+
+	slot := func(hour, minute int) *apiModels.TimeOfDay {
+		start := apiModels.NewTimeOfDay(hour, minute)
+		return &start
+	}
+	ctx := context.Background()
+
+	// Tuesday 2030-07-02, 10:00 at the office
+	now := time.Date(2030, 7, 2, 10, 0, 0, 0, services.DefaultOfficeLocation())
+	input := func(day string, start *apiModels.TimeOfDay) *services.RuleInput {
+		return &services.RuleInput{Date: mustDate(t, day), Start: start, Now: now, Hours: services.DefaultOpeningHours()}
+	}
+
+	// runs a single rule and returns the error of its violation, or nil when it passes
+	check := func(t *testing.T, rule services.Rule, in *services.RuleInput) error {
+		found, err := rule.Check(ctx, in)
+		require.NoError(t, err)
+		if found == nil {
+			return nil
+		}
+		assert.Equal(t, rule.Name(), found.Rule)
+		return found.Err
+	}
+
+	t.Run("OpeningHours", func(t *testing.T) {
+		rule := services.OpeningHoursRule{}
+		assert.NoError(t, check(t, rule, input("2030-07-03", slot(9, 30))))
+		assert.Equal(t, services.ErrOutsideOpeningHours, check(t, rule, input("2030-07-03", slot(9, 40))))
+		assert.Equal(t, services.ErrOutsideOpeningHours, check(t, rule, input("2030-07-03", slot(16, 30))))
+		assert.NoError(t, check(t, rule, input("2030-07-03", nil)), "dates on their own have no slot to check")
+	})
+
+	t.Run("PastDate", func(t *testing.T) {
+		rule := services.PastDateRule{}
+		assert.Equal(t, services.ErrDateInPast, check(t, rule, input("2030-07-01", nil)))
+		assert.NoError(t, check(t, rule, input("2030-07-02", nil)))
+		assert.Equal(t, services.ErrDateInPast, check(t, rule, input("2030-07-02", slot(10, 0))), "the slot has started")
+		assert.NoError(t, check(t, rule, input("2030-07-02", slot(10, 15))))
+	})
+
+	t.Run("Weekday", func(t *testing.T) {
+		rule := services.WeekdayRule{Schedule: services.WeekdaySchedule(services.DefaultOpeningHours())}
+		assert.NoError(t, check(t, rule, input("2030-07-05", nil)))
+		assert.Equal(t, services.ErrClosedWeekday, check(t, rule, input("2030-07-06", nil)))
+
+		saturday := input("2030-07-06", nil)
+		saturday.Opening = &dbModels.ExtraOpening{Reason: "Saturday surgery"}
+		assert.NoError(t, check(t, rule, saturday), "an extra opening lifts the weekday rule")
+	})
+
+	t.Run("BookingWindow", func(t *testing.T) {
+		cutoff := apiModels.NewTimeOfDay(10, 0)
+		rule := services.BookingWindowRule{Window: services.BookingWindow{
+			MinNotice:      2 * time.Hour,
+			MinWorkingDays: 2,
+			MaxDaysAhead:   30,
+			SameDayCutoff:  &cutoff,
+		}}
+		withEarliest := func(in *services.RuleInput) *services.RuleInput {
+			in.Earliest = func(context.Context) (apiModels.Date, error) { return mustDate(t, "2030-07-04"), nil }
+			return in
+		}
+
+		assert.Equal(t, services.ErrDateTooFar, check(t, rule, withEarliest(input("2030-08-02", nil))))
+		assert.NoError(t, check(t, rule, withEarliest(input("2030-08-01", nil))))
+		assert.Equal(t, services.ErrSameDayCutoff, check(t, rule, withEarliest(input("2030-07-02", nil))))
+		assert.Equal(t, services.ErrDateTooSoon, check(t, rule, withEarliest(input("2030-07-03", nil))))
+		assert.NoError(t, check(t, rule, withEarliest(input("2030-07-04", slot(9, 0)))))
+
+		rule.Window = services.BookingWindow{MinNotice: 2 * time.Hour}
+		assert.Equal(t, services.ErrNoticeTooShort, check(t, rule, input("2030-07-02", slot(11, 45))))
+		assert.NoError(t, check(t, rule, input("2030-07-02", slot(12, 0))))
+	})
+
+	t.Run("BookingWindowWithoutHolidays", func(t *testing.T) {
+		in := input("2030-07-03", nil)
+		in.Earliest = func(context.Context) (apiModels.Date, error) { return apiModels.Date{}, errors.New("upstream down") }
+
+		rule := services.BookingWindowRule{Window: services.BookingWindow{MinWorkingDays: 2}, FailPolicy: services.HolidayFailClosed}
+		_, err := rule.Check(ctx, in)
+		assert.Equal(t, services.ErrHolidayDataUnavailable, err)
+
+		rule.FailPolicy = services.HolidayFailOpen
+		assert.NoError(t, check(t, rule, in), "the lead time is not enforced while holidays are unknown")
+	})
+
+	t.Run("Closure", func(t *testing.T) {
+		rule := services.ClosureRule{}
+		assert.NoError(t, check(t, rule, input("2030-07-03", slot(14, 0))))
+
+		closed := input("2030-07-03", nil)
+		closed.Closure = &dbModels.Closure{Reason: "Refurbishment"}
+		assert.Equal(t, services.ErrOfficeClosed, check(t, rule, closed))
+
+		halfDay := input("2030-07-03", slot(11, 45))
+		halfDay.Closure = &dbModels.Closure{Reason: "Election count", HalfDay: true}
+		assert.NoError(t, check(t, rule, halfDay))
+		halfDay.Start = slot(12, 0)
+		assert.Equal(t, services.ErrOfficeClosedForSlot, check(t, rule, halfDay))
+
+		halfDay.HalfDayCloses = apiModels.NewTimeOfDay(13, 0)
+		assert.NoError(t, check(t, rule, halfDay), "a later configured closing time keeps 12:00 open")
+		halfDay.Start = slot(13, 0)
+		assert.Equal(t, services.ErrOfficeClosedForSlot, check(t, rule, halfDay))
+	})
+
+	t.Run("Holiday", func(t *testing.T) {
+		christmas := &client.Holiday{Date: "2030-12-25", Name: "Christmas Day"}
+		lookup := func(holiday *client.Holiday, err error) func(context.Context) (*client.Holiday, error) {
+			return func(context.Context) (*client.Holiday, error) { return holiday, err }
+		}
+
+		rule := services.HolidayRule{FailPolicy: services.HolidayFailClosed}
+		in := input("2030-12-25", nil)
+		in.Holiday = lookup(christmas, nil)
+		assert.Equal(t, services.ErrDateIsHoliday, check(t, rule, in))
+
+		in.Opening = &dbModels.ExtraOpening{Reason: "Christmas surgery"}
+		assert.NoError(t, check(t, rule, in), "an extra opening lifts the holiday rule")
+
+		in = input("2030-12-24", nil)
+		in.Holiday = lookup(nil, nil)
+		assert.NoError(t, check(t, rule, in))
+
+		in.Holiday = lookup(nil, errors.New("upstream down"))
+		_, err := rule.Check(ctx, in)
+		assert.Equal(t, services.ErrHolidayDataUnavailable, err)
+
+		rule.FailPolicy = services.HolidayFailOpen
+		assert.NoError(t, check(t, rule, in))
+		assert.True(t, in.HolidayUnverified)
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		rule := services.NameRule{}
+		in := input("2030-07-03", slot(9, 30))
+		assert.NoError(t, check(t, rule, in), "nothing to check without a person")
+
+		in.Person = &services.Person{LastName: "Doe"}
+		err := errcode.From(check(t, rule, in))
+		require.NotNil(t, err)
+		assert.ErrorIs(t, err, services.ErrInvalidInput)
+		assert.Equal(t, "body.firstName", err.Location)
+
+		in.Person = &services.Person{FirstName: "John", LastName: "Doe"}
+		assert.NoError(t, check(t, rule, in))
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		rule := services.CapacityRule{}
+		in := input("2030-07-03", nil)
+		in.Capacity = 2
+		booked := 1
+		in.Booked = func(context.Context) (int, error) { return booked, nil }
+		assert.NoError(t, check(t, rule, in))
+
+		booked = 2
+		assert.Equal(t, database.ErrDateFullyBooked, check(t, rule, in))
+
+		// a half-day closure leaves only the morning slots
+		in.Capacity, booked = 50, 12
+		in.Closure = &dbModels.Closure{Reason: "Election count", HalfDay: true}
+		assert.Equal(t, database.ErrDateFullyBooked, check(t, rule, in))
+	})
+
+	t.Run("PersonLimit", func(t *testing.T) {
+		in := input("2030-07-03", slot(9, 30))
+		in.Person = &services.Person{FirstName: "John", LastName: "Doe"}
+		in.PersonBookings = func(context.Context) (int, error) { return 2, nil }
+
+		assert.NoError(t, check(t, services.PersonLimitRule{}, in), "zero means no limit")
+		assert.NoError(t, check(t, services.PersonLimitRule{Max: 3}, in))
+		assert.Equal(t, database.ErrPersonLimitReached, check(t, services.PersonLimitRule{Max: 2}, in))
+	})
+}
+
+func TestPipeline(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Saturday 2030-07-06, which is also in the past and closed for refurbishment
+	in := func() *services.RuleInput {
+		return &services.RuleInput{
+			Date:    mustDate(t, "2030-07-06"),
+			Now:     time.Date(2030, 7, 8, 10, 0, 0, 0, services.DefaultOfficeLocation()),
+			Closure: &dbModels.Closure{Reason: "Refurbishment"},
+			Hours:   services.DefaultOpeningHours(),
+		}
+	}
+	pipeline := services.Pipeline{
+		services.PastDateRule{},
+		services.WeekdayRule{Schedule: services.WeekdaySchedule(services.DefaultOpeningHours())},
+		services.ClosureRule{},
+	}
+
+	t.Run("FailFast", func(t *testing.T) {
+		result, err := pipeline.Run(ctx, in(), services.FailFast)
+		require.NoError(t, err)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, "past_date", result.Violations[0].Rule)
+		assert.Equal(t, services.ErrDateInPast, result.Err())
+	})
+
+	t.Run("CollectAll", func(t *testing.T) {
+		result, err := pipeline.Run(ctx, in(), services.CollectAll)
+		require.NoError(t, err)
+
+		var rules []string
+		for _, violation := range result.Violations {
+			rules = append(rules, violation.Rule)
+		}
+		assert.Equal(t, []string{"past_date", "weekday", "closure"}, rules)
+		assert.Equal(t, services.ErrDateInPast, result.Err(), "the first rule decides the error")
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		reordered := services.Pipeline{pipeline[2], pipeline[0], pipeline[1]}
+		result, err := reordered.Run(ctx, in(), services.FailFast)
+		require.NoError(t, err)
+		assert.Equal(t, services.ErrOfficeClosed, result.Err())
+	})
+
+	t.Run("Passes", func(t *testing.T) {
+		open := in()
+		open.Date, open.Closure = mustDate(t, "2030-07-09"), nil
+		result, err := pipeline.Run(ctx, open, services.CollectAll)
+		require.NoError(t, err)
+		assert.Empty(t, result.Violations)
+		assert.NoError(t, result.Err())
+	})
+}
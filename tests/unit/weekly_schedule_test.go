@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	apiModels "citynext/internal/api/models"
+	"citynext/internal/services"
+	"citynext/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWeeklySchedule(t *testing.T) {
+
+	schedule, err := services.ParseWeeklySchedule("Tue-Fri=09:00-16:30, sat=09:00-12:00", 15*time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, schedule, 5)
+	assert.Equal(t, "Tue=09:00-16:30,Wed=09:00-16:30,Thu=09:00-16:30,Fri=09:00-16:30,Sat=09:00-12:00", schedule.String())
+
+	_, open := schedule.HoursOn(time.Monday)
+	assert.False(t, open)
+	saturday, open := schedule.HoursOn(time.Saturday)
+	assert.True(t, open)
+	assert.Len(t, saturday.Slots(), 12)
+
+	// ranges may wrap around the end of the week
+	schedule, err = services.ParseWeeklySchedule("Fri-Mon=10:00-14:00", 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "Mon=10:00-14:00,Fri=10:00-14:00,Sat=10:00-14:00,Sun=10:00-14:00", schedule.String())
+
+	assert.Equal(t, "Mon=09:00-16:30,Tue=09:00-16:30,Wed=09:00-16:30,Thu=09:00-16:30,Fri=09:00-16:30",
+		services.WeekdaySchedule(services.DefaultOpeningHours()).String())
+
+	for _, invalid := range []string{
+		"",                                    // no open days
+		"Mon",                                 // no hours
+		"Funday=09:00-16:30",                  // unknown day
+		"Mon-Fri=09:00",                       // no closing time
+		"Mon-Fri=16:30-09:00",                 // closes before it opens
+		"Mon-Fri=09:00-16:30,Fri=09:00-12:00", // Friday twice
+	} {
+		_, err := services.ParseWeeklySchedule(invalid, 15*time.Minute)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestHolidayService_WeeklySchedule(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	schedule, err := services.ParseWeeklySchedule("Tue-Fri=09:00-16:30,Sat=09:00-12:00", 15*time.Minute)
+	require.NoError(t, err)
+
+	var calls int32
+	service := services.NewHolidayService(client.NewHolidayClient(newCountingNagerStub(t, &calls).URL, logger), logger,
+		services.WithWeeklySchedule(schedule))
+	ctx := context.Background()
+
+	// 2030-11-04 is a Monday
+	err = service.ValidateDate(ctx, mustDate(t, "2030-11-04"))
+	assert.Equal(t, services.ErrClosedWeekday, err)
+	assert.ErrorIs(t, err, services.ErrOfficeClosed, "a closed weekday is a case of the office being closed")
+
+	assert.NoError(t, service.ValidateSlot(ctx, mustDate(t, "2030-11-09"), apiModels.NewTimeOfDay(11, 45)))
+	assert.Equal(t, services.ErrOutsideOpeningHours, service.ValidateSlot(ctx, mustDate(t, "2030-11-09"), apiModels.NewTimeOfDay(12, 0)),
+		"Saturday closes at noon")
+	assert.NoError(t, service.ValidateSlot(ctx, mustDate(t, "2030-11-08"), apiModels.NewTimeOfDay(16, 15)))
+
+	checks, err := service.CheckDates(ctx, mustDate(t, "2030-11-04"), mustDate(t, "2030-11-10"))
+	require.NoError(t, err)
+	require.Len(t, checks, 7)
+	assert.Equal(t, services.ErrClosedWeekday, checks[0].Err)
+	assert.Len(t, checks[1].Hours.Slots(), 30)
+	assert.Len(t, checks[5].Hours.Slots(), 12)
+	assert.Equal(t, services.ErrClosedWeekday, checks[6].Err)
+}